@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// listRemoteBranches returns the remote-tracking branches, e.g. "origin/ds-fix-x/CPRE-1".
+func listRemoteBranches() ([]string, error) {
+	branches, err := listRefs("refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	return branches, nil
+}
+
+// adoptCmd represents the command to continue work on a teammate's branch.
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [remote-branch]",
+	Short: "Continue a teammate's branch on your own convention-named branch",
+	Long: `Fetch a teammate's remote branch and create your own branch based on it,
+keeping the JIRA ticket from the original branch name. The new branch records
+which branch it was adopted from.
+
+For example, adopting origin/ds-fix-user-details/CPRE-11347 might create
+lv-fix-user-details-window-width/CPRE-11347.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if cfg.Abbreviation == "" {
+			return fmt.Errorf("no configuration found. Please run 'git-helper-cli config' to set your two-letter abbreviation")
+		}
+
+		// 1. Fetch so the teammate's latest work is available.
+		if err := runGit("fetch", "--prune"); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+
+		// 2. Pick the branch to adopt.
+		var source string
+		if len(args) == 1 {
+			source = args[0]
+		} else {
+			branches, err := listRemoteBranches()
+			if err != nil {
+				return err
+			}
+			if len(branches) == 0 {
+				return fmt.Errorf("no remote branches found")
+			}
+			if err := askOne(&survey.Select{
+				Message: "Choose the branch to adopt:",
+				Options: branches,
+			}, &source); err != nil {
+				return err
+			}
+		}
+		if _, err := gitOutput("rev-parse", "--verify", "--quiet", source); err != nil {
+			return fmt.Errorf("branch '%s' not found; use the remote-tracking name, e.g. origin/<branch>", source)
+		}
+
+		// 3. Keep the ticket from the original branch, asking only if it has none.
+		ticketID, err := extractTicketFromBranch(source)
+		if err != nil {
+			fmt.Printf("Could not find a JIRA ticket in '%s'.\n", source)
+			if err := askTicketID(cfg, &ticketID, false); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("Using JIRA ticket %s from '%s'.\n", linkTicket(ticketID), source)
+		}
+
+		// 4. Name the continuation branch.
+		var branchType, description string
+		if err := runSteps(
+			func(back bool) error { return askBranchType(cfg, &branchType, back) },
+			func(back bool) error { return askBranchDescription(cfg, &description, back) },
+		); err != nil {
+			return err
+		}
+		branchName, err := assembleBranchName(cfg, branchType, description, ticketID, "", nil)
+		if err != nil {
+			return err
+		}
+		if err := checkReservedBranch(cfg, branchName); err != nil {
+			return err
+		}
+		if branchName, err = resolveCaseCollision(branchName, true); err != nil {
+			return err
+		}
+
+		confirm, err := confirmAction(cfg, false, fmt.Sprintf("Create branch '%s' from '%s'?", branchName, source))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Aborting adoption.")
+			return nil
+		}
+
+		// 5. Create the branch without tracking the teammate's branch.
+		if err := runGit("checkout", "--no-track", "-b", branchName, source); err != nil {
+			return fmt.Errorf("failed to create branch: %w", err)
+		}
+
+		if err := recordBranch(branchName, BranchMetadata{Ticket: ticketID, AdoptedFrom: source}); err != nil {
+			fmt.Printf("Warning: failed to record branch metadata: %v\n", err)
+		}
+
+		enteredBranch(cfg, branchName)
+		fmt.Printf("Adopted '%s' as '%s'.\n", source, branchName)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// Defaults of when create-commit offers to amend the previous commit.
+const (
+	defaultAmendMinutes  = 15
+	defaultAmendMaxLines = 5
+)
+
+// AmendConfig controls when create-commit offers to amend the previous commit
+// instead of adding a small follow-up such as "fix typo".
+type AmendConfig struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Minutes is how recent the previous commit must be.
+	Minutes int `json:"minutes,omitempty"`
+	// MaxLines is the most lines the staged changes may add and remove.
+	MaxLines int `json:"max_lines,omitempty"`
+}
+
+// window returns how recent the previous commit must be.
+func (a AmendConfig) window() time.Duration {
+	if a.Minutes > 0 {
+		return time.Duration(a.Minutes) * time.Minute
+	}
+	return defaultAmendMinutes * time.Minute
+}
+
+// maxLines returns the most changed lines counted as a small follow-up.
+func (a AmendConfig) maxLines() int {
+	if a.MaxLines > 0 {
+		return a.MaxLines
+	}
+	return defaultAmendMaxLines
+}
+
+// amendCandidate describes a previous commit the staged changes could be folded into.
+type amendCandidate struct {
+	subject string
+	age     time.Duration
+	lines   int
+}
+
+// stagedLineCount returns how many lines the staged changes add and remove.
+// Binary files count as one line each.
+func stagedLineCount() (int, error) {
+	out, err := gitOutput("diff", "--cached", "--numstat")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count staged changes: %w", err)
+	}
+	total := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		added, errAdded := strconv.Atoi(fields[0])
+		removed, errRemoved := strconv.Atoi(fields[1])
+		if errAdded != nil || errRemoved != nil {
+			total++
+			continue
+		}
+		total += added + removed
+	}
+	return total, nil
+}
+
+// findAmendCandidate reports the previous commit if the staged changes are
+// small and it is the user's own unpushed commit on the same ticket, made
+// within the configured window.
+func findAmendCandidate(cfg Config, ticketID string) (amendCandidate, bool) {
+	if cfg.Amend.Disabled {
+		return amendCandidate{}, false
+	}
+	lines, err := stagedLineCount()
+	if err != nil || lines > cfg.Amend.maxLines() {
+		return amendCandidate{}, false
+	}
+	out, err := gitOutput("log", "-1", "--format=%ae%x00%ct%x00%s%x00%B")
+	if err != nil {
+		return amendCandidate{}, false
+	}
+	parts := strings.SplitN(out, "\x00", 4)
+	if len(parts) != 4 {
+		return amendCandidate{}, false
+	}
+	email, _ := gitOutput("config", "user.email")
+	if email == "" || !strings.EqualFold(parts[0], email) {
+		return amendCandidate{}, false
+	}
+	unix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return amendCandidate{}, false
+	}
+	age := time.Since(time.Unix(unix, 0))
+	if age > cfg.Amend.window() {
+		return amendCandidate{}, false
+	}
+	if convention.ParseCommitMetadata(parts[3]).Ticket != ticketID {
+		return amendCandidate{}, false
+	}
+	// Rewriting a commit others may have fetched is never suggested.
+	if pushed, err := gitOutput("branch", "-r", "--contains", "HEAD"); err != nil || pushed != "" {
+		return amendCandidate{}, false
+	}
+	return amendCandidate{subject: parts[2], age: age, lines: lines}, true
+}
+
+// offerAmend asks whether to fold the staged changes into the previous
+// commit, signing it again when sign is set. It reports true once the commit
+// has been amended.
+func offerAmend(cfg Config, c amendCandidate, sign bool) (bool, error) {
+	fmt.Printf("\nThe staged changes are small (%d lines) and your last commit on this ticket was %s ago:\n  %s\n",
+		c.lines, c.age.Round(time.Second), c.subject)
+	choice := "Amend the previous commit"
+	if err := askSelect("How do you want to commit these changes?", []string{choice, "Create a new commit"}, &choice, false); err != nil {
+		return false, err
+	}
+	if choice != "Amend the previous commit" {
+		return false, nil
+	}
+	if err := runGit(cfg.Signing.commitArgs(sign, "--amend", "--no-edit")...); err != nil {
+		return false, fmt.Errorf("failed to amend commit: %w", err)
+	}
+	reportDone("Previous commit amended successfully!", "Previous commit would be amended.")
+	return true, nil
+}
+
+// amendedCommit holds the details of the previous commit that create-commit
+// --amend starts from.
+type amendedCommit struct {
+	// header is the parsed subject; parsed is false when the subject does not
+	// follow the convention, e.g. with a custom commit template.
+	header   convention.Header
+	parsed   bool
+	meta     convention.CommitMetadata
+	trailers []convention.Trailer
+	// pushed reports whether the commit is already on a remote branch.
+	pushed bool
+}
+
+// loadAmendedCommit reads the previous commit for --amend.
+func loadAmendedCommit() (amendedCommit, error) {
+	var c amendedCommit
+	message, err := gitOutput("log", "-1", "--format=%B")
+	if err != nil {
+		return c, fmt.Errorf("no commit to amend: %w", err)
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	c.header, c.parsed = convention.ParseHeader(subject)
+	c.meta = convention.ParseCommitMetadata(message)
+	c.trailers = convention.ParseTrailers(message)
+	if pushed, err := gitOutput("branch", "-r", "--contains", "HEAD"); err == nil && pushed != "" {
+		c.pushed = true
+	}
+	return c, nil
+}
+
+// keepTrailers adds the trailers of the amended commit that the new messages
+// do not set again, such as Signed-off-by, to the trailer block at their end.
+// Trailers with the replaced keys were asked for again, so they are dropped.
+func (c amendedCommit) keepTrailers(messages []string, replaced ...string) []string {
+	last := len(messages) - 1
+	set := map[string]bool{}
+	for _, key := range replaced {
+		set[strings.ToLower(key)] = true
+	}
+	for _, t := range convention.ParseTrailers("subject\n\n" + messages[last]) {
+		set[strings.ToLower(t.Key)] = true
+	}
+	for _, t := range c.trailers {
+		if !set[strings.ToLower(t.Key)] {
+			messages[last] += "\n" + t.String()
+		}
+	}
+	return messages
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// historyAnalysis summarises how an existing history uses the convention.
+type historyAnalysis struct {
+	Commits           int
+	ConventionHeaders int // headers shaped like the commit subject template
+	CleanHeaders      int // headers that also pass the style rules
+	WithTicket        int
+	Branches          int
+	ConventionNames   int
+	Types             map[string]int
+	Products          map[string]int
+	TicketProjects    map[string]int
+	DescLengths       []int
+	HeaderLengths     []int
+	// SubjectTemplate and BranchTemplate are the layouts most of the history
+	// follows, when that is not the configured one, or "" otherwise.
+	SubjectTemplate string
+	SubjectMatches  int
+	BranchTemplate  string
+	BranchMatches   int
+}
+
+// subjectTemplateCandidates are common commit subject layouts, tried when
+// the history does not follow the configured one.
+var subjectTemplateCandidates = []string{
+	convention.DefaultCommitSubjectTemplate,
+	"{{.Type}}: {{.Desc}}",
+	"[{{.Ticket}}] {{.Type}}: {{.Desc}}",
+	"[{{.Ticket}}] {{.Desc}}",
+	"{{.Ticket}}: {{.Desc}}",
+	"{{.Ticket}} {{.Desc}}",
+	"{{.Type}}({{.Product}}): {{.Desc}} ({{.Ticket}})",
+}
+
+// branchTemplateCandidates are common branch name layouts, tried when the
+// branches do not follow the configured one.
+var branchTemplateCandidates = []string{
+	convention.DefaultBranchTemplate,
+	"{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}",
+	"{{.Type}}/{{.Ticket}}-{{.Desc}}",
+	"{{.Type}}/{{.Ticket}}/{{.Desc}}",
+	"{{.Type}}/{{.Desc}}/{{.Ticket}}",
+	"{{.Ticket}}-{{.Desc}}",
+}
+
+// commonLayout returns the candidate that most values follow and how many
+// do, if that is more than follow the configured layout and at least half of
+// the values; otherwise it returns "".
+func commonLayout(values []string, configured func(string) bool, candidates []string, compile func(string) (func(string) bool, error)) (string, int) {
+	count := func(follows func(string) bool) int {
+		n := 0
+		for _, v := range values {
+			if follows(v) {
+				n++
+			}
+		}
+		return n
+	}
+	best, bestCount := "", count(configured)
+	for _, candidate := range candidates {
+		follows, err := compile(candidate)
+		if err != nil {
+			continue
+		}
+		if n := count(follows); n > bestCount {
+			best, bestCount = candidate, n
+		}
+	}
+	if best == "" || bestCount*2 < len(values) {
+		return "", 0
+	}
+	return best, bestCount
+}
+
+// analyzeHistory scans the most recent commits on HEAD and all branch names.
+func analyzeHistory(cfg Config, maxCount int) (historyAnalysis, error) {
+	a := historyAnalysis{Types: map[string]int{}, Products: map[string]int{}, TicketProjects: map[string]int{}}
+	rules := cfg.conventionRules()
+	subject, err := rules.SubjectTemplate()
+	if err != nil {
+		return a, err
+	}
+
+	// Records are separated by \x1e since bodies span several lines.
+	out, err := gitOutput("log", "--no-merges", fmt.Sprintf("--max-count=%d", maxCount), "--format=%s%x1f%b%x1e")
+	if err != nil {
+		return a, fmt.Errorf("failed to read history: %w", err)
+	}
+	var headers, bodies []string
+	for _, record := range strings.Split(out, "\x1e") {
+		header, body, _ := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if header != "" {
+			headers, bodies = append(headers, header), append(bodies, body)
+		}
+	}
+
+	// Types, products and descriptions are read with the layout most headers
+	// follow, so the suggestions fit it.
+	a.SubjectTemplate, a.SubjectMatches = commonLayout(headers, func(h string) bool {
+		_, ok := subject.ParseHeader(h)
+		return ok
+	}, subjectTemplateCandidates, func(source string) (func(string) bool, error) {
+		t, err := convention.ParseMessageTemplate(source, "")
+		if err != nil {
+			return nil, err
+		}
+		return func(h string) bool { _, ok := t.ParseHeader(h); return ok }, nil
+	})
+	layout := subject
+	if a.SubjectTemplate != "" {
+		layout = convention.MustParseMessageTemplate(a.SubjectTemplate, "")
+	}
+
+	for i, header := range headers {
+		body := bodies[i]
+		a.Commits++
+		a.HeaderLengths = append(a.HeaderLengths, utf8.RuneCountInString(header))
+		if _, ok := subject.ParseHeader(header); ok {
+			a.ConventionHeaders++
+			if len(rules.LintHeader(header)) == 0 {
+				a.CleanHeaders++
+			}
+		}
+		if h, ok := layout.ParseHeader(header); ok {
+			if h.Type != "" {
+				a.Types[h.Type]++
+			}
+			if h.Product != "" {
+				a.Products[h.Product]++
+			}
+			if h.Description != "" {
+				a.DescLengths = append(a.DescLengths, utf8.RuneCountInString(h.Description))
+			}
+		}
+		tickets := convention.TicketRefPattern.FindAllString(header+"\n"+body, -1)
+		if len(tickets) > 0 {
+			a.WithTicket++
+		}
+		seen := map[string]bool{}
+		for _, t := range tickets {
+			project, _, _ := strings.Cut(t, "-")
+			if !seen[project] {
+				seen[project] = true
+				a.TicketProjects[project]++
+			}
+		}
+	}
+
+	branches, err := localBranchNames()
+	if err != nil {
+		return a, err
+	}
+	pattern := branchNamePattern(cfg)
+	seen := map[string]bool{}
+	var names []string
+	for _, b := range branches {
+		if seen[b] || convention.LongLivedBranchPattern.MatchString(b) {
+			continue
+		}
+		seen[b] = true
+		names = append(names, b)
+		a.Branches++
+		if pattern.MatchString(b) {
+			a.ConventionNames++
+		}
+	}
+	a.BranchTemplate, a.BranchMatches = commonLayout(names, func(name string) bool {
+		_, ok := branchTemplate.Parse(name)
+		return ok
+	}, branchTemplateCandidates, func(source string) (func(string) bool, error) {
+		t, err := convention.ParseBranchTemplate(source)
+		if err != nil {
+			return nil, err
+		}
+		return func(name string) bool { _, ok := t.Parse(name); return ok }, nil
+	})
+	return a, nil
+}
+
+// percentile returns the p-th percentile of values, or 0 if there are none.
+func percentile(values []int, p float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// percent formats part of total as a percentage.
+func percent(part, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return formatPercent(float64(part)*100/float64(total), 0)
+}
+
+// byCount returns the keys of counts, most used first.
+func byCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// suggestedConfig returns the config settings that would let most of the
+// existing history pass validation.
+func (a historyAnalysis) suggestedConfig(cfg Config) map[string]interface{} {
+	suggested := map[string]interface{}{}
+	if a.BranchTemplate != "" {
+		suggested["branch_template"] = a.BranchTemplate
+	}
+	if a.SubjectTemplate != "" {
+		suggested["commit_template"] = CommitTemplateConfig{Subject: a.SubjectTemplate, Body: cfg.CommitTemplate.Body}
+	}
+	if p95 := percentile(a.DescLengths, 0.95); p95 > cfg.Limits.commitDescription() {
+		suggested["limits"] = map[string]int{"commit_description": p95}
+	}
+	if p95 := percentile(a.HeaderLengths, 0.95); p95 > cfg.Style.MaxHeader() {
+		suggested["style"] = map[string]int{"max_header_length": p95}
+	}
+	if unknown := a.unknownProducts(cfg); len(unknown) > 0 {
+		suggested["products"] = append(slices.Clone(cfg.products()), unknown...)
+	}
+	return suggested
+}
+
+// unknownProducts returns the products used in the history but not
+// configured, most used first.
+func (a historyAnalysis) unknownProducts(cfg Config) []string {
+	var unknown []string
+	for _, p := range byCount(a.Products) {
+		if !slices.Contains(cfg.products(), p) {
+			unknown = append(unknown, p)
+		}
+	}
+	return unknown
+}
+
+// printCounts prints counts as "name (n)", most used first.
+func printCounts(label string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	var parts []string
+	for _, k := range byCount(counts) {
+		parts = append(parts, fmt.Sprintf("%s (%d)", k, counts[k]))
+	}
+	fmt.Printf("  %-16s %s\n", label+":", strings.Join(parts, ", "))
+}
+
+// analyzeHistoryCmd represents the command to analyse how an existing history follows the convention.
+var analyzeHistoryCmd = &cobra.Command{
+	Use:   "analyze-history",
+	Short: "Report how the existing history matches the convention and suggest a config",
+	Long: `Scan the recent history of HEAD and all branch names and report how well they
+match the convention, which commit types, products and JIRA projects are in use,
+and which config settings would fit the existing history. When most commit
+headers or branch names follow another common layout, such as
+"[TICKET-123] type: description" or "type/TICKET-123-description", the
+matching commit_template or branch_template is suggested too. Useful when rolling
+the tool out to an existing repository.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		maxCount, _ := cmd.Flags().GetInt("max-count")
+
+		a, err := analyzeHistory(cfg, maxCount)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Analysed %s commits and %s branches.\n\n", formatNumber(a.Commits), formatNumber(a.Branches))
+		fmt.Println("Convention adherence:")
+		fmt.Printf("  %-16s %s\n", "Commit headers:", percent(a.ConventionHeaders, a.Commits))
+		fmt.Printf("  %-16s %s\n", "Style rules:", percent(a.CleanHeaders, a.Commits))
+		fmt.Printf("  %-16s %s\n", "Ticket refs:", percent(a.WithTicket, a.Commits))
+		fmt.Printf("  %-16s %s\n", "Branch names:", percent(a.ConventionNames, a.Branches))
+
+		fmt.Println("\nIn use:")
+		printCounts("Commit types", a.Types)
+		printCounts("Products", a.Products)
+		printCounts("JIRA projects", a.TicketProjects)
+		if len(a.DescLengths) > 0 {
+			fmt.Printf("  %-16s %d characters (95th percentile)\n", "Descriptions:", percentile(a.DescLengths, 0.95))
+		}
+
+		if a.SubjectTemplate != "" {
+			example := convention.MustParseMessageTemplate(a.SubjectTemplate, "").Example()
+			fmt.Printf("\n%s of commit headers look like '%s' rather than the configured layout.\n", percent(a.SubjectMatches, a.Commits), example)
+		}
+		if a.BranchTemplate != "" {
+			example := convention.MustParseBranchTemplate(a.BranchTemplate).Example()
+			fmt.Printf("\n%s of branch names look like '%s' rather than the configured layout.\n", percent(a.BranchMatches, a.Branches), example)
+		}
+
+		if unknown := a.unknownProducts(cfg); len(unknown) > 0 {
+			fmt.Printf("\nProducts used in the history but not offered by create-commit: %s\n", strings.Join(unknown, ", "))
+			fmt.Printf("Add them with 'gh config products add %s'.\n", strings.Join(unknown, " "))
+		}
+
+		suggested := a.suggestedConfig(cfg)
+		if len(suggested) == 0 {
+			fmt.Println("\nThe current configuration fits the existing history.")
+			return nil
+		}
+		data, err := json.MarshalIndent(suggested, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nSuggested additions to %s:\n%s\n", "~/.git-helper-cli/config.json", data)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeHistoryCmd)
+	analyzeHistoryCmd.Flags().Int("max-count", 1000, "Number of recent commits to analyse")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// auditTicket is the incident the running command works on. While it is set,
+// every change made to a repository or remote service is recorded in the
+// audit trail, so the response can be reviewed after the incident.
+var auditTicket string
+
+// AuditEntry is one action recorded in the audit trail.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Ticket string    `json:"ticket"`
+	Repo   string    `json:"repo,omitempty"`
+	Action string    `json:"action"`
+}
+
+// auditLogPath returns the path of the audit trail next to the config file.
+func auditLogPath() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "audit.log"), nil
+}
+
+// audit records an action in the audit trail while working on an incident,
+// noting if it failed. The trail must not get in the way of the response, so
+// failing to write it only warns.
+func audit(action string, err error) {
+	if auditTicket == "" {
+		return
+	}
+	if err != nil {
+		action += " (failed)"
+	}
+	entry := AuditEntry{Time: time.Now(), Ticket: auditTicket, Action: action}
+	entry.Repo, _ = repoKey()
+	if err := appendAuditEntry(entry); err != nil {
+		fmt.Printf("Warning: failed to write the audit trail: %v\n", err)
+	}
+}
+
+// appendAuditEntry adds an entry to the end of the audit trail.
+func appendAuditEntry(entry AuditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// loadAuditTrail reads the audit trail, oldest entry first.
+func loadAuditTrail() ([]AuditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		// No incidents yet.
+		return nil, nil
+	}
+	defer file.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit trail %s: %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// resumeAudit turns the audit trail back on when the current branch was
+// created for an incident, so follow-up commits and pushes are recorded too.
+func resumeAudit() {
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return
+	}
+	// Only hotfix branches can be incident branches, which saves reading the metadata.
+	if b, ok := branchTemplate.Parse(branch); !ok || b.Type != convention.HotfixBranchType {
+		return
+	}
+	repo, err := repoKey()
+	if err != nil {
+		return
+	}
+	md, err := loadMetadata()
+	if err != nil {
+		return
+	}
+	if meta := md.Repos[repo][branch]; meta.Incident {
+		auditTicket = meta.Ticket
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchmarkHook runs a hook command the way git does, from the top level of
+// a repository with a global and a repository configuration, so the startup
+// work every hook pays is measured too.
+func benchmarkHook(b *testing.B, args ...string) {
+	home, repo := b.TempDir(), b.TempDir()
+	b.Setenv("HOME", home)
+	b.Chdir(repo)
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(home, ".git-helper-cli"), 0o755); err != nil {
+		b.Fatal(err)
+	}
+	config := `{"abbreviation":"lv","branch_template":"{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}"}`
+	if err := os.WriteFile(filepath.Join(home, ".git-helper-cli", "config.json"), []byte(config), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, repoConfigFile), []byte(`{"products":["lego","plec"]}`), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	message := filepath.Join(repo, "COMMIT_EDITMSG")
+	if err := os.WriteFile(message, []byte("fix(lego): handle empty playlists\n\nFixes CPRE-11347\n"), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	for i, arg := range args {
+		if arg == "<message-file>" {
+			args[i] = message
+		}
+	}
+	stdout, err := os.Open(os.DevNull)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func(f *os.File) { os.Stdout = f }(os.Stdout)
+	os.Stdout = stdout
+	rootCmd.SetArgs(args)
+	defer rootCmd.SetArgs(nil)
+	for b.Loop() {
+		if err := rootCmd.ExecuteContext(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHookCommitMsg(b *testing.B) {
+	benchmarkHook(b, "hooks", "commit-msg", "<message-file>")
+}
+
+func BenchmarkValidateBranchCommand(b *testing.B) {
+	benchmarkHook(b, "validate", "--branch", "lv/fix/CPRE-11347-window-width")
+}
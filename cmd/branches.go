@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Branches without commits for this many days are flagged when no policy is configured.
+const defaultBranchExpiryDays = 21
+
+// Branches are reported as expiring this many days before they expire.
+const branchExpiryWarningDays = 3
+
+// Spike branches expire this many days after creation when no policy is configured.
+const defaultSpikeExpiryDays = 7
+
+// BranchPolicyConfig configures when branches are flagged as stale.
+type BranchPolicyConfig struct {
+	// ExpiryDays is how long a branch may go without commits before it is flagged.
+	ExpiryDays int `json:"expiry_days,omitempty"`
+	// SpikeDays is how long spike branches live, from their creation.
+	SpikeDays int `json:"spike_days,omitempty"`
+}
+
+// expiryDays returns the configured expiry, defaulting to defaultBranchExpiryDays.
+func (p BranchPolicyConfig) expiryDays() int {
+	if p.ExpiryDays > 0 {
+		return p.ExpiryDays
+	}
+	return defaultBranchExpiryDays
+}
+
+// spikeDays returns the configured spike lifetime, defaulting to defaultSpikeExpiryDays.
+func (p BranchPolicyConfig) spikeDays() int {
+	if p.SpikeDays > 0 {
+		return p.SpikeDays
+	}
+	return defaultSpikeExpiryDays
+}
+
+// localBranch describes a local branch and how close it is to expiring.
+type localBranch struct {
+	Name       string
+	Upstream   string
+	Track      string // e.g. "[ahead 1, behind 2]" or "[gone]"
+	LastCommit time.Time
+	ExpiresIn  int // days until the branch expires, negative once expired
+	// Spike marks experimental branches, which expire on a set date rather than when idle.
+	Spike bool
+}
+
+// expired reports whether the branch has gone without commits for longer than the policy allows.
+func (b localBranch) expired() bool {
+	return b.ExpiresIn < 0
+}
+
+// expiring reports whether the branch has expired or will do so soon.
+func (b localBranch) expiring() bool {
+	return b.ExpiresIn <= branchExpiryWarningDays
+}
+
+// status describes the branch's expiry state for display.
+func (b localBranch) status() string {
+	kind := ""
+	if b.Spike {
+		kind = "spike, "
+	}
+	switch {
+	case b.expired():
+		return fmt.Sprintf("%sexpired %d days ago", kind, -b.ExpiresIn)
+	case b.expiring():
+		return fmt.Sprintf("%sexpires in %d days", kind, b.ExpiresIn)
+	case b.Spike:
+		return "spike"
+	default:
+		return ""
+	}
+}
+
+// listLocalBranches returns the local branches, except long-lived base
+// branches, with their expiry state under the policy. Spike branches expire
+// on the date recorded when they were created.
+func listLocalBranches(policy BranchPolicyConfig) ([]localBranch, error) {
+	out, err := gitOutput("for-each-ref", "--sort=committerdate",
+		"--format=%(refname:short)%09%(upstream:short)%09%(upstream:track)%09%(committerdate:unix)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	days := policy.expiryDays()
+	// Without metadata no branch is known to be a spike, which only affects expiry.
+	var meta map[string]BranchMetadata
+	if repo, err := repoKey(); err == nil {
+		if md, err := loadMetadata(); err == nil {
+			meta = md.Repos[repo]
+		}
+	}
+	var branches []localBranch
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 || convention.LongLivedBranchPattern.MatchString(parts[0]) {
+			continue
+		}
+		last := parseUnix(parts[3])
+		age := int(time.Since(last).Hours() / 24)
+		b := localBranch{
+			Name:       parts[0],
+			Upstream:   parts[1],
+			Track:      parts[2],
+			LastCommit: last,
+			ExpiresIn:  days - age,
+		}
+		if expiresAt := meta[b.Name].ExpiresAt; !expiresAt.IsZero() {
+			b.Spike = true
+			b.ExpiresIn = int(math.Floor(time.Until(expiresAt).Hours() / 24))
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// expiringBranches returns the local branches that have expired or will do so soon.
+func expiringBranches(policy BranchPolicyConfig) ([]localBranch, error) {
+	branches, err := listLocalBranches(policy)
+	if err != nil {
+		return nil, err
+	}
+	var expiring []localBranch
+	for _, b := range branches {
+		if b.expiring() {
+			expiring = append(expiring, b)
+		}
+	}
+	return expiring, nil
+}
+
+// defaultBaseBranch returns the branch origin/HEAD points to, e.g. origin/main.
+func defaultBaseBranch() (string, error) {
+	base, err := gitOutput("rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the default branch of origin (try 'git remote set-head origin --auto'): %w", err)
+	}
+	return base, nil
+}
+
+// branchColumns are the columns of list-branches.
+var branchColumns = []tableColumn{
+	{name: "name"},
+	{name: "last_commit", kind: columnDate},
+	{name: "upstream"},
+	{name: "status", truncate: true},
+}
+
+// deleteBranches deletes local branches after previewing them and, with
+// remote set, their upstream branches too. Unmerged branches are only deleted
+// when confirmed one by one, so force skips them.
+func deleteBranches(cfg Config, branches []localBranch, remote, force bool) error {
+	names := make([]string, len(branches))
+	for i, b := range branches {
+		names[i] = b.Name
+	}
+	// Deleting a branch usually means the work is finished, so point out loose ends.
+	for _, name := range names {
+		if ticket, err := extractTicketFromBranch(name); err == nil {
+			if items, err := openTodos(ticket); err == nil && len(items) > 0 {
+				fmt.Printf("Warning: %s still has %d open TODOs (see 'gh todo --ticket %s').\n", linkTicket(ticket), len(items), ticket)
+			}
+		}
+	}
+	confirm, err := confirmDeletion(cfg, "branches", names, "", force)
+	if err != nil || !confirm {
+		return err
+	}
+
+	var deleted []localBranch
+	for _, b := range branches {
+		if err := runGit("branch", "-d", b.Name); err == nil {
+			deleted = append(deleted, b)
+			continue
+		}
+		if force {
+			fmt.Printf("Skipped '%s': it is not fully merged.\n", b.Name)
+			continue
+		}
+		// Unmerged work is only thrown away when explicitly confirmed.
+		unmerged, err := confirmAction(cfg, true, fmt.Sprintf("'%s' is not fully merged. Delete it anyway?", b.Name))
+		if err != nil {
+			return err
+		}
+		if unmerged {
+			if err := runGit("branch", "-D", b.Name); err != nil {
+				return fmt.Errorf("failed to delete branch '%s': %w", b.Name, err)
+			}
+			deleted = append(deleted, b)
+		}
+	}
+	if !remote {
+		return nil
+	}
+
+	// Upstream branches that are already gone need no deleting.
+	byRemote := map[string][]string{}
+	var order []string
+	for _, b := range deleted {
+		if b.Upstream == "" || b.Track == "[gone]" {
+			continue
+		}
+		name, branch, ok := strings.Cut(b.Upstream, "/")
+		if !ok {
+			continue
+		}
+		if _, seen := byRemote[name]; !seen {
+			order = append(order, name)
+		}
+		byRemote[name] = append(byRemote[name], branch)
+	}
+	for _, name := range order {
+		confirm, err := confirmDeletion(cfg, "branches", byRemote[name], name, force)
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			continue
+		}
+		if err := runGit(append([]string{"push", name, "--delete"}, byRemote[name]...)...); err != nil {
+			return fmt.Errorf("failed to delete branches from %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// listBranchesCmd represents the command to list local branches and their expiry state.
+var listBranchesCmd = &cobra.Command{
+	Use:   "list-branches",
+	Short: "List local branches and flag the ones that are going stale",
+	Long: `List local branches with their upstream and last commit date. Branches that
+have gone without commits for longer than the branch policy allows (21 days
+unless "branch_policy.expiry_days" is set in the config file) are flagged, as
+are the ones about to expire.
+
+Use --expiring to only list flagged branches, and --sort, --columns and
+--format (table, json, csv or markdown) to shape the output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		onlyExpiring, _ := cmd.Flags().GetBool("expiring")
+
+		branches, err := listLocalBranches(cfg.BranchPolicy)
+		if err != nil {
+			return err
+		}
+		t := newTable(branchColumns...)
+		for _, b := range branches {
+			if onlyExpiring && !b.expiring() {
+				continue
+			}
+			t.add(b.Name, b.LastCommit, strings.TrimSpace(b.Upstream+" "+b.Track), b.status())
+		}
+		return t.render(cmd, "No branches to show.")
+	},
+}
+
+// cleanupBranchesCmd represents the command to delete or sync expired branches.
+var cleanupBranchesCmd = &cobra.Command{
+	Use:   "cleanup-branches",
+	Short: "Delete or sync branches that have expired under the branch policy",
+	Long: `Offer the local branches that have gone without commits for longer than the
+branch policy allows, and either delete them or sync them by rebasing them onto
+the default branch of origin. Expired spike branches (see 'gh create-branch
+--spike') are listed first and already selected for deletion.
+
+Before deleting, the command lists exactly which branches will go. With
+--remote, their upstream branches are deleted too, which has to be confirmed by
+typing the remote's name. --force deletes every expired branch without asking,
+for scripts; branches that are not fully merged are then skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		current, err := getCurrentBranch()
+		if err != nil {
+			return err
+		}
+
+		branches, err := listLocalBranches(cfg.BranchPolicy)
+		if err != nil {
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		remote, _ := cmd.Flags().GetBool("remote")
+
+		var expired []localBranch
+		for _, b := range branches {
+			if b.expired() && b.Name != current {
+				expired = append(expired, b)
+			}
+		}
+		// Expired spikes were meant to be thrown away, so they are offered
+		// first and already selected.
+		slices.SortStableFunc(expired, func(a, b localBranch) int {
+			switch {
+			case a.Spike == b.Spike:
+				return 0
+			case a.Spike:
+				return -1
+			default:
+				return 1
+			}
+		})
+		var options, spikes []string
+		for _, b := range expired {
+			option := fmt.Sprintf("%s (%s)", b.Name, b.status())
+			options = append(options, option)
+			if b.Spike {
+				spikes = append(spikes, option)
+			}
+		}
+		if len(options) == 0 {
+			fmt.Printf("No branches older than %d days.\n", cfg.BranchPolicy.expiryDays())
+			return nil
+		}
+
+		// With --force every expired branch is deleted, for scripts.
+		picked := make([]int, len(expired))
+		for i := range picked {
+			picked[i] = i
+		}
+		action := "Delete"
+		if !force {
+			if err := askOne(&survey.MultiSelect{
+				Message:  "Choose the branches to clean up:",
+				Options:  options,
+				Default:  spikes,
+				PageSize: 15,
+			}, &picked, survey.WithValidator(survey.MinItems(1))); err != nil {
+				return err
+			}
+			if err := askOne(&survey.Select{
+				Message: "What would you like to do with them?",
+				Options: []string{"Delete", "Sync with the default branch"},
+			}, &action); err != nil {
+				return err
+			}
+		}
+		chosen := make([]localBranch, len(picked))
+		names := make([]string, len(picked))
+		for i, idx := range picked {
+			chosen[i] = expired[idx]
+			names[i] = expired[idx].Name
+		}
+
+		if action == "Delete" {
+			return deleteBranches(cfg, chosen, remote, force)
+		}
+
+		dirty, err := workingTreeDirty()
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("working tree has uncommitted changes; commit or stash them before syncing branches")
+		}
+		if err := runGit("fetch", "origin"); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+		base, err := defaultBaseBranch()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := runGit("rebase", base, name); err != nil {
+				return fmt.Errorf("failed to rebase '%s' onto %s; resolve the conflicts and run 'git rebase --continue': %w", name, base, err)
+			}
+		}
+		return runGit("checkout", current)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listBranchesCmd)
+	rootCmd.AddCommand(cleanupBranchesCmd)
+	listBranchesCmd.Flags().Bool("expiring", false, "Only list branches that have expired or are about to")
+	addTableFlags(listBranchesCmd, branchColumns, "")
+	cleanupBranchesCmd.Flags().Bool("remote", false, "Also delete the upstream branches of deleted branches")
+	cleanupBranchesCmd.Flags().Bool("force", false, "Delete every expired branch without prompting")
+}
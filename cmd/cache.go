@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheDir returns the directory of the caches next to the config file, creating it if needed.
+func cacheDir() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(configPath), "cache")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// cacheCmd groups the commands that manage the local caches.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cached pull request statuses and JIRA tickets",
+}
+
+// cacheClearCmd represents the command to delete the cached data.
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cached pull request statuses and JIRA tickets",
+	Long: `Delete the cached pull request statuses and assigned JIRA tickets, so they are
+fetched again on next use. The files to be deleted are listed first; --force
+deletes them without asking.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		dir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		var files []string
+		for _, e := range entries {
+			if !e.IsDir() {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+		if len(files) == 0 {
+			fmt.Println("The cache is empty.")
+			return nil
+		}
+
+		confirm, err := confirmDeletion(cfg, "cache files", files, "", force)
+		if err != nil || !confirm {
+			return err
+		}
+		for _, f := range files {
+			if err := os.Remove(f); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", f, err)
+			}
+		}
+		fmt.Printf("Deleted %d cache files.\n", len(files))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheClearCmd.Flags().Bool("force", false, "Delete the cache files without prompting")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// caseCollision returns the existing branch whose name, or one of whose
+// directories, differs from name only in case. Such branches map to the same
+// files on case-insensitive filesystems (macOS, Windows) and break checkouts;
+// that includes a branch and a directory of another, such as foo and FOO/bar.
+// The second result is how many leading path segments collide.
+func caseCollision(name string, existing []string) (string, int) {
+	segments := strings.Split(name, "/")
+	for _, other := range existing {
+		otherSegments := strings.Split(other, "/")
+		for i := 0; i < len(segments) && i < len(otherSegments); i++ {
+			if !strings.EqualFold(segments[i], otherSegments[i]) {
+				break
+			}
+			if segments[i] != otherSegments[i] {
+				return other, i + 1
+			}
+		}
+	}
+	return "", 0
+}
+
+// localBranchNames returns local branch names and remote branch names without
+// their remote prefix.
+func localBranchNames() ([]string, error) {
+	refs, err := listRefs("refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	remotes, _ := gitOutput("remote")
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		for _, remote := range strings.Fields(remotes) {
+			if rest, ok := strings.CutPrefix(ref, remote+"/"); ok {
+				ref = rest
+				break
+			}
+		}
+		names = append(names, ref)
+	}
+	return names, nil
+}
+
+// resolveCaseCollision checks a new branch name against existing branches
+// that differ only in case. When ask is set it offers to adopt the existing
+// casing; otherwise, and for names that would still collide, it refuses.
+func resolveCaseCollision(name string, ask bool) (string, error) {
+	existing, err := localBranchNames()
+	if err != nil {
+		return "", err
+	}
+	other, n := caseCollision(name, existing)
+	if other == "" {
+		return name, nil
+	}
+
+	// Adopt the existing casing for the colliding segments.
+	segments := strings.Split(name, "/")
+	copy(segments, strings.Split(other, "/")[:n])
+	renamed := strings.Join(segments, "/")
+	for _, e := range existing {
+		if e == renamed {
+			return "", fmt.Errorf("branch '%s' already exists and differs only in case from '%s'; switch to it with 'git checkout %s'", e, name, e)
+		}
+		// git cannot have both a branch and a directory of branches by the same name.
+		if strings.HasPrefix(e, renamed+"/") || strings.HasPrefix(renamed, e+"/") {
+			return "", fmt.Errorf("branch name '%s' collides with '%s' on case-insensitive filesystems, and '%s' would collide with it everywhere; pick another description", name, other, renamed)
+		}
+	}
+
+	if !ask {
+		return "", fmt.Errorf("branch name '%s' collides with '%s' on case-insensitive filesystems; use '%s' instead", name, other, renamed)
+	}
+	fmt.Printf("Warning: '%s' differs only in case from the existing branch '%s', which breaks checkouts on macOS and Windows.\n", name, other)
+	rename := true
+	if err := askOne(&survey.Confirm{
+		Message: fmt.Sprintf("Create '%s' instead?", renamed),
+		Default: true,
+	}, &rename); err != nil {
+		return "", err
+	}
+	if !rename {
+		return "", fmt.Errorf("branch name '%s' collides with '%s' on case-insensitive filesystems", name, other)
+	}
+	return renamed, nil
+}
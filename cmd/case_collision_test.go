@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestCaseCollision(t *testing.T) {
+	existing := []string{"main", "lv/fix/CPRE-1-login", "Feature/search", "release"}
+	for _, tt := range []struct {
+		name  string
+		other string
+		n     int
+	}{
+		{"lv/fix/CPRE-1-login", "", 0},
+		{"lv/fix/cpre-1-login", "lv/fix/CPRE-1-login", 3},
+		{"LV/feat/CPRE-2-search", "lv/fix/CPRE-1-login", 1},
+		{"feature/search", "Feature/search", 1},
+		{"feature", "Feature/search", 1},
+		{"Release/1.0", "release", 1},
+		{"lv/feat/CPRE-2-search", "", 0},
+	} {
+		other, n := caseCollision(tt.name, existing)
+		if other != tt.other || n != tt.n {
+			t.Errorf("caseCollision(%q) = %q, %d; want %q, %d", tt.name, other, n, tt.other, tt.n)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// cherryPickConflictHelp explains how to finish a cherry-pick that stopped on a conflict.
+const cherryPickConflictHelp = `cherry-pick stopped on a conflict. To continue:
+  1. Fix the conflicting files and stage them with 'git add'.
+  2. Run 'git cherry-pick --continue' to carry on with the remaining commits.
+Or run 'git cherry-pick --skip' to drop this commit, or 'git cherry-pick --abort' to undo everything`
+
+// searchCommits returns commits on any branch that are not on HEAD yet and
+// whose message mentions query, newest first, as "<hash>\t<subject>" lines.
+func searchCommits(query string) ([]string, error) {
+	out, err := gitOutput("log", "--all", "--no-merges", "--regexp-ignore-case", "--fixed-strings",
+		"--grep="+query, "--format=%h%x09%s (%an, %ar)", "--not", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search commits: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// cherryPickCmd represents the command to find and cherry-pick commits by message.
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick [ticket-or-text]",
+	Short: "Search commits by ticket or text and cherry-pick them onto the current branch",
+	Long: `Search the commits of all branches for a JIRA ticket or any text in the
+commit message, pick the ones you want, and cherry-pick them onto the current
+branch in their original order.
+
+Each cherry-picked commit gets a "(cherry picked from commit ...)" line so its
+origin can be traced.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		// 1. Search.
+		var query string
+		if len(args) == 1 {
+			query = args[0]
+		} else if err := askOne(&survey.Input{
+			Message: "Search commits for (JIRA ticket or text):",
+		}, &query, survey.WithValidator(survey.Required)); err != nil {
+			return err
+		}
+		commits, err := searchCommits(query)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			fmt.Printf("No commits mentioning '%s' found outside the current branch.\n", query)
+			return nil
+		}
+
+		// 2. Pick.
+		var picked []int
+		if err := askOne(&survey.MultiSelect{
+			Message:  "Choose the commits to cherry-pick:",
+			Options:  commits,
+			PageSize: 15,
+		}, &picked, survey.WithValidator(survey.MinItems(1))); err != nil {
+			return err
+		}
+
+		// Apply oldest first, the reverse of the log order.
+		slices.Sort(picked)
+		slices.Reverse(picked)
+		var hashes []string
+		for _, i := range picked {
+			hash, _, _ := strings.Cut(commits[i], "\t")
+			hashes = append(hashes, hash)
+		}
+
+		confirm, err := confirmAction(cfg, false, fmt.Sprintf("Cherry-pick %d commit(s) onto the current branch?", len(hashes)))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Cherry-pick aborted.")
+			return nil
+		}
+
+		// 3. Cherry-pick with provenance.
+		if err := runGit(append([]string{"cherry-pick", "-x"}, hashes...)...); err != nil {
+			return fmt.Errorf("%s: %w", cherryPickConflictHelp, err)
+		}
+		reportDone(fmt.Sprintf("Cherry-picked %d commit(s) successfully!", len(hashes)), fmt.Sprintf("%d commit(s) would be cherry-picked.", len(hashes)))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cherryPickCmd)
+}
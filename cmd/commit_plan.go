@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// commitPlan is a declarative list of commits, read from a YAML (or JSON) file.
+type commitPlan struct {
+	Commits []plannedCommit `yaml:"commits"`
+}
+
+// plannedCommit is one commit of a plan.
+type plannedCommit struct {
+	// Files are paths or glob patterns, e.g. "src/**/*.go".
+	Files       []string `yaml:"files"`
+	Type        string   `yaml:"type"`
+	Product     string   `yaml:"product"`
+	Description string   `yaml:"description"`
+	// Ticket defaults to the ticket of the current branch.
+	Ticket string `yaml:"ticket"`
+}
+
+// loadCommitPlan reads a plan file.
+func loadCommitPlan(path string) (commitPlan, error) {
+	var plan commitPlan
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return plan, fmt.Errorf("failed to read plan: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(plan.Commits) == 0 {
+		return plan, fmt.Errorf("plan '%s' has no commits", path)
+	}
+	return plan, nil
+}
+
+// validate checks a planned commit against the conventions.
+func (c plannedCommit) validate(cfg Config) error {
+	if len(c.Files) == 0 {
+		return fmt.Errorf("no files listed")
+	}
+	if err := convention.ValidateChoice("type", c.Type, cfg.commitTypes()); err != nil {
+		return err
+	}
+	if err := convention.ValidateChoice("product", c.Product, cfg.products()); err != nil {
+		return err
+	}
+	if err := cfg.validateTicketID(c.Ticket); err != nil {
+		return err
+	}
+	return commitDescValidator(cfg, c.Type, c.Product)(c.Description)
+}
+
+// globPathspecs turns plan file patterns into git pathspecs where "**" matches across directories.
+func globPathspecs(files []string) []string {
+	specs := make([]string, len(files))
+	for i, f := range files {
+		specs[i] = ":(glob)" + f
+	}
+	return specs
+}
+
+// commitPlanCmd groups the commit plan commands.
+var commitPlanCmd = &cobra.Command{
+	Use:   "commit-plan",
+	Short: "Create a series of commits from a plan file",
+}
+
+// commitPlanApplyCmd represents the command to execute a commit plan.
+var commitPlanApplyCmd = &cobra.Command{
+	Use:   "apply <plan.yaml>",
+	Short: "Stage and commit the changes described by a plan file",
+	Long: `Read a list of commits from a YAML file and create them in order, staging the
+files matching each entry's paths or globs:
+
+  commits:
+    - files: ["svc/lego/**/*.go"]
+      type: fix
+      product: lego
+      description: rename the config loader
+    - files: ["docs/"]
+      type: feat
+      product: plec
+      description: document the new loader
+      ticket: CPRE-11348
+
+Every entry is validated against the conventions before anything is committed.
+The ticket defaults to the one in the current branch name. The index must be
+empty when starting, so only the planned changes end up in the commits.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		plan, err := loadCommitPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		if gitRun("diff", "--cached", "--quiet") != nil {
+			return fmt.Errorf("there are already staged changes. Please commit or unstage them before applying a plan")
+		}
+		showTrunkStatus(cfg)
+
+		// 1. Validate every entry before touching the repository.
+		branchTicket := ""
+		if branch, err := getCurrentBranch(); err == nil {
+			branchTicket, _ = extractTicketFromBranch(branch)
+		}
+		var problems []string
+		for i := range plan.Commits {
+			c := &plan.Commits[i]
+			if c.Ticket == "" {
+				c.Ticket = branchTicket
+			}
+			if err := c.validate(cfg); err != nil {
+				problems = append(problems, fmt.Sprintf("commit %d: %v", i+1, err))
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("invalid plan:\n  %s", strings.Join(problems, "\n  "))
+		}
+
+		fmt.Println("The following commits will be created:")
+		for i, c := range plan.Commits {
+			fmt.Printf("%d. %s(%s): %s [%s]\n", i+1, c.Type, c.Product, c.Description, strings.Join(c.Files, ", "))
+		}
+		confirm, err := confirmAction(cfg, false, "Do you want to proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Plan aborted.")
+			return nil
+		}
+
+		// 2. Stage and commit each entry in turn.
+		for i, c := range plan.Commits {
+			if err := runGit(append([]string{"add", "--all", "--"}, globPathspecs(c.Files)...)...); err != nil {
+				return fmt.Errorf("commit %d: failed to stage files: %w", i+1, err)
+			}
+			// Nothing is staged in read-only mode, so there is nothing to check.
+			if !readOnly && gitRun("diff", "--cached", "--quiet") == nil {
+				return fmt.Errorf("commit %d: no changes match %s; %d of %d commits were created", i+1, strings.Join(c.Files, ", "), i, len(plan.Commits))
+			}
+			messages, err := commitMessages(cfg, c.Type, c.Product, c.Description, c.Ticket, "", nil)
+			if err != nil {
+				return err
+			}
+			commitArgs := []string{"commit"}
+			for _, msg := range messages {
+				commitArgs = append(commitArgs, "-m", msg)
+			}
+			if err := runGit(commitArgs...); err != nil {
+				return fmt.Errorf("commit %d: failed to create commit; %d of %d commits were created: %w", i+1, i, len(plan.Commits), err)
+			}
+		}
+
+		fmt.Printf("Created %d commits.\n", len(plan.Commits))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commitPlanCmd)
+	commitPlanCmd.AddCommand(commitPlanApplyCmd)
+}
@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// ComplianceConfig configures the compliance report.
+type ComplianceConfig struct {
+	// Endpoint receives the JSON report when --post is used.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// complianceReport summarises how well a repository follows the convention.
+type complianceReport struct {
+	Repo              string    `json:"repo"`
+	Days              int       `json:"days,omitempty"`
+	Since             time.Time `json:"since"`
+	Branches          int       `json:"branches"`
+	CompliantBranches int       `json:"compliant_branches"`
+	Commits           int       `json:"commits"`
+	CompliantCommits  int       `json:"compliant_commits"`
+	Score             float64   `json:"score"`
+	BadBranches       []string  `json:"non_compliant_branches,omitempty"`
+	BadCommits        []string  `json:"non_compliant_commits,omitempty"`
+}
+
+// branchNamePattern matches branch names produced by create-branch.
+func branchNamePattern(cfg Config) *regexp.Regexp {
+	return branchTemplate.Pattern(cfg.validBranchTypes())
+}
+
+// recentBranches returns local and remote branches with commits in the last
+// since, without remote prefixes and long-lived base branches.
+func recentBranches(since time.Time) ([]string, error) {
+	out, err := gitOutput("for-each-ref", "--format=%(refname:short)%09%(symref)%09%(committerdate:unix)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	cutoff := since.Unix()
+	remotes, _ := gitOutput("remote")
+
+	seen := map[string]bool{}
+	var branches []string
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 || parts[1] != "" {
+			continue
+		}
+		name := parts[0]
+		for _, remote := range strings.Fields(remotes) {
+			if rest, ok := strings.CutPrefix(name, remote+"/"); ok {
+				name = rest
+				break
+			}
+		}
+		date, _ := strconv.ParseInt(parts[2], 10, 64)
+		if date < cutoff || seen[name] || convention.LongLivedBranchPattern.MatchString(name) {
+			continue
+		}
+		seen[name] = true
+		branches = append(branches, name)
+	}
+	return branches, nil
+}
+
+// buildComplianceReport scores the branches and commits since the given time.
+func buildComplianceReport(cfg Config, since time.Time, days int) (complianceReport, error) {
+	repo, err := repoKey()
+	if err != nil {
+		return complianceReport{}, fmt.Errorf("not inside a git repository: %w", err)
+	}
+	report := complianceReport{Repo: repo, Days: days, Since: since}
+
+	branches, err := recentBranches(since)
+	if err != nil {
+		return report, err
+	}
+	pattern := branchNamePattern(cfg)
+	for _, b := range branches {
+		report.Branches++
+		if pattern.MatchString(b) {
+			report.CompliantBranches++
+		} else {
+			report.BadBranches = append(report.BadBranches, b)
+		}
+	}
+
+	commits, err := commitHeaders("--since=" + since.Format(time.RFC3339))
+	if err != nil {
+		return report, err
+	}
+	rules := cfg.conventionRules()
+	for _, c := range commits {
+		report.Commits++
+		if len(rules.LintHeader(c[1])) == 0 {
+			report.CompliantCommits++
+		} else {
+			report.BadCommits = append(report.BadCommits, c[0]+" "+c[1])
+		}
+	}
+
+	if total := report.Branches + report.Commits; total > 0 {
+		report.Score = float64(report.CompliantBranches+report.CompliantCommits) * 100 / float64(total)
+	}
+	return report, nil
+}
+
+// markdown renders the report as a Markdown summary.
+func (r complianceReport) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Convention compliance: %s\n\n", formatPercent(r.Score, 0))
+	if r.Days > 0 {
+		fmt.Fprintf(&b, "Repository `%s`, last %d days.\n\n", r.Repo, r.Days)
+	} else {
+		fmt.Fprintf(&b, "Repository `%s`, week starting %s.\n\n", r.Repo, formatDate(r.Since))
+	}
+	fmt.Fprintf(&b, "| | Compliant | Total |\n|---|---|---|\n")
+	fmt.Fprintf(&b, "| Branches | %s | %s |\n", formatNumber(r.CompliantBranches), formatNumber(r.Branches))
+	fmt.Fprintf(&b, "| Commits | %s | %s |\n", formatNumber(r.CompliantCommits), formatNumber(r.Commits))
+	if len(r.BadBranches) > 0 {
+		b.WriteString("\n### Non-compliant branches\n\n")
+		for _, name := range r.BadBranches {
+			fmt.Fprintf(&b, "- `%s`\n", name)
+		}
+	}
+	if len(r.BadCommits) > 0 {
+		b.WriteString("\n### Non-compliant commits\n\n")
+		for _, c := range r.BadCommits {
+			fmt.Fprintf(&b, "- `%s`\n", c)
+		}
+	}
+	return b.String()
+}
+
+// postComplianceReport sends the JSON report to the configured endpoint.
+func postComplianceReport(endpoint string, report complianceReport) error {
+	if skipReadOnly("POST " + endpoint) {
+		return nil
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return explainCancel(err, "POST "+endpoint, apiTimeout)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// complianceCmd represents the command to score a repository against the convention.
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Score how well recent branches and commits follow the convention",
+	Long: `Compute the percentage of recent branches and commits that follow the naming
+and commit conventions. Long-lived branches such as main, develop and release/*
+are not counted.
+
+Use --week to score the current week instead, starting on the first day of the
+week of the configured locale ("locale.week_start" overrides it).
+
+Use --format json for dashboards, and --post to send the JSON report to the
+"compliance.endpoint" URL from the config file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		days, _ := cmd.Flags().GetInt("days")
+		week, _ := cmd.Flags().GetBool("week")
+		format, _ := cmd.Flags().GetString("format")
+		post, _ := cmd.Flags().GetBool("post")
+
+		since := time.Now().AddDate(0, 0, -days)
+		if week {
+			since, days = startOfWeek(time.Now()), 0
+		}
+		report, err := buildComplianceReport(cfg, since, days)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "json":
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		case "markdown":
+			fmt.Print(report.markdown())
+		default:
+			return fmt.Errorf("unknown format '%s'; use markdown or json", format)
+		}
+
+		if post {
+			if cfg.Compliance.Endpoint == "" {
+				return fmt.Errorf("no compliance.endpoint configured")
+			}
+			if err := postComplianceReport(cfg.Compliance.Endpoint, report); err != nil {
+				return fmt.Errorf("failed to post report: %w", err)
+			}
+			// The report itself may be piped, e.g. into jq, so this goes to stderr.
+			if !readOnly {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Report posted to %s\n", cfg.Compliance.Endpoint)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(complianceCmd)
+	complianceCmd.Flags().Int("days", 30, "Only consider branches and commits from the last N days")
+	complianceCmd.Flags().Bool("week", false, "Only consider the current week, starting on the locale's first day of the week")
+	complianceCmd.Flags().String("format", "markdown", "Output format: markdown or json")
+	complianceCmd.Flags().Bool("post", false, "Post the JSON report to compliance.endpoint")
+}
@@ -6,14 +6,93 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
 	"github.com/spf13/cobra"
 )
 
 // Config represents the configuration structure.
 type Config struct {
-	Abbreviation string `json:"abbreviation"`
+	Abbreviation  string `json:"abbreviation"`
+	Confirmations string `json:"confirmations,omitempty"`
+	// PromptBackend is what shows the questions: survey (the default) or bubbletea.
+	PromptBackend string `json:"prompt_backend,omitempty"`
+	// Collaborators are offered as co-authors by create-commit.
+	Collaborators []Collaborator `json:"collaborators,omitempty"`
+	Style         StyleConfig    `json:"style,omitzero"`
+	// ProductPaths maps each product to the paths it owns in a monorepo.
+	ProductPaths map[string][]string `json:"product_paths,omitempty"`
+	Release      ReleaseConfig       `json:"release,omitzero"`
+	GitHub       GitHubConfig        `json:"github,omitzero"`
+	GitLab       GitLabConfig        `json:"gitlab,omitzero"`
+	Bitbucket    BitbucketConfig     `json:"bitbucket,omitzero"`
+	Jira         JiraConfig          `json:"jira,omitzero"`
+	Slack        SlackConfig         `json:"slack,omitzero"`
+	Compliance   ComplianceConfig    `json:"compliance,omitzero"`
+	BranchPolicy BranchPolicyConfig  `json:"branch_policy,omitzero"`
+	Translation  TranslationConfig   `json:"translation,omitzero"`
+	Limits       LimitsConfig        `json:"limits,omitzero"`
+	Ingest       IngestConfig        `json:"ingest,omitzero"`
+	Timeouts     TimeoutsConfig      `json:"timeouts,omitzero"`
+	// Provider overrides the hosting provider detected from the origin remote.
+	Provider string `json:"provider,omitempty"`
+	// TerminalTitle names the terminal or tmux window after the ticket when switching branches.
+	TerminalTitle bool `json:"terminal_title,omitempty"`
+	// Hyperlinks makes printed tickets links to JIRA: auto (in terminals known
+	// to support them), always or never.
+	Hyperlinks string `json:"hyperlinks,omitempty"`
+	// ReadOnly blocks all operations that change a repository or remote service, like --read-only.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// ReservedBranches are branch name patterns, such as "release/*", that
+	// create-branch must never produce because automation keys off them.
+	ReservedBranches []string `json:"reserved_branches,omitempty"`
+	// BaseBranches are the branches of origin create-branch offers to start
+	// from, e.g. "main", "develop" or "release/*".
+	BaseBranches []string `json:"base_branches,omitempty"`
+	// Environments are the environments, such as prod, staging or a customer,
+	// that create-branch and create-commit ask about, for incident work.
+	Environments []string `json:"environments,omitempty"`
+	// Workspace lists repositories, or directories of repositories, to keep in sync.
+	Workspace []string `json:"workspace,omitempty"`
+	// BranchTypes and CommitTypes replace the default "fix" and "feat" options.
+	BranchTypes []string `json:"branch_types,omitempty"`
+	CommitTypes []string `json:"commit_types,omitempty"`
+	// Products replaces the default product scopes offered by create-commit.
+	Products []string `json:"products,omitempty"`
+	// TicketProjects limits ticket IDs to these JIRA project keys, e.g. "CPRE".
+	TicketProjects []string `json:"ticket_projects,omitempty"`
+	// ValidateTickets makes create-branch and create-commit check that the
+	// ticket exists in JIRA and is not closed.
+	ValidateTickets bool `json:"validate_tickets,omitempty"`
+	// BranchTemplate replaces the default branch name format, e.g.
+	// "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}".
+	BranchTemplate string `json:"branch_template,omitempty"`
+	// CommitTemplate replaces the default commit subject and ticket line.
+	CommitTemplate CommitTemplateConfig `json:"commit_template,omitzero"`
+	// Amend controls when create-commit offers to amend the previous commit.
+	Amend AmendConfig `json:"amend,omitzero"`
+	// Signing makes create-commit sign commits with GPG, SSH or X.509.
+	Signing SigningConfig `json:"signing,omitzero"`
+	// Storage selects where metadata is kept, e.g. in a store shared by a team.
+	Storage StorageConfig `json:"storage,omitzero"`
+	// Locale sets how reports format dates and numbers, and the first day of the week.
+	Locale LocaleConfig `json:"locale,omitzero"`
+	// Notifications routes desktop, Slack and JIRA notifications.
+	Notifications NotificationsConfig `json:"notifications,omitzero"`
+	// Incident sets where the incident command files new incident tickets.
+	Incident IncidentConfig `json:"incident,omitzero"`
+	// Push sets whether new branches are pushed to origin right away.
+	Push PushConfig `json:"push,omitzero"`
+	// Trunk warns about branches that live too long, for trunk-based development.
+	Trunk TrunkConfig `json:"trunk,omitzero"`
+	// Prompts reorders, hides and adds the questions of the create-branch and
+	// create-commit flows, keyed by command name.
+	Prompts map[string]FlowPromptsConfig `json:"prompts,omitempty"`
+	// CustomFields are asked in create-branch and create-commit and used in the
+	// branch and commit templates as {{.Fields.name}}.
+	CustomFields []CustomStep `json:"custom_fields,omitempty"`
 }
 
 // configFilePath returns the path to the config file in the user's home directory.
@@ -47,8 +126,23 @@ func saveConfig(cfg Config) error {
 	return encoder.Encode(cfg)
 }
 
-// loadConfig reads the configuration from file.
+// loadConfig reads the configuration from file, with the settings of the
+// current repository's .git-helper.json applied on top.
 func loadConfig() (Config, error) {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return cfg, err
+	}
+	repoCfg, ok, err := loadRepoConfig()
+	if err != nil || !ok {
+		return cfg, err
+	}
+	return repoCfg.apply(cfg), nil
+}
+
+// loadGlobalConfig reads the configuration from the file in the home
+// directory only; commands that save the configuration start from it.
+func loadGlobalConfig() (Config, error) {
 	var cfg Config
 	configPath, err := configFilePath()
 	if err != nil {
@@ -71,7 +165,7 @@ func loadConfig() (Config, error) {
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure your git-helper-cli settings",
-	Long:  "Set or update your two-letter abbreviation used in branch naming.",
+	Long:  "Set or update your two-letter abbreviation used in branch naming, the branch and commit types offered, the branch name format, and how often you are asked to confirm actions.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var abbrev string
 
@@ -95,11 +189,67 @@ var configCmd = &cobra.Command{
 			return nil
 		}
 
-		if err := survey.AskOne(prompt, &abbrev, survey.WithValidator(validator)); err != nil {
+		// Start from the existing configuration so other settings are preserved.
+		cfg, err := loadGlobalConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		prompt.Default = cfg.Abbreviation
+
+		if err := askOne(prompt, &abbrev, survey.WithValidator(validator)); err != nil {
+			return err
+		}
+
+		// Prompt for how often the tool should ask for confirmation.
+		confirmations := cfg.confirmationLevel()
+		if err := askOne(&survey.Select{
+			Message: "When should the tool ask \"are you sure?\":",
+			Options: confirmationLevels,
+			Default: confirmations,
+			Description: func(value string, index int) string {
+				return confirmationDescriptions[value]
+			},
+		}, &confirmations); err != nil {
+			return err
+		}
+
+		// Prompt for the branch and commit types offered by create-branch and create-commit.
+		branchTypes := strings.Join(cfg.branchTypes(), ", ")
+		if err := askOne(&survey.Input{
+			Message: "Branch types (comma-separated):",
+			Default: branchTypes,
+		}, &branchTypes, survey.WithValidator(typeListValidator)); err != nil {
+			return err
+		}
+		commitTypes := strings.Join(cfg.commitTypes(), ", ")
+		if err := askOne(&survey.Input{
+			Message: "Commit types (comma-separated):",
+			Default: commitTypes,
+		}, &commitTypes, survey.WithValidator(typeListValidator)); err != nil {
+			return err
+		}
+
+		// Prompt for the branch name format, previewing it before it is saved.
+		template, err := askBranchTemplate(cfg.BranchTemplate, abbrev, parseTypeList(branchTypes), cfg.customFieldNames())
+		if err != nil {
+			return err
+		}
+
+		// Prompt for whether to name the terminal window after the current ticket.
+		terminalTitle := cfg.TerminalTitle
+		if err := askOne(&survey.Confirm{
+			Message: "Set the terminal/tmux window title to the ticket when switching branches?",
+			Default: terminalTitle,
+		}, &terminalTitle); err != nil {
 			return err
 		}
 
-		cfg := Config{Abbreviation: abbrev}
+		cfg.Abbreviation = abbrev
+		cfg.Confirmations = confirmations
+		cfg.TerminalTitle = terminalTitle
+		cfg.BranchTypes = parseTypeList(branchTypes)
+		cfg.CommitTypes = parseTypeList(commitTypes)
+		cfg.BranchTemplate = template
 		if err := saveConfig(cfg); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
 		}
@@ -109,6 +259,53 @@ var configCmd = &cobra.Command{
 	},
 }
 
+// askBranchTemplate prompts for the branch name template, showing a branch
+// named with it until the user accepts. The default template is stored as "".
+func askBranchTemplate(current, abbrev string, branchTypes []string, customFields []string) (string, error) {
+	if current == "" {
+		current = convention.DefaultBranchTemplate
+	}
+	validator := func(val interface{}) error {
+		_, err := convention.ParseBranchTemplate(val.(string), customFields...)
+		return err
+	}
+	for {
+		if err := askOne(&survey.Input{
+			Message: "Branch name template:",
+			Default: current,
+			Help:    "A Go template over {{.Abbrev}}, {{.Type}}, {{.Desc}}, {{.Ticket}} and {{.Env}}; {{.Desc}} and {{.Ticket}} are required.",
+		}, &current, survey.WithValidator(validator)); err != nil {
+			return "", err
+		}
+		tmpl, err := convention.ParseBranchTemplate(current, customFields...)
+		if err != nil {
+			return "", err
+		}
+		preview, err := tmpl.Render(convention.Branch{
+			Abbreviation: abbrev,
+			Type:         branchTypes[0],
+			Description:  "user-details-window-width",
+			Ticket:       "CPRE-11347",
+		})
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		fmt.Printf("Branches will be named like: %s\n", preview)
+		accept := true
+		if err := askOne(&survey.Confirm{Message: "Use this format?", Default: true}, &accept); err != nil {
+			return "", err
+		}
+		if !accept {
+			continue
+		}
+		if current == convention.DefaultBranchTemplate {
+			return "", nil
+		}
+		return current, nil
+	}
+}
+
 // showConfigCmd represents the command to display the current configuration.
 var showConfigCmd = &cobra.Command{
 	Use:   "show-config",
@@ -128,6 +325,24 @@ var showConfigCmd = &cobra.Command{
 
 		fmt.Println("Current Configuration:")
 		fmt.Printf("  Two-letter Abbreviation: %s\n", cfg.Abbreviation)
+		fmt.Printf("  Confirmations: %s\n", cfg.confirmationLevel())
+		fmt.Printf("  Terminal title: %t\n", cfg.TerminalTitle)
+		fmt.Printf("  Branch types: %s\n", strings.Join(cfg.branchTypes(), ", "))
+		fmt.Printf("  Commit types: %s\n", strings.Join(cfg.commitTypes(), ", "))
+		fmt.Printf("  Products: %s\n", strings.Join(cfg.products(), ", "))
+		fmt.Printf("  Branch template: %s\n", branchTemplate)
+		if cfg.CommitTemplate.Subject != "" {
+			fmt.Printf("  Commit subject template: %s\n", cfg.CommitTemplate.Subject)
+		}
+		if cfg.CommitTemplate.Body != "" {
+			fmt.Printf("  Commit body template: %s\n", cfg.CommitTemplate.Body)
+		}
+		if len(cfg.TicketProjects) > 0 {
+			fmt.Printf("  Ticket projects: %s\n", strings.Join(cfg.TicketProjects, ", "))
+		}
+		if _, ok, _ := loadRepoConfig(); ok {
+			fmt.Printf("  Repository overrides: %s\n", repoConfigPath())
+		}
 		return nil
 	},
 }
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// configDefaults are the values used for the keys of the configuration that
+// are left unset, by dotted key as listed by config docs.
+var configDefaults = map[string]any{
+	"confirmations":              confirmAlways,
+	"prompt_backend":             promptBackendSurvey,
+	"hyperlinks":                 hyperlinksAuto,
+	"storage.backend":            storageFile,
+	"style.max_header_length":    convention.DefaultMaxHeaderLength,
+	"github.api_url":             defaultGitHubAPIURL,
+	"github.upload_url":          defaultGitHubUploadURL,
+	"gitlab.api_url":             defaultGitLabAPIURL,
+	"bitbucket.api_url":          defaultBitbucketCloudAPIURL,
+	"jira.ready_statuses":        defaultReadyStatuses,
+	"jira.subtask_type":          defaultJiraSubtaskType,
+	"branch_policy.expiry_days":  defaultBranchExpiryDays,
+	"branch_policy.spike_days":   defaultSpikeExpiryDays,
+	"limits.branch_description":  convention.DefaultMaxBranchDescription,
+	"limits.commit_description":  convention.DefaultMaxCommitDescription,
+	"timeouts.git":               defaultGitTimeout.String(),
+	"timeouts.api":               defaultAPITimeout.String(),
+	"reserved_branches":          defaultReservedBranches,
+	"branch_types":               convention.DefaultBranchTypes,
+	"commit_types":               convention.DefaultCommitTypes,
+	"products":                   convention.DefaultProducts,
+	"branch_template":            convention.DefaultBranchTemplate,
+	"commit_template.subject":    convention.DefaultCommitSubjectTemplate,
+	"commit_template.body":       convention.DefaultCommitBodyTemplate,
+	"amend.minutes":              defaultAmendMinutes,
+	"amend.max_lines":            defaultAmendMaxLines,
+	"notifications.events":       defaultEventChannels,
+	"notifications.max_per_hour": defaultMaxNotificationsPerHour,
+	"incident.issue_type":        defaultIncidentIssueType,
+	"push.after_branch":          pushAsk,
+	"push.after_commit":          pushNever,
+	"trunk.max_commits":          defaultTrunkMaxCommits,
+	"trunk.max_days":             defaultTrunkMaxDays,
+}
+
+// configKey is one setting of the configuration, found by walking the
+// configuration structs.
+type configKey struct {
+	// path is the key split at its dots, with "<name>" standing for the keys
+	// of a map and "[]" for the items of a list.
+	path []string
+	typ  reflect.Type
+	// env is the environment variable that takes precedence over the key.
+	env string
+}
+
+// name returns the dotted key, e.g. prompts.<name>.order.
+func (k configKey) name() string {
+	return strings.ReplaceAll(strings.Join(k.path, "."), ".[]", "[]")
+}
+
+// configKeys lists every key of the configuration in declaration order.
+// Structs are listed key by key, as are the structs in maps and lists.
+func configKeys(t reflect.Type, path []string) []configKey {
+	var keys []configKey
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), name)
+		typ := field.Type
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		switch {
+		case typ.Kind() == reflect.Struct && typ != reflect.TypeOf(time.Time{}):
+			keys = append(keys, configKeys(typ, fieldPath)...)
+		case typ.Kind() == reflect.Map && typ.Elem().Kind() == reflect.Struct:
+			keys = append(keys, configKeys(typ.Elem(), append(fieldPath, "<name>"))...)
+		case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Struct:
+			keys = append(keys, configKeys(typ.Elem(), append(fieldPath, "[]"))...)
+		default:
+			keys = append(keys, configKey{path: fieldPath, typ: typ, env: field.Tag.Get("env")})
+		}
+	}
+	return keys
+}
+
+// configTypeName describes the type of a key in the words of the JSON file.
+func configTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list of " + configTypeName(t.Elem())
+	case reflect.Map:
+		return "map of " + configTypeName(t.Elem())
+	}
+	return t.String()
+}
+
+// formatConfigDefault formats a default value as it would be written in the
+// JSON file, leaving strings unquoted.
+func formatConfigDefault(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// readRawConfig reads a configuration file as generic JSON, to tell which
+// keys it sets. A missing file sets nothing.
+func readRawConfig(path string) (any, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// rawConfigSets reports whether the generic JSON of a configuration file sets
+// the key at path, in any of the maps or lists along it.
+func rawConfigSets(raw any, path []string) bool {
+	if len(path) == 0 {
+		return raw != nil
+	}
+	switch v := raw.(type) {
+	case map[string]any:
+		if path[0] == "<name>" {
+			for _, item := range v {
+				if rawConfigSets(item, path[1:]) {
+					return true
+				}
+			}
+			return false
+		}
+		return rawConfigSets(v[path[0]], path[1:])
+	case []any:
+		if path[0] != "[]" {
+			return false
+		}
+		for _, item := range v {
+			if rawConfigSets(item, path[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configDocsColumns are the columns of config docs.
+var configDocsColumns = []tableColumn{
+	{name: "key"},
+	{name: "type"},
+	{name: "default", truncate: true},
+	{name: "layers"},
+	{name: "set_by"},
+}
+
+// configDocsCmd represents the command to document the configuration.
+var configDocsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Document every configuration key and where it is set",
+	Long: `List every key of the configuration with its type and default, the layers
+that can set it, and the layer that sets it here: "env" for an environment
+variable, "repo" for the repository's .git-helper.json, "global" for
+~/.git-helper-cli/config.json, or "default".
+
+The list is generated from the configuration code, so it always matches the
+running version. Keys inside maps are written as <name> and items of lists as
+[], e.g. prompts.<name>.order or custom_fields[].name. Use --format markdown
+to paste it into documentation.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := configFilePath()
+		if err != nil {
+			return err
+		}
+		global, err := readRawConfig(configPath)
+		if err != nil {
+			return err
+		}
+		repo, err := readRawConfig(repoConfigPath())
+		if err != nil {
+			return err
+		}
+		// The repository configuration can only set the keys of RepoConfig.
+		repoKeys := map[string]bool{}
+		for _, k := range configKeys(reflect.TypeOf(RepoConfig{}), nil) {
+			repoKeys[k.path[0]] = true
+		}
+
+		t := newTable(configDocsColumns...)
+		for _, k := range configKeys(reflect.TypeOf(Config{}), nil) {
+			layers := []string{"global"}
+			if repoKeys[k.path[0]] {
+				layers = append(layers, "repo")
+			}
+			if k.env != "" {
+				layers = append(layers, "env "+k.env)
+			}
+
+			setBy := "-"
+			def, hasDefault := configDefaults[k.name()]
+			if hasDefault {
+				setBy = "default"
+			}
+			switch {
+			case k.env != "" && os.Getenv(k.env) != "":
+				setBy = "env"
+			case repoKeys[k.path[0]] && rawConfigSets(repo, k.path):
+				setBy = "repo"
+			case rawConfigSets(global, k.path):
+				setBy = "global"
+			}
+
+			defText := ""
+			if hasDefault {
+				defText = formatConfigDefault(def)
+			}
+			t.add(k.name(), configTypeName(k.typ), defText, strings.Join(layers, ", "), setBy)
+		}
+		return t.render(cmd, "No configuration keys.")
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configDocsCmd)
+	addTableFlags(configDocsCmd, configDocsColumns, "")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// productPattern matches a valid product scope, as used in "fix(<product>): ...".
+var productPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// configProductsCmd groups the commands that manage the product scopes.
+var configProductsCmd = &cobra.Command{
+	Use:   "products",
+	Short: "List, add or remove the products offered by create-commit",
+	Long: `Manage the product scopes offered by create-commit, as in "fix(<product>): ...".
+Until products are configured, the defaults (lego, plec) are used.`,
+}
+
+// configProductsListCmd represents the command to list the configured products.
+var configProductsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the products offered by create-commit",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		for _, p := range cfg.products() {
+			fmt.Println(p)
+		}
+		return nil
+	},
+}
+
+// configProductsAddCmd represents the command to add products.
+var configProductsAddCmd = &cobra.Command{
+	Use:   "add <product>...",
+	Short: "Add products to the ones offered by create-commit",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadGlobalConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		// Start from the defaults so adding a product keeps them.
+		products := slices.Clone(cfg.products())
+		for _, p := range args {
+			p = strings.ToLower(p)
+			if !productPattern.MatchString(p) {
+				return fmt.Errorf("product '%s' must be lowercase letters, digits and hyphens", p)
+			}
+			if slices.Contains(products, p) {
+				fmt.Printf("Product '%s' is already configured.\n", p)
+				continue
+			}
+			products = append(products, p)
+		}
+
+		cfg.Products = products
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("Products: %s\n", strings.Join(products, ", "))
+		noteRepoProducts()
+		return nil
+	},
+}
+
+// configProductsRemoveCmd represents the command to remove products.
+var configProductsRemoveCmd = &cobra.Command{
+	Use:   "remove <product>...",
+	Short: "Remove products from the ones offered by create-commit",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadGlobalConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		products := slices.Clone(cfg.products())
+		for _, p := range args {
+			i := slices.Index(products, strings.ToLower(p))
+			if i < 0 {
+				return fmt.Errorf("product '%s' is not configured; configured products: %s", p, strings.Join(products, ", "))
+			}
+			products = slices.Delete(products, i, i+1)
+		}
+		// An empty list would silently bring back the defaults.
+		if len(products) == 0 {
+			return fmt.Errorf("at least one product must remain configured")
+		}
+
+		cfg.Products = products
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("Products: %s\n", strings.Join(products, ", "))
+		noteRepoProducts()
+		return nil
+	},
+}
+
+// noteRepoProducts points out that the current repository's configuration
+// sets the products, so changes to the personal list do not apply in it.
+func noteRepoProducts() {
+	if repoCfg, ok, _ := loadRepoConfig(); ok && len(repoCfg.Products) > 0 {
+		fmt.Printf("Note: %s sets the products used in this repository.\n", repoConfigPath())
+	}
+}
+
+func init() {
+	configCmd.AddCommand(configProductsCmd)
+	configProductsCmd.AddCommand(configProductsListCmd)
+	configProductsCmd.AddCommand(configProductsAddCmd)
+	configProductsCmd.AddCommand(configProductsRemoveCmd)
+}
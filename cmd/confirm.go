@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Supported values for the confirmations setting.
+const (
+	confirmAlways = "always"
+	confirmMajor  = "major"
+	confirmNever  = "never"
+)
+
+// confirmationLevels lists the confirmation settings in the order they are offered.
+var confirmationLevels = []string{confirmAlways, confirmMajor, confirmNever}
+
+// confirmationDescriptions explains each confirmation setting in the config prompt.
+var confirmationDescriptions = map[string]string{
+	confirmAlways: "confirm every action",
+	confirmMajor:  "confirm only pushes, merges and other hard-to-undo actions",
+	confirmNever:  "never ask",
+}
+
+// confirmationLevel returns the configured confirmation level, defaulting to "always".
+func (c Config) confirmationLevel() string {
+	switch c.Confirmations {
+	case confirmMajor, confirmNever:
+		return c.Confirmations
+	default:
+		return confirmAlways
+	}
+}
+
+// confirmAction asks the user to confirm an action unless the configured
+// confirmation level says the prompt can be skipped. Major actions are the
+// ones that are hard to undo or affect others, such as pushes and merges.
+func confirmAction(cfg Config, major bool, message string) (bool, error) {
+	switch cfg.confirmationLevel() {
+	case confirmNever:
+		return true, nil
+	case confirmMajor:
+		if !major {
+			return true, nil
+		}
+	}
+
+	confirm := false
+	if err := askOne(&survey.Confirm{
+		Message: message,
+	}, &confirm); err != nil {
+		return false, err
+	}
+	return confirm, nil
+}
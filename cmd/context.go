@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Default timeouts of git commands and API requests.
+const (
+	defaultGitTimeout = 10 * time.Minute
+	defaultAPITimeout = 30 * time.Second
+)
+
+// TimeoutsConfig overrides how long git commands and API requests may take,
+// as durations such as "90s" or "5m".
+type TimeoutsConfig struct {
+	Git string `json:"git,omitempty"`
+	API string `json:"api,omitempty"`
+}
+
+// Timeouts resolved from the config file.
+var (
+	gitTimeout = defaultGitTimeout
+	apiTimeout = defaultAPITimeout
+)
+
+// baseContext is the context of the running command. It is cancelled when
+// the user presses Ctrl+C, which stops any git command or API request in flight.
+var baseContext = context.Background()
+
+// configureTimeouts sets the git and API timeouts from the config.
+func configureTimeouts(cfg TimeoutsConfig) error {
+	for _, t := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"timeouts.git", cfg.Git, &gitTimeout},
+		{"timeouts.api", cfg.API, &apiTimeout},
+	} {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s '%s': expected a duration such as \"30s\" or \"5m\"", t.name, t.value)
+		}
+		*t.target = d
+	}
+	return nil
+}
+
+// withTimeout returns a context for one operation, cancelled on Ctrl+C or after timeout.
+func withTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(baseContext, timeout)
+}
+
+// gitCommand prepares a git command bound to the git timeout. The cancel
+// function must be called once the command has finished.
+func gitCommand(args ...string) (*exec.Cmd, context.CancelFunc) {
+	ctx, cancel := withTimeout(gitTimeout)
+	return exec.CommandContext(ctx, "git", args...), cancel
+}
+
+// gitRun runs a git command without output and reports whether it failed;
+// used for checks such as "git diff --quiet".
+func gitRun(args ...string) error {
+	call := shellCommand("git", args)
+	if e, ok := replayed(replayGit, call); ok {
+		return e.err()
+	}
+	c, cancel := gitCommand(args...)
+	defer cancel()
+	err := explainCancel(c.Run(), "git "+strings.Join(args, " "), gitTimeout)
+	recordCall(replayGit, call, "", err)
+	return err
+}
+
+// explainCancel replaces the error of an operation that was cancelled or timed out with one that says so.
+func explainCancel(err error, operation string, timeout time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(baseContext.Err(), context.Canceled):
+		return fmt.Errorf("%s: cancelled", operation)
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "signal: killed"):
+		return fmt.Errorf("%s: timed out after %s", operation, timeout)
+	}
+	return err
+}
+
+// serveUntilCancelled runs an HTTP server until the command is cancelled,
+// then shuts it down gracefully.
+func serveUntilCancelled(server *http.Server) error {
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+	select {
+	case err := <-errs:
+		return err
+	case <-baseContext.Done():
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		fmt.Println("Shutting down...")
+		return server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// typePattern matches a valid branch or commit type; branch names only allow
+// lowercase letters in the type.
+var typePattern = regexp.MustCompile(`^[a-z]+$`)
+
+// branchTypes returns the branch types offered when creating a branch.
+func (c Config) branchTypes() []string {
+	if len(c.BranchTypes) > 0 {
+		return c.BranchTypes
+	}
+	return convention.DefaultBranchTypes
+}
+
+// validBranchTypes returns the branch types existing branches may have: the
+// configured ones plus spike and hotfix.
+func (c Config) validBranchTypes() []string {
+	types := slices.Clone(c.branchTypes())
+	for _, t := range []string{convention.SpikeBranchType, convention.HotfixBranchType} {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// commitTypes returns the commit types offered when creating a commit.
+func (c Config) commitTypes() []string {
+	if len(c.CommitTypes) > 0 {
+		return c.CommitTypes
+	}
+	return convention.DefaultCommitTypes
+}
+
+// products returns the product scopes offered when creating a commit.
+func (c Config) products() []string {
+	if len(c.Products) > 0 {
+		return c.Products
+	}
+	return convention.DefaultProducts
+}
+
+// validateTicketID checks that a ticket ID looks like ABC-123 and, when
+// ticket projects are configured, belongs to one of them.
+func (c Config) validateTicketID(ticketID string) error {
+	if err := convention.ValidateTicketID(ticketID); err != nil {
+		return err
+	}
+	if len(c.TicketProjects) == 0 {
+		return nil
+	}
+	project, _, _ := strings.Cut(ticketID, "-")
+	for _, p := range c.TicketProjects {
+		if strings.EqualFold(p, project) {
+			return nil
+		}
+	}
+	return fmt.Errorf("ticket must belong to one of the projects: %s", strings.Join(c.TicketProjects, ", "))
+}
+
+// checkTicket validates a ticket ID and, with validate_tickets set, checks
+// that the ticket exists in JIRA and is not closed.
+func (c Config) checkTicket(ticketID string) error {
+	if err := c.validateTicketID(ticketID); err != nil {
+		return err
+	}
+	if !c.ValidateTickets {
+		return nil
+	}
+	return verifyTicket(c, ticketID)
+}
+
+// parseTypeList splits a comma-separated list of types, dropping empty entries.
+func parseTypeList(list string) []string {
+	var types []string
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// typeListValidator checks a comma-separated list of branch or commit types.
+func typeListValidator(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid input")
+	}
+	types := parseTypeList(str)
+	if len(types) == 0 {
+		return fmt.Errorf("enter at least one type")
+	}
+	for _, t := range types {
+		if !typePattern.MatchString(t) {
+			return fmt.Errorf("type '%s' must be lowercase letters only", t)
+		}
+	}
+	return nil
+}
+
+// conventionRules returns the convention rules in effect for this configuration.
+func (c Config) conventionRules() convention.Rules {
+	return convention.Rules{
+		BranchTypes:           c.validBranchTypes(),
+		CommitTypes:           c.commitTypes(),
+		Products:              c.products(),
+		MaxBranchDescription:  c.Limits.branchDescription(),
+		MaxCommitDescription:  c.Limits.commitDescription(),
+		Style:                 c.Style,
+		BranchTemplate:        c.BranchTemplate,
+		CommitSubjectTemplate: c.CommitTemplate.Subject,
+		CustomFields:          c.customFieldNames(),
+	}
+}
+
+// CommitTemplateConfig replaces the default commit message layout with Go
+// templates over .Type, .Product, .Desc, .Ticket, .Verb, .Branch, .Abbrev,
+// .Date, .Env and the custom fields as .Fields.<name>.
+type CommitTemplateConfig struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+}
+
+// The branch name and commit message formats in effect, resolved from the
+// config before any command runs.
+var (
+	branchTemplate = convention.MustParseBranchTemplate(convention.DefaultBranchTemplate)
+	commitTemplate = convention.MustParseMessageTemplate("", "")
+)
+
+// configureTemplates sets the branch name and commit message formats from the config.
+func configureTemplates(cfg Config) error {
+	if cfg.BranchTemplate != "" {
+		// Compiled through the rules, so validating against them reuses it.
+		tmpl, err := cfg.conventionRules().Template()
+		if err != nil {
+			return err
+		}
+		branchTemplate = tmpl
+	}
+	tmpl, err := convention.ParseMessageTemplate(cfg.CommitTemplate.Subject, cfg.CommitTemplate.Body, cfg.customFieldNames()...)
+	if err != nil {
+		return err
+	}
+	commitTemplate = tmpl
+	return nil
+}
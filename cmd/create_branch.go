@@ -3,16 +3,68 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
 	"github.com/spf13/cobra"
 )
 
-// Maximum length allowed for the short description (after replacing spaces with hyphens)
-const maxDescLength = 30
+// askBranchType prompts for one of the configured branch types.
+func askBranchType(cfg Config, branchType *string, back bool) error {
+	return askSelect("Choose branch type:", cfg.branchTypes(), branchType, back)
+}
+
+// askBranchDescription prompts for the short branch description and replaces
+// spaces with hyphens.
+func askBranchDescription(cfg Config, description *string, back bool) error {
+	maxLength := cfg.Limits.branchDescription()
+	validator := func(val interface{}) error {
+		str, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("invalid input")
+		}
+		_, err := convention.FormatDescription(str, maxLength)
+		return err
+	}
+	message := fmt.Sprintf("Enter a short branch description (spaces will be replaced with hyphens, %s):", lengthHint(*description, maxLength))
+	if err := askInput(message, description, back, validator); err != nil {
+		return err
+	}
+	*description = strings.ReplaceAll(*description, " ", "-")
+	return nil
+}
+
+// askTicketID prompts for the JIRA ticket ID.
+func askTicketID(cfg Config, ticketID *string, back bool) error {
+	validator := func(val interface{}) error {
+		str, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("invalid input")
+		}
+		return cfg.checkTicket(str)
+	}
+	// Complete from tickets seen locally, which works without JIRA access.
+	keys := recentTicketKeys()
+	suggest := func(toComplete string) []string { return completeTicketKey(keys, toComplete) }
+	return askInputSuggest("Enter the JIRA Ticket ID (e.g., CPRE-11347):", ticketID, back, validator, suggest)
+}
+
+// assembleBranchName builds the branch name from its parts using the
+// configured branch template.
+func assembleBranchName(cfg Config, branchType, description, ticketID, env string, fields map[string]string) (string, error) {
+	return branchTemplate.Render(convention.Branch{Abbreviation: cfg.Abbreviation, Type: branchType, Description: description, Ticket: ticketID, Env: givenEnvironment(env), Fields: fields})
+}
+
+// printToStdout sends everything else the command prints, including its
+// prompts, to stderr, and returns the original stdout for the --print result.
+func printToStdout() *os.File {
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	return out
+}
 
 // createBranchCmd represents the create-branch command.
 var createBranchCmd = &cobra.Command{
@@ -20,7 +72,56 @@ var createBranchCmd = &cobra.Command{
 	Short: "Create and switch to a new branch following company conventions",
 	Long: `Interactively create a new branch that follows the naming convention:
 <abbreviation>-<type>-<short_desc>/<JIRA_ticket_id>
-For example: lv-fix-user-details-window-width/CPRE-11347`,
+For example: lv-fix-user-details-window-width/CPRE-11347
+
+Teams using a different format can set branch_template in the config (or the
+repository's .git-helper.json) to a Go template over .Abbrev, .Type, .Desc and
+.Ticket, e.g. {{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}.
+
+With "environments" in the config, e.g. ["prod", "staging", "acme"], the
+affected environment is asked too (or given with --env) and available to the
+templates as .Env, e.g. {{.Abbrev}}-{{.Type}}-{{.Env}}-{{.Desc}}/{{.Ticket}}
+for incident-response branches.
+
+Use --from-stash to move your uncommitted changes (or a stash) onto the new
+branch, leaving the current branch clean.
+
+The branch starts from the current HEAD unless --ref names a commit, tag or
+branch to start from, or --pick-ref offers recent tags and remote branches.
+--base names a branch of origin instead, such as main or release/1.4: it is
+fetched first (unless --no-fetch), so the branch starts from up-to-date code.
+With "base_branches" in the config, e.g. ["main", "develop", "release/*"],
+the base is asked for.
+
+Use --type, --desc and --ticket to give the branch details up front; with all
+three the branch is created without any prompts, for use in scripts.
+
+Use --pick-ticket to choose the ticket from the open JIRA issues assigned to
+you instead of typing its ID, optionally narrowed with --sprint (see 'gh tickets').
+
+Use --spike for experimental work: the branch gets the spike type, e.g.
+lv-spike-try-redis-cache/CPRE-11347, and expires after --expires-in days (7
+unless "branch_policy.spike_days" is set), whatever its activity.
+'gh cleanup-branches' then proposes deleting it first.
+
+With JIRA configured (see "jira" in the config), the ticket is asked first and
+its summary is shown so you can confirm it is the right issue; the summary
+then pre-fills the description, which you can still edit.
+
+With uncommitted changes, you are asked whether to carry them to the new
+branch, stash them for the current branch (named after its ticket) or abort;
+--dirty carry, stash or abort answers up front.
+
+If the ticket already has a local or remote branch, you are offered to check
+it out instead (tracking the remote branch) rather than create a duplicate.
+
+Once created, you are offered to push the branch to origin and track it, so
+the remote branch exists right away. --push pushes without asking and
+--no-push skips it; set "push": {"after_branch": "always"} (or "never") in the
+config to change the default.
+
+Use --print to only write the branch name to stdout, without creating the
+branch, e.g. to pipe it into another tool; prompts are shown on stderr.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 1. Load user configuration.
 		cfg, err := loadConfig()
@@ -31,87 +132,387 @@ For example: lv-fix-user-details-window-width/CPRE-11347`,
 			return fmt.Errorf("no configuration found. Please run 'git-helper-cli config' to set your two-letter abbreviation")
 		}
 
-		// Variables to store the branch details.
-		branchType := ""
-		description := ""
-		ticketID := ""
-
-		// Function to prompt for branch type.
-		promptBranchType := func() error {
-			options := []string{"fix", "feat"}
-			prompt := &survey.Select{
-				Message: "Choose branch type:",
-				Options: options,
+		// With --print, git is left alone and only the name goes to stdout.
+		printOnly, err := cmd.Flags().GetBool("print")
+		if err != nil {
+			return err
+		}
+		out := os.Stdout
+		if printOnly {
+			for _, flag := range []string{"from-stash", "dirty", "ref", "pick-ref", "base", "push"} {
+				if cmd.Flags().Changed(flag) {
+					return fmt.Errorf("--print cannot be combined with --%s", flag)
+				}
 			}
-			return survey.AskOne(prompt, &branchType)
+			out = printToStdout()
 		}
 
-		// Function to prompt for branch description.
-		promptDescription := func() error {
-			prompt := &survey.Input{
-				Message: "Enter a short branch description (spaces will be replaced with hyphens):",
+		push, err := pushMode(cmd, cfg.Push.AfterBranch, pushAsk, "push.after_branch")
+		if err != nil {
+			return err
+		}
+
+		// With --from-stash, work out which changes to carry over before prompting.
+		fromStash, err := cmd.Flags().GetBool("from-stash")
+		if err != nil {
+			return err
+		}
+		dirty, err := cmd.Flags().GetString("dirty")
+		if err != nil {
+			return err
+		}
+		if dirty != "" {
+			if fromStash {
+				return fmt.Errorf("--dirty cannot be combined with --from-stash")
+			}
+			if err := convention.ValidateChoice("--dirty", dirty, dirtyActions); err != nil {
+				return err
+			}
+		}
+		stashRef := ""
+		if fromStash {
+			if stashRef, err = chooseStashSource(); err != nil {
+				return err
 			}
-			validator := func(val interface{}) error {
-				str, ok := val.(string)
-				if !ok {
-					return fmt.Errorf("invalid input")
+		}
+		// leaveWorkingTree deals with uncommitted changes right before the
+		// branch is created, reporting false when the user aborts.
+		leaveWorkingTree := func() (bool, error) {
+			if fromStash {
+				return true, nil
+			}
+			action, err := dirtyTreeAction(dirty)
+			if err != nil {
+				return false, err
+			}
+			switch action {
+			case dirtyCarry:
+				// Moving them through a stash keeps them safe if they conflict with the start point.
+				fromStash = true
+			case dirtyStash:
+				if err := stashWorkInProgress(); err != nil {
+					return false, err
 				}
-				formatted := strings.ReplaceAll(str, " ", "-")
-				if len(formatted) > maxDescLength {
-					return fmt.Errorf("description too long (max %d characters after formatting)", maxDescLength)
+			case dirtyAbort:
+				fmt.Println("Aborting branch creation.")
+				return false, nil
+			}
+			return true, nil
+		}
+
+		// Work out where the branch starts; "" means the current HEAD.
+		startPoint, err := cmd.Flags().GetString("ref")
+		if err != nil {
+			return err
+		}
+		pickRef, err := cmd.Flags().GetBool("pick-ref")
+		if err != nil {
+			return err
+		}
+		base, err := cmd.Flags().GetString("base")
+		if err != nil {
+			return err
+		}
+		if base != "" && (startPoint != "" || pickRef) {
+			return fmt.Errorf("--base cannot be combined with --ref or --pick-ref")
+		}
+		if startPoint != "" {
+			if err := verifyStartPoint(startPoint); err != nil {
+				return err
+			}
+		} else if pickRef {
+			if startPoint, err = chooseStartPoint(); err != nil {
+				return err
+			}
+		}
+		noFetch, err := cmd.Flags().GetBool("no-fetch")
+		if err != nil {
+			return err
+		}
+		// startFrom fetches the chosen base branch and starts from it; without
+		// one the start point above is used.
+		startFrom := func() (string, error) {
+			if base == "" || base == startPointHead {
+				return startPoint, nil
+			}
+			return fetchBaseBranch(base, !noFetch)
+		}
+
+		// Branch details given as flags are validated up front and not asked for.
+		branchType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+		description, err := cmd.Flags().GetString("desc")
+		if err != nil {
+			return err
+		}
+		ticketID, err := cmd.Flags().GetString("ticket")
+		if err != nil {
+			return err
+		}
+		// A spike always has the spike type and an expiry date.
+		spike, err := cmd.Flags().GetBool("spike")
+		if err != nil {
+			return err
+		}
+		meta := BranchMetadata{}
+		if spike {
+			if branchType != "" && branchType != convention.SpikeBranchType {
+				return fmt.Errorf("--spike cannot be combined with --type %s", branchType)
+			}
+			branchType = convention.SpikeBranchType
+			days := cfg.BranchPolicy.spikeDays()
+			if cmd.Flags().Changed("expires-in") {
+				if days, err = cmd.Flags().GetInt("expires-in"); err != nil {
+					return err
 				}
-				if len(formatted) == 0 {
-					return fmt.Errorf("description cannot be empty")
+				if days <= 0 {
+					return fmt.Errorf("--expires-in must be a positive number of days")
 				}
-				return nil
 			}
-			return survey.AskOne(prompt, &description, survey.WithValidator(validator))
+			meta.ExpiresAt = time.Now().AddDate(0, 0, days)
+		} else if branchType != "" {
+			if err := convention.ValidateChoice("branch type", branchType, cfg.branchTypes()); err != nil {
+				return err
+			}
+		}
+		if description != "" {
+			if description, err = convention.FormatDescription(description, cfg.Limits.branchDescription()); err != nil {
+				return err
+			}
+		}
+		if ticketID != "" {
+			if err := cfg.checkTicket(ticketID); err != nil {
+				return err
+			}
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			return err
+		}
+		if env != "" {
+			if err := cfg.checkEnvironment(env); err != nil {
+				return err
+			}
 		}
 
-		// Function to prompt for JIRA Ticket ID.
-		promptTicketID := func() error {
-			prompt := &survey.Input{
-				Message: "Enter the JIRA Ticket ID (e.g., CPRE-11347):",
+		// With --pick-ticket, the ticket comes from the user's assigned JIRA issues.
+		pickTicket, err := cmd.Flags().GetBool("pick-ticket")
+		if err != nil {
+			return err
+		}
+		if pickTicket && ticketID == "" {
+			sprint, err := cmd.Flags().GetString("sprint")
+			if err != nil {
+				return err
 			}
-			validator := func(val interface{}) error {
-				str, ok := val.(string)
-				if !ok {
-					return fmt.Errorf("invalid input")
-				}
-				matched, err := regexp.MatchString(`^[A-Za-z]+-\d+$`, str)
-				if err != nil {
-					return err
+			picked, err := pickAssignedTicket(cfg, sprint)
+			if err != nil {
+				return err
+			}
+			if picked != nil {
+				ticketID = picked.Ticket
+				if branchType == "" && slices.Contains(cfg.branchTypes(), branchTypeForIssue(picked.IssueType)) {
+					branchType = branchTypeForIssue(picked.IssueType)
 				}
-				if !matched {
-					return fmt.Errorf("ticket ID must be in format ABC-123")
+				if description == "" {
+					description = convention.Slugify(picked.Summary, cfg.Limits.branchDescription())
 				}
+			}
+		}
+
+		// Custom fields can be given with --field name=value.
+		session := newFlowSession("create-branch")
+		fieldSteps, fields, err := customSteps(session, cfg.CustomFields)
+		if err != nil {
+			return err
+		}
+		fieldFlags, err := cmd.Flags().GetStringToString("field")
+		if err != nil {
+			return err
+		}
+		if err := setFields(cfg.CustomFields, fields, fieldFlags); err != nil {
+			return err
+		}
+
+		// A hidden type question takes the first branch type, also for scripted runs.
+		fromFlags := branchType != "" || description != "" || ticketID != "" || env != "" || base != "" || len(fieldFlags) > 0
+		prompts := cfg.flowPrompts("create-branch")
+		if branchType == "" && slices.Contains(prompts.Hide, "type") {
+			branchType = cfg.branchTypes()[0]
+		}
+		// Likewise a hidden base question starts from the first base branch.
+		askBase := len(cfg.BaseBranches) > 0 && startPoint == "" && !pickRef && !printOnly
+		var baseOptions []string
+		baseFallback := startPointHead
+		if askBase {
+			if baseOptions, err = baseBranchOptions(cfg); err != nil {
+				return err
+			}
+			if len(baseOptions) > 0 {
+				baseFallback = baseOptions[0]
+			}
+			if base == "" && slices.Contains(prompts.Hide, "base") {
+				base = baseFallback
+			}
+		}
+
+		// With every detail given, create the branch without prompting.
+		if branchType != "" && description != "" && ticketID != "" && requiredFieldsGiven(cfg.CustomFields, fields) {
+			branchName, err := assembleBranchName(cfg, branchType, description, ticketID, env, answerMap(fields))
+			if err != nil {
+				return err
+			}
+			if err := checkReservedBranch(cfg, branchName); err != nil {
+				return err
+			}
+			if printOnly {
+				fmt.Fprintln(out, branchName)
 				return nil
 			}
-			return survey.AskOne(prompt, &ticketID, survey.WithValidator(validator))
+			if !fromStash {
+				if switched, err := offerTicketBranch(cfg, ticketID); err != nil || switched {
+					return err
+				}
+			}
+			if branchName, err = resolveCaseCollision(branchName, false); err != nil {
+				return err
+			}
+			fmt.Printf("Branch name: %s\n", branchName)
+			if proceed, err := leaveWorkingTree(); err != nil || !proceed {
+				return err
+			}
+			if startPoint, err = startFrom(); err != nil {
+				return err
+			}
+			meta.setDetails(ticketID, env, fields)
+			if err := createBranch(cfg, branchName, startPoint, fromStash, stashRef, meta); err != nil {
+				return err
+			}
+			return pushBranch(push, branchName)
 		}
 
-		// Initial prompts
-		if err := promptBranchType(); err != nil {
+		// Prompt helpers bound to the variables above.
+		promptBranchType := func(back bool) error { return askBranchType(cfg, &branchType, back) }
+		if spike {
+			promptBranchType = func(back bool) error { return nil }
+		}
+		promptDescription := func(back bool) error { return askBranchDescription(cfg, &description, back) }
+		promptTicketID := func(back bool) error { return askTicketID(cfg, &ticketID, back) }
+		jira := cfg.Jira.BaseURL != ""
+		if jira {
+			promptTicketID = func(back bool) error {
+				return askJiraTicket(cfg, &ticketID, &branchType, &description, back)
+			}
+		}
+
+		// Answers are saved as they are given, so an interrupted run can be resumed.
+		typeStep := flowStep{name: "type", value: &branchType, ask: session.step("type", &branchType, promptBranchType), fallback: cfg.branchTypes()[0]}
+		descriptionStep := flowStep{name: "description", value: &description, ask: session.step("description", &description, promptDescription)}
+		ticketStep := flowStep{name: "ticket", value: &ticketID, ask: session.step("ticket", &ticketID, promptTicketID)}
+		builtin := []flowStep{typeStep, descriptionStep, ticketStep}
+		// With JIRA configured the ticket comes first: its summary is shown for
+		// confirmation and pre-fills the type and description.
+		if jira {
+			builtin = []flowStep{ticketStep, typeStep, descriptionStep}
+		}
+		// The environment is asked after the details of the branch when configured.
+		envStep, askEnv := environmentStep(cfg, session, &env)
+		if askEnv {
+			builtin = append(builtin, envStep)
+		}
+		// With base_branches configured, the base is asked last unless the
+		// start point was given another way.
+		var baseStep *flowStep
+		if askBase {
+			promptBase := func(back bool) error { return askBaseBranch(baseOptions, &base, back) }
+			baseStep = &flowStep{name: "base", value: &base, ask: session.step("base", &base, promptBase), fallback: baseFallback}
+			builtin = append(builtin, *baseStep)
+		}
+		// The config can reorder the questions, hide the type and add its own;
+		// custom fields are asked after the built-in questions.
+		custom, answers, err := customSteps(session, prompts.Steps)
+		if err != nil {
 			return err
 		}
-		if err := promptDescription(); err != nil {
+		custom = append(fieldSteps, custom...)
+		answers = append(fields, answers...)
+		steps, err := prompts.arrange("create-branch", builtin, custom)
+		if err != nil {
 			return err
 		}
-		// Replace spaces with hyphens for consistency.
-		description = strings.ReplaceAll(description, " ", "-")
-		if err := promptTicketID(); err != nil {
+
+		// Offer tickets queued by `listen` as a starting point; otherwise offer
+		// to resume an interrupted run. Both are skipped when details were given as flags.
+		var missing []promptStep
+		if !fromFlags {
+			suggestion, err := pickSuggestion()
+			if err != nil {
+				return err
+			}
+			if suggestion != nil {
+				ticketID = suggestion.Ticket
+				description = convention.Slugify(suggestion.Summary, cfg.Limits.branchDescription())
+				if slices.Contains(cfg.branchTypes(), branchTypeForIssue(suggestion.IssueType)) {
+					branchType = branchTypeForIssue(suggestion.IssueType)
+				}
+			} else if err := session.resume(); err != nil {
+				return err
+			}
+			for _, s := range steps {
+				missing = append(missing, s.ask)
+			}
+		} else {
+			// Only ask for the details missing from the flags.
+			for _, s := range steps {
+				if *s.value == "" && !s.optional {
+					missing = append(missing, s.ask)
+				}
+			}
+		}
+
+		// Initial prompts; each question can go back to the previous one.
+		if err := runSteps(missing...); err != nil {
 			return err
 		}
+		if printOnly {
+			branchName, err := assembleBranchName(cfg, branchType, description, ticketID, env, answerMap(fields))
+			if err != nil {
+				return err
+			}
+			if err := checkReservedBranch(cfg, branchName); err != nil {
+				return err
+			}
+			session.clear()
+			fmt.Fprintln(out, branchName)
+			return nil
+		}
 
-		// A helper to assemble the branch name.
-		assembleBranchName := func() string {
-			return fmt.Sprintf("%s-%s-%s/%s", strings.ToLower(cfg.Abbreviation), branchType, strings.ToLower(description), ticketID)
+		// Offer the ticket's existing branch rather than a duplicate of it.
+		if !fromStash {
+			switched, err := offerTicketBranch(cfg, ticketID)
+			if err != nil {
+				return err
+			}
+			if switched {
+				session.clear()
+				return nil
+			}
 		}
 
 		// Loop to allow user to review and edit inputs.
 		for {
-			branchName := assembleBranchName()
+			branchName, err := assembleBranchName(cfg, branchType, description, ticketID, env, answerMap(fields))
+			if err != nil {
+				return err
+			}
 			fmt.Printf("\nProposed branch name: %s\n", branchName)
+			if startPoint != "" {
+				fmt.Printf("Starting from: %s\n", startPoint)
+			} else if base != "" && base != startPointHead {
+				fmt.Printf("Starting from: origin/%s\n", base)
+			}
 
 			// Offer options to either confirm or edit details.
 			menuOptions := []string{
@@ -119,64 +520,261 @@ For example: lv-fix-user-details-window-width/CPRE-11347`,
 				"Edit branch type",
 				"Edit description",
 				"Edit JIRA ticket ID",
-				"Cancel",
+			}
+			if askEnv {
+				menuOptions = append(menuOptions, "Edit environment")
+			}
+			if baseStep != nil && !slices.Contains(prompts.Hide, "base") {
+				menuOptions = append(menuOptions, "Edit base branch")
+			}
+			for _, s := range custom {
+				menuOptions = append(menuOptions, "Edit "+s.name)
+			}
+			menuOptions = append(menuOptions, "Cancel")
+			if spike || slices.Contains(prompts.Hide, "type") {
+				menuOptions = slices.Delete(menuOptions, 1, 2)
 			}
 			var choice string
 			menuPrompt := &survey.Select{
 				Message: "What would you like to do?",
 				Options: menuOptions,
 			}
-			if err := survey.AskOne(menuPrompt, &choice); err != nil {
+			if err := askOne(menuPrompt, &choice); err != nil {
 				return err
 			}
 
 			switch choice {
 			case "Confirm and create branch":
-				// Confirm and proceed to create the branch.
-				confirm := false
-				confirmPrompt := &survey.Confirm{
-					Message: fmt.Sprintf("Create branch '%s'?", branchName),
+				if err := checkReservedBranch(cfg, branchName); err != nil {
+					fmt.Printf("%v; please edit the branch details.\n", err)
+					continue
 				}
-				if err := survey.AskOne(confirmPrompt, &confirm); err != nil {
+				// Branches differing only in case break checkouts on case-insensitive filesystems.
+				branchName, err := resolveCaseCollision(branchName, true)
+				if err != nil {
+					return err
+				}
+				// Confirm and proceed to create the branch.
+				confirm, err := confirmAction(cfg, false, fmt.Sprintf("Create branch '%s'?", branchName))
+				if err != nil {
 					return err
 				}
 				if confirm {
-					// Execute the Git command: git checkout -b <branchName>
-					cmdGit := exec.Command("git", "checkout", "-b", branchName)
-					cmdGit.Stdout = os.Stdout
-					cmdGit.Stderr = os.Stderr
-
-					fmt.Printf("Executing: git checkout -b %s\n", branchName)
-					if err := cmdGit.Run(); err != nil {
-						return fmt.Errorf("failed to create branch: %w", err)
+					proceed, err := leaveWorkingTree()
+					if err != nil {
+						return err
 					}
-
-					fmt.Println("Branch created and switched successfully!")
-					return nil
+					if !proceed {
+						session.clear()
+						return nil
+					}
+					if startPoint, err = startFrom(); err != nil {
+						return err
+					}
+					meta.setDetails(ticketID, env, answers)
+					if err := createBranch(cfg, branchName, startPoint, fromStash, stashRef, meta); err != nil {
+						return err
+					}
+					session.clear()
+					return pushBranch(push, branchName)
 				}
 				// If not confirmed, continue the loop.
 			case "Edit branch type":
-				if err := promptBranchType(); err != nil {
+				if err := typeStep.ask(false); err != nil {
+					return err
+				}
+			case "Edit environment":
+				if err := envStep.ask(false); err != nil {
+					return err
+				}
+			case "Edit base branch":
+				if err := baseStep.ask(false); err != nil {
 					return err
 				}
 			case "Edit description":
-				if err := promptDescription(); err != nil {
+				if err := descriptionStep.ask(false); err != nil {
 					return err
 				}
-				description = strings.ReplaceAll(description, " ", "-")
 			case "Edit JIRA ticket ID":
-				if err := promptTicketID(); err != nil {
+				previous := ticketID
+				if err := ticketStep.ask(false); err != nil {
 					return err
 				}
+				// Keep the description in line with the new ticket.
+				if ticketID != previous {
+					if err := resuggestDescription(cfg, ticketID, &description); err != nil {
+						return err
+					}
+					if renamed, err := assembleBranchName(cfg, branchType, description, ticketID, env, answerMap(fields)); err == nil {
+						printBranchNameChange(branchName, renamed)
+					}
+				}
 			case "Cancel":
+				session.clear()
 				fmt.Println("Aborting branch creation.")
 				return nil
+			default:
+				// One of the custom steps.
+				for _, s := range custom {
+					if choice == "Edit "+s.name {
+						if err := s.ask(false); err != nil {
+							return err
+						}
+					}
+				}
 			}
 			// After editing, the loop will reassemble the branch name and present the menu again.
 		}
 	},
 }
 
+// lookupTicket returns the summary and issue type of a ticket from the queue
+// filled by `listen` or, when configured, from JIRA. ok is false when neither knows it.
+func lookupTicket(cfg Config, ticketID string) (ticket TicketSuggestion, ok bool) {
+	if md, err := loadMetadata(); err == nil {
+		for _, s := range md.Suggestions {
+			if strings.EqualFold(s.Ticket, ticketID) {
+				return s, true
+			}
+		}
+	}
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return ticket, false
+	}
+	issue, err := client.issue(ticketID)
+	if err != nil {
+		fmt.Printf("Warning: failed to look up %s: %v\n", ticketID, err)
+		return ticket, false
+	}
+	return TicketSuggestion{Ticket: issue.Key, Summary: issue.Fields.Summary, IssueType: issue.Fields.IssueType.Name}, true
+}
+
+// branchTypeForIssue returns the branch type for a JIRA issue type: fix for
+// bugs and feat for everything else.
+func branchTypeForIssue(issueType string) string {
+	if strings.EqualFold(issueType, "bug") {
+		return "fix"
+	}
+	return "feat"
+}
+
+// askJiraTicket prompts for the ticket ID and shows its JIRA summary, asking
+// again until the user confirms it is the right issue. The summary pre-fills
+// the branch type and description when they are not set yet.
+func askJiraTicket(cfg Config, ticketID, branchType, description *string, back bool) error {
+	for {
+		if err := askTicketID(cfg, ticketID, back); err != nil {
+			return err
+		}
+		ticket, ok := lookupTicket(cfg, *ticketID)
+		if !ok {
+			return nil
+		}
+		fmt.Printf("%s [%s] %s\n", linkTicket(ticket.Ticket), ticket.IssueType, ticket.Summary)
+		right := true
+		if err := askOne(&survey.Confirm{Message: "Is this the right ticket?", Default: true}, &right); err != nil {
+			return err
+		}
+		if !right {
+			continue
+		}
+		if *branchType == "" && slices.Contains(cfg.branchTypes(), branchTypeForIssue(ticket.IssueType)) {
+			*branchType = branchTypeForIssue(ticket.IssueType)
+		}
+		if *description == "" {
+			*description = convention.Slugify(ticket.Summary, cfg.Limits.branchDescription())
+		}
+		return nil
+	}
+}
+
+// resuggestDescription offers a description derived from the summary of a
+// newly entered ticket in place of the current one.
+func resuggestDescription(cfg Config, ticketID string, description *string) error {
+	ticket, ok := lookupTicket(cfg, ticketID)
+	if !ok {
+		return nil
+	}
+	suggested := convention.Slugify(ticket.Summary, cfg.Limits.branchDescription())
+	if suggested == "" || suggested == *description {
+		return nil
+	}
+	use := true
+	if err := askOne(&survey.Confirm{
+		Message: fmt.Sprintf("%s is \"%s\". Use the description '%s'?", ticketID, ticket.Summary, suggested),
+		Default: true,
+	}, &use); err != nil {
+		return err
+	}
+	if use {
+		*description = suggested
+	}
+	return nil
+}
+
+// printBranchNameChange shows how an edit changed the proposed branch name.
+func printBranchNameChange(before, after string) {
+	if before == after {
+		return
+	}
+	fmt.Printf("\nBranch name changes:\n  - %s\n  + %s\n", before, after)
+}
+
+// setDetails records the ticket, environment and custom field answers of a
+// new branch, however they were given.
+func (m *BranchMetadata) setDetails(ticketID, env string, answers []*customAnswer) {
+	m.Ticket = ticketID
+	m.Env = givenEnvironment(env)
+	m.Fields = answerMap(answers)
+}
+
+// createBranch creates and switches to the branch, starting from startPoint
+// (or the current HEAD) and carrying over stashRef when fromStash is set, then
+// records its metadata.
+func createBranch(cfg Config, branchName, startPoint string, fromStash bool, stashRef string, meta BranchMetadata) error {
+	if fromStash {
+		if err := createBranchFromStash(branchName, startPoint, stashRef); err != nil {
+			return err
+		}
+	} else if err := createBranchAt(branchName, startPoint); err != nil {
+		return err
+	}
+
+	// Metadata is a convenience, so failing to record it is not fatal.
+	if err := recordBranch(branchName, meta); err != nil {
+		fmt.Printf("Warning: failed to record branch metadata: %v\n", err)
+	}
+	if err := dropSuggestion(meta.Ticket); err != nil {
+		fmt.Printf("Warning: failed to update the ticket queue: %v\n", err)
+	}
+
+	enteredBranch(cfg, branchName)
+	reportDone("Branch created and switched successfully!", "Branch would be created and switched to.")
+	if !meta.ExpiresAt.IsZero() {
+		fmt.Printf("This spike expires on %s; 'gh cleanup-branches' will offer to delete it then.\n", formatDate(meta.ExpiresAt))
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(createBranchCmd)
+	createBranchCmd.Flags().Bool("from-stash", false, "Move uncommitted changes or a stash onto the new branch")
+	createBranchCmd.Flags().String("dirty", "", "What to do with uncommitted changes: carry, stash or abort (default ask)")
+	createBranchCmd.Flags().String("ref", "", "Commit, tag or branch to start the new branch from")
+	createBranchCmd.Flags().Bool("pick-ref", false, "Pick the start point from recent tags and remote branches")
+	createBranchCmd.Flags().String("base", "", "Branch of origin to start from, fetched first, e.g. main or release/1.4")
+	createBranchCmd.Flags().Bool("no-fetch", false, "With --base, start from the branch as last fetched")
+	createBranchCmd.Flags().String("type", "", "Branch type, one of the configured branch types (default fix or feat)")
+	createBranchCmd.Flags().String("desc", "", "Short branch description; spaces become hyphens")
+	createBranchCmd.Flags().String("ticket", "", "JIRA ticket ID, e.g. CPRE-11347")
+	createBranchCmd.RegisterFlagCompletionFunc("ticket", completeTicketFlag)
+	createBranchCmd.Flags().Bool("pick-ticket", false, "Pick the ticket from the open JIRA issues assigned to you")
+	createBranchCmd.Flags().String("sprint", "", "With --pick-ticket, only offer tickets in this sprint; \"current\" for the open sprints")
+	createBranchCmd.Flags().String("env", "", "Affected environment, one of the configured environments")
+	createBranchCmd.Flags().StringToString("field", nil, "Custom field value as name=value (repeatable), see custom_fields")
+	addPushFlags(createBranchCmd, "the new branch")
+	createBranchCmd.Flags().Bool("print", false, "Only print the branch name to stdout; do not create the branch")
+	createBranchCmd.Flags().Bool("spike", false, "Create an experimental spike branch that expires")
+	createBranchCmd.Flags().Int("expires-in", defaultSpikeExpiryDays, "With --spike, days until the branch expires")
 }
@@ -1,46 +1,173 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
 	"github.com/spf13/cobra"
 )
 
-// Maximum allowed length for the commit description.
-const maxCommitDescLength = 50
+// commitDescValidator checks a commit description's length and style for the
+// given commit type and product.
+func commitDescValidator(cfg Config, commitType, product string) func(val interface{}) error {
+	return func(val interface{}) error {
+		str, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("invalid input")
+		}
+		return cfg.conventionRules().CheckCommitDescription(convention.Header{Type: commitType, Product: product, Description: str})
+	}
+}
 
 // getCurrentBranch returns the current git branch name.
 func getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	out, err := cmd.Output()
+	branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return strings.TrimSpace(string(out)), nil
+	return branch, nil
 }
 
 // extractTicketFromBranch extracts the JIRA ticket from the current branch name.
-// It uses the last part after splitting by '/' as the ticket ID.
+// It parses the name with the branch template, falling back to the last part
+// after splitting by '/' for branches named some other way.
 func extractTicketFromBranch(branch string) (string, error) {
-	parts := strings.Split(branch, "/")
-	if len(parts) < 1 {
-		return "", fmt.Errorf("branch name does not contain a '/' separator")
+	if b, ok := branchTemplate.Parse(branch); ok {
+		return b.Ticket, nil
 	}
-	ticket := parts[len(parts)-1]
+	ticket := branch[strings.LastIndex(branch, "/")+1:]
 	// Validate ticket format (e.g., ABC-123 or CLI-34343)
-	matched, err := regexp.MatchString(`^[A-Za-z]+-\d+$`, ticket)
+	if !convention.TicketIDPattern.MatchString(ticket) {
+		return "", fmt.Errorf("extracted ticket ID %q does not match expected pattern", ticket)
+	}
+	return ticket, nil
+}
+
+// commitMessages assembles the messages of a convention commit: the subject
+// and body from the commit template ("<type>(<product>): <desc>" and
+// "<Verb> <ticket>" by default) and the trailers recording the ticket, product
+// and tool version, any extra trailers such as the answers to custom prompt
+// steps and, while pairing, a Co-authored-by trailer for the partner. Custom
+// fields missing from fields are empty in the templates.
+func commitMessages(cfg Config, commitType, product, desc, ticketID, env string, fields map[string]string, extra ...convention.Trailer) ([]string, error) {
+	// The branch only feeds the template, so a detached HEAD is not an error.
+	branch, _ := getCurrentBranch()
+	subject, body, err := commitTemplate.Render(convention.Message{
+		Type:    commitType,
+		Product: product,
+		Desc:    desc,
+		Ticket:  ticketID,
+		Verb:    convention.TicketVerb(commitType),
+		Branch:  branch,
+		Abbrev:  strings.ToLower(cfg.Abbreviation),
+		Date:    time.Now().Format(time.DateOnly),
+		Env:     env,
+		Fields:  templateFields(cfg, fields),
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if !matched {
-		return "", fmt.Errorf("extracted ticket ID '%s' does not match expected pattern", ticket)
+
+	trailers := convention.FormatTrailers(append(convention.CommitMetadata{
+		Ticket:        ticketID,
+		Product:       product,
+		HelperVersion: version,
+		Environment:   env,
+	}.Trailers(), extra...))
+
+	// Credit the partner of an active pairing session.
+	partner, err := activePair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pairing session: %w", err)
 	}
-	return ticket, nil
+	if partner != "" {
+		trailers += "\n" + coAuthorTrailer(partner)
+	}
+	messages := []string{subject}
+	if body != "" {
+		messages = append(messages, body)
+	}
+	return append(messages, trailers), nil
+}
+
+// Choices of the create-commit confirmation menu.
+const (
+	commitChoiceYes  = "Yes, create the commit"
+	commitChoiceDiff = "View the staged diff"
+	commitChoiceNo   = "No, abort"
+)
+
+// confirmCommit asks whether to create the commit, offering to view the
+// staged diff first as often as wanted. Like confirmAction, it does not ask
+// when the confirmation level skips minor actions.
+func confirmCommit(cfg Config) (bool, error) {
+	if cfg.confirmationLevel() != confirmAlways {
+		return true, nil
+	}
+	for {
+		var choice string
+		if err := askOne(&survey.Select{
+			Message: "Do you want to proceed with this commit?",
+			Options: []string{commitChoiceYes, commitChoiceDiff, commitChoiceNo},
+		}, &choice); err != nil {
+			return false, err
+		}
+		if choice != commitChoiceDiff {
+			return choice == commitChoiceYes, nil
+		}
+		if err := showStagedDiff(); err != nil {
+			fmt.Printf("Warning: failed to show the staged diff: %v\n", err)
+		}
+	}
+}
+
+// showStagedDiff shows the staged changes through git's pager, which is
+// $GIT_PAGER, core.pager or $PAGER; with PAGER=cat the diff is shown inline.
+func showStagedDiff() error {
+	// Reading the diff takes as long as the user likes, so no timeout applies.
+	c := exec.CommandContext(baseContext, "git", "--paginate", "diff", "--cached", "--stat", "--patch")
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// stageInteractively offers the files with unstaged changes and stages the
+// ones chosen, for create-commit runs that start with nothing staged.
+func stageInteractively() error {
+	files, err := unstagedFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no changes found. Please make some changes before committing")
+	}
+	options := make([]string, len(files))
+	for i, f := range files {
+		options[i] = f.Status + " " + f.Path
+	}
+	var picked []int
+	if err := askOne(&survey.MultiSelect{
+		Message:  "Nothing is staged. Choose the files to stage:",
+		Options:  options,
+		PageSize: 15,
+	}, &picked, survey.WithValidator(survey.MinItems(1))); err != nil {
+		return err
+	}
+	paths := make([]string, len(picked))
+	for i, p := range picked {
+		paths[i] = files[p].Path
+	}
+	if err := runGit(append([]string{"add"}, pathArgs(paths)...)...); err != nil {
+		return fmt.Errorf("failed to stage the chosen files: %w", err)
+	}
+	return nil
 }
 
 // createCommitCmd represents the command to interactively create a commit message.
@@ -51,115 +178,459 @@ var createCommitCmd = &cobra.Command{
   
 git commit -m "${type: fix | feat}(${product: lego | plec}): ${commit desc (short)}" -m "${type: Fixes | Closes} ${JIRA ticket id}"
 
-It prompts for commit type, product, and a short description, and extracts the JIRA ticket id from the current branch name.`,
+It prompts for commit type, product, and a short description, and extracts the JIRA ticket id from the current branch name.
+
+The subject and ticket line can be changed with commit_template in the config,
+e.g. {"subject": "[{{.Ticket}}] {{.Type}}: {{.Desc}}", "body": ""}.
+
+Use --type, --product and --message to give the details up front, and --yes to
+skip the confirmation; only missing details are prompted for, so the command
+can be driven from scripts and aliases.
+
+When the staged changes are small and your last commit is on the same ticket,
+recent and not yet pushed, you are offered to amend it instead; tune or turn
+this off with "amend" in the config, e.g. {"minutes": 30, "max_lines": 10}.
+
+Use --print to only write the commit message to stdout, without committing,
+e.g. for 'git commit -F -' or another tool; prompts are shown on stderr.
+
+To push the branch once committed, creating its upstream on origin if it has
+none, use --push or set "push": {"after_commit": "always"} in the config; set
+it to "ask" to be asked each time, and use --no-push to skip it once.
+
+Use --amend to fix the previous commit, e.g. a typo in its description or a
+forgotten file: its type, product and description are offered for editing
+through the same prompts, the staged changes (if any) are added, and its
+ticket and trailers are kept.
+
+Use --fixup to turn the staged changes, e.g. review feedback, into a
+"fixup!" commit for an earlier commit of the branch, picked from a list (or
+given as --fixup=<commit>). 'gh autosquash' later folds them all in.
+
+With "collaborators" in the config, e.g. [{"name": "Ada", "email":
+"ada@example.com"}], you can pick co-authors to credit with Co-authored-by
+trailers; --co-author adds one for a single commit.
+
+Use --sign to sign the commit (git commit -S), or set "signing": {"commits":
+true} in the config to sign every commit, with "format" (openpgp, ssh or
+x509) and "key" to use other than git's gpg.format and user.signingkey. A
+repository can require it with "require_signing" in its .git-helper.json.
+The key is checked before anything is asked, so a missing one is explained
+up front.
+
+Use --jira-comment, or set "jira": {"comment_links": true} in the config, to
+comment on the ticket with the new commit's SHA, the branch and its pull
+request, if one was opened.
+
+When nothing is staged, you can pick the modified and untracked files to stage
+from a list; --no-stage fails instead, as scripts may prefer.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// 0. Check if there are staged changes.
-		stagedCheck := exec.Command("git", "diff", "--cached", "--quiet")
-		if err := stagedCheck.Run(); err == nil {
+		// With --print, git is left alone and only the message goes to stdout.
+		printOnly, err := cmd.Flags().GetBool("print")
+		if err != nil {
+			return err
+		}
+		amend, err := cmd.Flags().GetBool("amend")
+		if err != nil {
+			return err
+		}
+		fixup, err := cmd.Flags().GetString("fixup")
+		if err != nil {
+			return err
+		}
+		// A fixup commit's message comes from the commit it fixes.
+		if fixup != "" {
+			for _, name := range []string{"amend", "print", "type", "product", "message", "env", "field", "co-author"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--fixup cannot be combined with --%s", name)
+				}
+			}
+		}
+		out := os.Stdout
+		if printOnly {
+			if cmd.Flags().Changed("push") {
+				return fmt.Errorf("--print cannot be combined with --push")
+			}
+			out = printToStdout()
+		}
+
+		// 0. Check if there are staged changes, and offer to stage some if not.
+		// Nothing is committed with --print, so nothing needs to be staged, and
+		// amending may only fix the message.
+		if !printOnly && !amend && gitRun("diff", "--cached", "--quiet") == nil {
 			// If no error, then nothing is staged.
-			return fmt.Errorf("no staged changes found. Please stage your changes before committing")
+			noStage, _ := cmd.Flags().GetBool("no-stage")
+			if noStage {
+				return fmt.Errorf("no staged changes found. Please stage your changes before committing")
+			}
+			if err := stageInteractively(); err != nil {
+				return err
+			}
 		}
 
-		// Variables to store commit details.
-		var commitType string
-		var product string
-		var commitDesc string
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		push, err := pushMode(cmd, cfg.Push.AfterCommit, pushNever, "push.after_commit")
+		if err != nil {
+			return err
+		}
+		// pushCommit pushes the branch once committed to. A detached HEAD has
+		// no branch to push.
+		pushCommit := func() error {
+			if push == pushNever {
+				return nil
+			}
+			branch, err := getCurrentBranch()
+			if err != nil || branch == "HEAD" {
+				fmt.Println("Warning: not on a branch, so nothing was pushed.")
+				return nil
+			}
+			return pushBranch(push, branch)
+		}
+		// A missing signing key is reported now rather than by the commit after all prompts.
+		sign := cfg.Signing.Commits
+		if cmd.Flags().Changed("sign") {
+			sign, _ = cmd.Flags().GetBool("sign")
+		}
+		if sign && !printOnly && !readOnly {
+			if err := checkSigningKey(cfg.Signing); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+		}
+		if fixup != "" {
+			target, err := fixupTarget(fixup)
+			if err != nil {
+				return err
+			}
+			if err := runGit(cfg.Signing.commitArgs(sign, "--fixup="+target)...); err != nil {
+				return fmt.Errorf("failed to create commit: %w", err)
+			}
+			fmt.Println("Fixup commit created; fold it in with 'gh autosquash'.")
+			return pushCommit()
+		}
 
-		// 1. Prompt for commit type.
-		commitTypeOptions := []string{"fix", "feat"}
-		if err := survey.AskOne(&survey.Select{
-			Message: "Select commit type:",
-			Options: commitTypeOptions,
-		}, &commitType); err != nil {
+		// Commit details given as flags are not asked for.
+		commitType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+		product, err := cmd.Flags().GetString("product")
+		if err != nil {
+			return err
+		}
+		commitDesc, err := cmd.Flags().GetString("message")
+		if err != nil {
+			return err
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
 			return err
 		}
+		// --yes is for running without questions, including whether to push.
+		if yes && push == pushAsk {
+			push = pushNever
+		}
+		if commitType != "" {
+			if err := convention.ValidateChoice("commit type", commitType, cfg.commitTypes()); err != nil {
+				return err
+			}
+		}
+		if product != "" {
+			if err := convention.ValidateChoice("product", product, cfg.products()); err != nil {
+				return err
+			}
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			return err
+		}
+		if env != "" {
+			if err := cfg.checkEnvironment(env); err != nil {
+				return err
+			}
+		}
+		fieldFlags, err := cmd.Flags().GetStringToString("field")
+		if err != nil {
+			return err
+		}
+		fromFlags := commitType != "" || product != "" || commitDesc != "" || env != "" || len(fieldFlags) > 0
+		coAuthorFlags, err := cmd.Flags().GetStringArray("co-author")
+		if err != nil {
+			return err
+		}
+		var coAuthors []string
+		for _, c := range coAuthorFlags {
+			coAuthor, err := resolveCoAuthor(cfg, c)
+			if err != nil {
+				return err
+			}
+			coAuthors = append(coAuthors, coAuthor)
+		}
+
+		// With --amend, the previous commit's details are the answers to edit.
+		var previous amendedCommit
+		var previousCoAuthors []string
+		if amend {
+			if previous, err = loadAmendedCommit(); err != nil {
+				return err
+			}
+			if previous.parsed {
+				commitType = cmp.Or(commitType, previous.header.Type)
+				product = cmp.Or(product, previous.header.Product)
+				commitDesc = cmp.Or(commitDesc, previous.header.Description)
+			}
+			product = cmp.Or(product, previous.meta.Product)
+			env = cmp.Or(env, previous.meta.Environment)
+			for _, t := range previous.trailers {
+				if strings.EqualFold(t.Key, coAuthorKey) {
+					previousCoAuthors = append(previousCoAuthors, t.Value)
+				}
+			}
+		} else {
+			showTrunkStatus(cfg)
+		}
+
+		// A small follow-up to the user's own recent commit can be folded into it instead.
+		if !fromFlags && !yes && !printOnly && !amend {
+			if branch, err := getCurrentBranch(); err == nil {
+				if ticketID, err := extractTicketFromBranch(branch); err == nil {
+					if c, ok := findAmendCandidate(cfg, ticketID); ok {
+						if amended, err := offerAmend(cfg, c, sign); err != nil || amended {
+							if err != nil {
+								return err
+							}
+							return pushCommit()
+						}
+					}
+				}
+			}
+		}
+
+		// 1. Prompt for commit type.
+		promptCommitType := func(back bool) error {
+			return askSelect("Select commit type:", cfg.commitTypes(), &commitType, back)
+		}
 
 		// 2. Prompt for product.
-		productOptions := []string{"lego", "plec"}
-		if err := survey.AskOne(&survey.Select{
-			Message: "Select product:",
-			Options: productOptions,
-		}, &product); err != nil {
-			return err
+		promptProduct := func(back bool) error {
+			return askSelect("Select product:", cfg.products(), &product, back)
 		}
 
 		// 3. Prompt for commit description.
-		if err := survey.AskOne(&survey.Input{
-			Message: "Enter a short commit description:",
-		}, &commitDesc, survey.WithValidator(func(val interface{}) error {
-			str, ok := val.(string)
-			if !ok {
-				return fmt.Errorf("invalid input")
+		promptCommitDesc := func(back bool) error {
+			message := fmt.Sprintf("Enter a short commit description (%s):", lengthHint(commitDesc, cfg.Limits.commitDescription()))
+			return askInput(message, &commitDesc, back, commitDescValidator(cfg, commitType, product))
+		}
+
+		// Answers are saved as they are given, so an interrupted run can be resumed.
+		session := newFlowSession("create-commit")
+		descriptionStep := session.step("description", &commitDesc, promptCommitDesc)
+		// The config can reorder the questions, hide the choices and add its own.
+		prompts := cfg.flowPrompts("create-commit")
+		custom, answers, err := customSteps(session, prompts.Steps)
+		if err != nil {
+			return err
+		}
+		// Custom fields known from the branch are not asked again.
+		fieldSteps, fields, err := customSteps(session, cfg.CustomFields)
+		if err != nil {
+			return err
+		}
+		known := map[string]bool{}
+		if branch, err := getCurrentBranch(); err == nil {
+			values := branchFieldValues(branch)
+			for _, f := range fields {
+				if value := values[f.Name]; value != "" {
+					f.Value = value
+					known[f.Name] = true
+				}
 			}
-			if len(str) == 0 {
-				return fmt.Errorf("commit description cannot be empty")
+			if env == "" {
+				if env = branchEnvironment(branch); env != "" {
+					known["environment"] = true
+				}
+			}
+		}
+		if err := setFields(cfg.CustomFields, fields, fieldFlags); err != nil {
+			return err
+		}
+		builtin := []flowStep{
+			{name: "type", value: &commitType, ask: session.step("type", &commitType, promptCommitType), fallback: cfg.commitTypes()[0]},
+			{name: "product", value: &product, ask: session.step("product", &product, promptProduct), fallback: cfg.products()[0]},
+			{name: "description", value: &commitDesc, ask: descriptionStep},
+		}
+		if envStep, ok := environmentStep(cfg, session, &env); ok {
+			builtin = append(builtin, envStep)
+		}
+		// Co-authors picked from the collaborators, one per line.
+		coAuthorAnswer := strings.Join(previousCoAuthors, "\n")
+		if coAuthorsStep, ok := coAuthorStep(cfg, session, &coAuthorAnswer, previousCoAuthors); ok {
+			builtin = append(builtin, coAuthorsStep)
+		}
+		steps, err := prompts.arrange("create-commit", builtin, append(fieldSteps, custom...))
+		if err != nil {
+			return err
+		}
+		var missing []promptStep
+		for _, s := range steps {
+			// Only ask for the details missing from the flags.
+			if !known[s.name] && (!fromFlags || *s.value == "" && !s.optional) {
+				missing = append(missing, s.ask)
 			}
-			if len(str) > maxCommitDescLength {
-				return fmt.Errorf("commit description too long (max %d characters)", maxCommitDescLength)
+		}
+		if !fromFlags && !amend {
+			if err := session.resume(); err != nil {
+				return err
 			}
-			return nil
-		})); err != nil {
+		}
+
+		// Each question can go back to the previous one.
+		if err := runSteps(missing...); err != nil {
 			return err
 		}
 
+		// A description given with --message is checked once the type and product are known.
+		descFlag := cmd.Flags().Changed("message")
+		if descFlag {
+			if err := commitDescValidator(cfg, commitType, product)(commitDesc); err != nil {
+				return fmt.Errorf("invalid --message: %w", err)
+			}
+		}
+
+		// Messages must be in English; a translation may need editing to fit the rules.
+		// Descriptions given as flags are used as-is.
+		translated := false
+		if !descFlag {
+			if translated, err = offerTranslation(cfg, &commitDesc); err != nil {
+				return err
+			}
+		}
+		if translated {
+			if err := commitDescValidator(cfg, commitType, product)(commitDesc); err != nil {
+				fmt.Printf("The translation needs editing: %v\n", err)
+				if err := descriptionStep(false); err != nil {
+					return err
+				}
+			}
+		}
+
 		// 4. Get current branch and extract ticket ID.
 		branch, err := getCurrentBranch()
 		if err != nil {
 			return err
 		}
-		ticketID, err := extractTicketFromBranch(branch)
-		if err != nil {
-			return fmt.Errorf("failed to extract JIRA ticket from branch '%s': %w", branch, err)
+		// An amended commit keeps its ticket, whatever the branch.
+		ticketID := previous.meta.Ticket
+		if ticketID == "" {
+			if ticketID, err = extractTicketFromBranch(branch); err != nil {
+				return fmt.Errorf("failed to extract JIRA ticket from branch '%s': %w", branch, err)
+			}
+		}
+		if cfg.ValidateTickets {
+			if err := verifyTicket(cfg, ticketID); err != nil {
+				return err
+			}
 		}
 
 		// 5. Assemble the commit messages.
-		// First message: "<type>(<product>): <commitDesc>"
-		// Second message: "<CapitalizedType> <ticketID>"
-		firstMsg := fmt.Sprintf("%s(%s): %s", commitType, product, commitDesc)
-		var verb string
-		switch commitType {
-		case "fix":
-			verb = "Fixes"
-		case "feat":
-			verb = "Closes"
-		default:
-			verb = strings.Title(commitType)
-		}
-		secondMsg := fmt.Sprintf("%s %s", verb, ticketID)
-
-		fmt.Println("\nThe following commit messages will be created:")
-		fmt.Printf("Message 1: %s\n", firstMsg)
-		fmt.Printf("Message 2: %s\n", secondMsg)
-
-		// 6. Ask for confirmation.
-		confirm := false
-		if err := survey.AskOne(&survey.Confirm{
-			Message: "Do you want to proceed with this commit?",
-		}, &confirm); err != nil {
+		var trailers []convention.Trailer
+		for _, a := range givenAnswers(answers) {
+			trailers = append(trailers, a.trailer())
+		}
+		trailers = append(trailers, coAuthorTrailers(append(strings.Split(coAuthorAnswer, "\n"), coAuthors...))...)
+		messages, err := commitMessages(cfg, commitType, product, commitDesc, ticketID, givenEnvironment(env), answerMap(fields), trailers...)
+		if err != nil {
 			return err
 		}
+		if amend {
+			// The co-authors were offered for editing like the other details.
+			messages = previous.keepTrailers(messages, coAuthorKey)
+		}
+
+		if printOnly {
+			session.clear()
+			fmt.Fprintln(out, strings.Join(messages, "\n\n"))
+			return nil
+		}
+
+		if amend {
+			fmt.Println("\nThe previous commit will be amended with the following commit messages:")
+		} else {
+			fmt.Println("\nThe following commit messages will be created:")
+		}
+		for i, msg := range messages {
+			// Align the lines of the trailer block under the first one.
+			fmt.Printf("Message %d: %s\n", i+1, strings.ReplaceAll(msg, "\n", "\n           "))
+		}
+
+		// 6. Ask for confirmation, unless --yes was given.
+		confirm := yes
 		if !confirm {
+			if confirm, err = confirmCommit(cfg); err != nil {
+				return err
+			}
+		}
+		if confirm && amend && previous.pushed {
+			if confirm, err = confirmAction(cfg, true, "The previous commit is already pushed; amending it needs a force push. Amend anyway?"); err != nil {
+				return err
+			}
+		}
+		if !confirm {
+			session.clear()
 			fmt.Println("Commit creation aborted.")
 			return nil
 		}
 
 		// 7. Execute the git commit command.
-		gitCmd := exec.Command("git", "commit", "-m", firstMsg, "-m", secondMsg)
-		gitCmd.Stdout = os.Stdout
-		gitCmd.Stderr = os.Stderr
-
-		fmt.Println("Executing git commit...")
-		if err := gitCmd.Run(); err != nil {
+		var commitArgs []string
+		if amend {
+			commitArgs = append(commitArgs, "--amend")
+		}
+		for _, msg := range messages {
+			commitArgs = append(commitArgs, "-m", msg)
+		}
+		if err := runGit(cfg.Signing.commitArgs(sign, commitArgs...)...); err != nil {
 			return fmt.Errorf("failed to create commit: %w", err)
 		}
 
-		fmt.Println("Commit created successfully!")
-		return nil
+		session.clear()
+		if amend {
+			reportDone("Commit amended successfully!", "Commit would be amended.")
+			if previous.pushed && push != pushNever {
+				fmt.Println("Not pushing the rewritten commit; push it with 'git push --force-with-lease' once you are sure.")
+				return nil
+			}
+		} else {
+			reportDone("Commit created successfully!", "Commit would be created.")
+			if jiraComment(cmd, cfg) {
+				commentLinks(cfg, ticketID, branch, branchPR(branch))
+			}
+			transitionTicket(cfg, ticketID, cfg.Jira.Transitions.forCommit(commitType))
+		}
+		return pushCommit()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(createCommitCmd)
+	createCommitCmd.Flags().String("type", "", "Commit type, one of the configured commit types (default fix or feat)")
+	createCommitCmd.Flags().String("product", "", "Product the commit belongs to, one of the configured products")
+	createCommitCmd.Flags().StringP("message", "m", "", "Short commit description")
+	createCommitCmd.Flags().BoolP("yes", "y", false, "Create the commit without asking for confirmation")
+	createCommitCmd.Flags().String("env", "", "Affected environment, one of the configured environments (default: the branch's)")
+	createCommitCmd.Flags().StringToString("field", nil, "Custom field value as name=value (repeatable), see custom_fields")
+	createCommitCmd.Flags().StringArray("co-author", nil, "Co-author as 'Name <email>', or the name or e-mail of a collaborator in the config (repeatable)")
+	createCommitCmd.Flags().Bool("amend", false, "Amend the previous commit, editing its type, product and description")
+	createCommitCmd.Flags().String("fixup", "", "Create a fixup! commit for a commit of the branch, picked from a list or given as --fixup=<commit>")
+	createCommitCmd.Flags().Lookup("fixup").NoOptDefVal = fixupPick
+	createCommitCmd.Flags().Bool("jira-comment", false, "Comment on the ticket with the commit and branch, see jira.comment_links (--jira-comment=false to not comment)")
+	createCommitCmd.Flags().Bool("sign", false, "Sign the commit with GPG, SSH or X.509, see signing in the config (--sign=false to not sign)")
+	addPushFlags(createCommitCmd, "the branch after committing")
+	createCommitCmd.Flags().Bool("print", false, "Only print the commit message to stdout; do not commit")
+	createCommitCmd.Flags().Bool("no-stage", false, "Fail when nothing is staged instead of offering files to stage")
 }
 
 // bleh
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// ticketLinePattern matches the "Fixes CPRE-11347" lines create-commit adds,
+// which the pull request body replaces with a single ticket link.
+var ticketLinePattern = regexp.MustCompile(`^(Fixes|Closes|Refs) [A-Z][A-Z0-9]*-\d+$`)
+
+// prCommit is a commit going into a pull request.
+type prCommit struct {
+	Subject string
+	Body    string
+}
+
+// branchCommits returns the commits on HEAD that are not on base, oldest first.
+func branchCommits(base string) ([]prCommit, error) {
+	out, err := gitOutput("log", "--no-merges", "--reverse", "--format=%s%x1f%b%x1e", base+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the commits since %s: %w", base, err)
+	}
+	var commits []prCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		subject, body, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, prCommit{Subject: subject, Body: strings.TrimSpace(body)})
+	}
+	return commits, nil
+}
+
+// prTitle returns the header of the most recent convention commit, or the
+// branch description when no commit follows the convention.
+func prTitle(branch string, commits []prCommit) string {
+	for i := len(commits) - 1; i >= 0; i-- {
+		if _, ok := convention.ParseHeader(commits[i].Subject); ok {
+			return commits[i].Subject
+		}
+	}
+	if b, ok := branchTemplate.Parse(branch); ok && b.Description != "" {
+		return strings.ReplaceAll(b.Description, "-", " ")
+	}
+	return branch
+}
+
+// prBody lists the commits with their messages, without trailers and ticket
+// lines, below a link to the ticket.
+func prBody(cfg Config, ticketID string, commits []prCommit) string {
+	var b strings.Builder
+	if ticketID != "" {
+		if url := ticketURL(cfg, ticketID); url != "" {
+			fmt.Fprintf(&b, "JIRA: [%s](%s)\n\n", ticketID, url)
+		} else {
+			fmt.Fprintf(&b, "Ticket: %s\n\n", ticketID)
+		}
+	}
+	b.WriteString("## Commits\n")
+	for _, c := range commits {
+		fmt.Fprintf(&b, "\n- %s\n", c.Subject)
+		paragraphs := strings.Split(c.Body, "\n\n")
+		if convention.ParseTrailers("subject\n\n"+c.Body) != nil {
+			paragraphs = paragraphs[:len(paragraphs)-1]
+		}
+		for _, p := range paragraphs {
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSpace(p), "\n") {
+				if line != "" && !ticketLinePattern.MatchString(line) {
+					lines = append(lines, "  "+line)
+				}
+			}
+			if len(lines) > 0 {
+				fmt.Fprintf(&b, "\n%s\n", strings.Join(lines, "\n"))
+			}
+		}
+	}
+	return b.String()
+}
+
+// createPRCmd represents the command to push the current branch and open a pull request.
+var createPRCmd = &cobra.Command{
+	Use:   "create-pr",
+	Short: "Push the current branch and open a pull request for it",
+	Long: `Push the current branch to origin and open a pull request on GitHub,
+Bitbucket Cloud or Bitbucket Server, or a merge request on GitLab.
+
+The title is the header of the most recent commit that follows the convention,
+e.g. "fix(lego): handle empty playlists", unless --title is given. The body
+links the branch's ticket in JIRA and lists the commits with their messages.
+
+The pull request targets the default branch of origin unless --base names
+another branch; --draft opens it as a draft. If the branch already has an open
+pull request, it is pushed and the existing pull request is shown.
+
+With --jira-comment, or "jira": {"comment_links": true} in the config, the
+ticket gets a comment with the new pull request's URL, the branch and the
+SHA of its latest commit.
+
+The provider and repository are detected from 'git remote get-url origin';
+set "provider" in the config file for self-hosted servers that cannot be
+recognised from their URL. Tokens come from the environment or the config file:
+
+  GitHub     GITHUB_TOKEN or "github.token"
+  GitLab     GITLAB_TOKEN or "gitlab.token" ("gitlab.api_url" for other hosts)
+  Bitbucket  BITBUCKET_TOKEN or "bitbucket.token", with "bitbucket.username"
+             for app passwords ("bitbucket.api_url" overrides the API URL)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		branch, err := getCurrentBranch()
+		if err != nil || branch == "HEAD" {
+			return fmt.Errorf("not on a branch; check out the branch to open a pull request for")
+		}
+		provider, err := originProvider(cfg)
+		if err != nil {
+			return err
+		}
+		prs, err := newPRProvider(cfg, provider)
+		if err != nil {
+			return err
+		}
+
+		base, _ := cmd.Flags().GetString("base")
+		if base == "" {
+			defaultBase, err := defaultBaseBranch()
+			if err != nil {
+				return err
+			}
+			base = strings.TrimPrefix(defaultBase, "origin/")
+		}
+		if base == branch {
+			return fmt.Errorf("'%s' is the base branch; create a branch for your changes first", branch)
+		}
+		commits, err := branchCommits("origin/" + base)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			return fmt.Errorf("no commits on '%s' that are not on %s", branch, base)
+		}
+
+		ticketID, _ := extractTicketFromBranch(branch)
+		title, _ := cmd.Flags().GetString("title")
+		if title == "" {
+			title = prTitle(branch, commits)
+		}
+		body := prBody(cfg, ticketID, commits)
+		draft, _ := cmd.Flags().GetBool("draft")
+
+		fmt.Printf("Title: %s\nBase:  %s\n\n%s\n", title, base, body)
+		confirm, err := confirmAction(cfg, true, fmt.Sprintf("Push '%s' and open a pull request into %s?", branch, base))
+		if err != nil || !confirm {
+			return err
+		}
+
+		if err := runGit("push", "-u", "origin", branch); err != nil {
+			return fmt.Errorf("failed to push '%s': %w", branch, err)
+		}
+		// Pushing updates an open pull request, so only open one if there is none.
+		link, err := prs.findOpen(branch)
+		if err != nil {
+			return err
+		}
+		created := link == nil
+		if created {
+			link, err = prs.create(prRequest{Branch: branch, Base: base, Title: title, Body: body, Draft: draft})
+			if err != nil {
+				return fmt.Errorf("failed to open the pull request: %w", err)
+			}
+			// Nothing was opened in read-only mode.
+			if link == nil || link.Number == 0 {
+				return nil
+			}
+		}
+
+		repo, err := repoKey()
+		if err == nil {
+			var md Metadata
+			if md, err = loadMetadata(); err == nil {
+				meta := md.Repos[repo][branch]
+				if meta.Ticket == "" {
+					meta.Ticket = ticketID
+				}
+				meta.PR = link
+				err = recordBranch(branch, meta)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Warning: failed to record branch metadata: %v\n", err)
+		}
+
+		if created {
+			fmt.Printf("Opened %s: %s\n", link, link.URL)
+			if jiraComment(cmd, cfg) {
+				commentLinks(cfg, ticketID, branch, link)
+			}
+			transitionTicket(cfg, ticketID, cfg.Jira.Transitions.PR)
+		} else {
+			fmt.Printf("The %s is already open: %s\n", link, link.URL)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(createPRCmd)
+	createPRCmd.Flags().String("base", "", "Branch to merge into (default: the default branch of origin)")
+	createPRCmd.Flags().String("title", "", "Pull request title (default: the latest convention commit header)")
+	createPRCmd.Flags().Bool("draft", false, "Open the pull request as a draft")
+	createPRCmd.Flags().Bool("jira-comment", false, "Comment on the ticket with the pull request, see jira.comment_links (--jira-comment=false to not comment)")
+}
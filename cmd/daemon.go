@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Queued ticket suggestions older than this are dropped by the daemon.
+const suggestionMaxAge = 14 * 24 * time.Hour
+
+// daemonTask is one job the daemon runs on every tick.
+type daemonTask struct {
+	name string
+	run  func(cfg Config) error
+}
+
+// daemonTasks are run in order on every tick. Integrations that keep caches
+// add their refresh tasks here.
+var daemonTasks = []daemonTask{
+	{name: "fetch workspace repositories", run: fetchWorkspaceRepos},
+	{name: "prune stale state", run: pruneStaleState},
+}
+
+// fetchWorkspaceRepos fetches every repository of the workspace.
+func fetchWorkspaceRepos(cfg Config) error {
+	var failed []string
+	for _, repo := range workspaceRepos(cfg) {
+		if err := gitRun("-C", repo, "fetch", "--prune", "--quiet"); err != nil {
+			failed = append(failed, filepath.Base(repo))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to fetch %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// pruneStaleState removes expired sessions, old ticket suggestions, and
+// metadata for repositories and branches that no longer exist. A repository
+// is only forgotten once its directory is gone, not when git fails on it,
+// e.g. on an unmounted drive. A branch is only forgotten once it is gone
+// locally and on origin and has no pull request, which history and stats
+// still report on.
+func pruneStaleState(cfg Config) error {
+	// Interrupted sessions past their resume window.
+	if dir, err := sessionDir(); err == nil {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > sessionMaxAge {
+				os.Remove(filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	md.Suggestions = slices.DeleteFunc(md.Suggestions, func(s TicketSuggestion) bool {
+		return time.Since(s.ReceivedAt) > suggestionMaxAge
+	})
+	for repo, branches := range md.Repos {
+		if _, err := os.Stat(repo); errors.Is(err, fs.ErrNotExist) {
+			delete(md.Repos, repo)
+			continue
+		}
+		if !isGitRepo(repo) {
+			continue
+		}
+		for branch, meta := range branches {
+			if meta.PR != nil {
+				continue
+			}
+			if refExists(repo, "refs/heads/"+branch) || refExists(repo, "refs/remotes/origin/"+branch) {
+				continue
+			}
+			delete(branches, branch)
+		}
+	}
+	return saveMetadata(md)
+}
+
+// refExists reports whether ref exists in repo.
+func refExists(repo, ref string) bool {
+	return gitRun("-C", repo, "rev-parse", "--verify", "--quiet", ref) == nil
+}
+
+// runDaemonTasks runs every daemon task once, logging failures.
+func runDaemonTasks(cfg Config) {
+	for _, task := range daemonTasks {
+		start := time.Now()
+		if err := task.run(cfg); err != nil {
+			fmt.Printf("%s %s: %v\n", start.Format(time.DateTime), task.name, err)
+			continue
+		}
+		fmt.Printf("%s %s: done in %s\n", start.Format(time.DateTime), task.name, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// systemdUnit is the user service installed on Linux.
+var systemdUnit = template.Must(template.New("systemd").Parse(`[Unit]
+Description=git-helper-cli background sync
+
+[Service]
+ExecStart={{.Executable}} daemon run --interval {{.Interval}}
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`))
+
+// launchdPlist is the launch agent installed on macOS.
+var launchdPlist = template.Must(template.New("launchd").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>{{.Label}}</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>{{.Executable}}</string>
+    <string>daemon</string>
+    <string>run</string>
+    <string>--interval</string>
+    <string>{{.Interval}}</string>
+  </array>
+  <key>RunAtLoad</key>
+  <true/>
+  <key>KeepAlive</key>
+  <true/>
+  <key>StandardOutPath</key>
+  <string>{{.LogPath}}</string>
+  <key>StandardErrorPath</key>
+  <string>{{.LogPath}}</string>
+</dict>
+</plist>
+`))
+
+// Names of the installed service.
+const (
+	systemdServiceName = "git-helper-daemon.service"
+	launchdLabel       = "com.amagi.git-helper.daemon"
+)
+
+// serviceFilePath returns where the service definition lives on this platform.
+func serviceFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return filepath.Join(home, ".config", "systemd", "user", systemdServiceName), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+	}
+	return "", fmt.Errorf("installing the daemon as a service is not supported on %s", runtime.GOOS)
+}
+
+// daemonCmd groups the background sync commands.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run or install the background sync daemon",
+	Long: `The daemon periodically fetches the repositories of your workspace, refreshes
+cached data, and prunes stale state, so interactive commands stay fast.
+
+Configure the workspace in the config file as a list of repositories or
+directories containing repositories, e.g. "workspace": ["~/code"].`,
+}
+
+// daemonRunCmd represents the command to run the daemon in the foreground.
+var daemonRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the daemon in the foreground",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+
+		for {
+			// Reload every tick so config changes apply without a restart.
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			runDaemonTasks(cfg)
+			if once {
+				return nil
+			}
+			select {
+			case <-time.After(interval):
+			case <-baseContext.Done():
+				return nil
+			}
+		}
+	},
+}
+
+// daemonInstallCmd represents the command to install the daemon as a user service.
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the daemon as a systemd (Linux) or launchd (macOS) user service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the executable: %w", err)
+		}
+		path, err := serviceFilePath()
+		if err != nil {
+			return err
+		}
+		configPath, err := configFilePath()
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		data := map[string]string{
+			"Executable": executable,
+			"Interval":   interval.String(),
+			"Label":      launchdLabel,
+			"LogPath":    filepath.Join(filepath.Dir(configPath), "daemon.log"),
+		}
+		tmpl := systemdUnit
+		if runtime.GOOS == "darwin" {
+			tmpl = launchdPlist
+		}
+		err = tmpl.Execute(file, data)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+
+		if runtime.GOOS == "darwin" {
+			err = runCommand("launchctl", "load", "-w", path)
+		} else {
+			if err = runCommand("systemctl", "--user", "daemon-reload"); err == nil {
+				err = runCommand("systemctl", "--user", "enable", "--now", systemdServiceName)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to start the service: %w", err)
+		}
+		fmt.Println("Daemon installed and started.")
+		return nil
+	},
+}
+
+// daemonUninstallCmd represents the command to remove the daemon user service.
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the daemon user service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := serviceFilePath()
+		if err != nil {
+			return err
+		}
+		if runtime.GOOS == "darwin" {
+			err = runCommand("launchctl", "unload", "-w", path)
+		} else {
+			err = runCommand("systemctl", "--user", "disable", "--now", systemdServiceName)
+		}
+		if err != nil {
+			fmt.Printf("Warning: failed to stop the service: %v\n", err)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fmt.Println("Daemon uninstalled.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonRunCmd)
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+	daemonCmd.PersistentFlags().Duration("interval", 15*time.Minute, "Time between sync runs")
+	daemonRunCmd.Flags().Bool("once", false, "Run the tasks once and exit")
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// deepLinkScheme is the URL scheme of links to helper actions.
+const deepLinkScheme = "git-helper"
+
+// shareableActions are the commands a deep link may run. They only create or
+// check out branches and read history, so following a link cannot lose work.
+var shareableActions = []string{
+	"adopt",
+	"create-branch",
+	"grep-ticket",
+	"pr checkout",
+	"what-changed",
+}
+
+// deepLink is a helper action with its arguments, in the repository Repo
+// ("host/path" of its origin remote) or wherever it is opened if Repo is empty.
+type deepLink struct {
+	Action string
+	Args   []string
+	Flags  url.Values
+	Repo   string
+}
+
+// String encodes the link, e.g.
+// git-helper://create-branch?repo=github.com/org/repo&ticket=CPRE-11347.
+func (l deepLink) String() string {
+	query := url.Values{}
+	for name, values := range l.Flags {
+		query[name] = values
+	}
+	if len(l.Args) > 0 {
+		query["arg"] = l.Args
+	}
+	if l.Repo != "" {
+		query.Set("repo", l.Repo)
+	}
+	host, path, _ := strings.Cut(l.Action, " ")
+	u := url.URL{Scheme: deepLinkScheme, Host: host, RawQuery: query.Encode()}
+	if path != "" {
+		u.Path = "/" + strings.ReplaceAll(path, " ", "/")
+	}
+	return u.String()
+}
+
+// commandLine returns the arguments that run the link's action. The link's
+// arguments follow "--", so none of them can pass for a flag such as --force.
+func (l deepLink) commandLine() []string {
+	args := strings.Fields(l.Action)
+	for _, name := range slices.Sorted(maps.Keys(l.Flags)) {
+		for _, v := range l.Flags[name] {
+			args = append(args, "--"+name+"="+v)
+		}
+	}
+	if len(l.Args) == 0 {
+		return args
+	}
+	return append(append(args, "--"), l.Args...)
+}
+
+// shareableCommand returns the command of a shareable action.
+func shareableCommand(action string) (*cobra.Command, error) {
+	if !slices.Contains(shareableActions, action) {
+		return nil, fmt.Errorf("'%s' cannot be shared as a link; use one of: %s", action, strings.Join(shareableActions, ", "))
+	}
+	c, _, err := rootCmd.Find(strings.Fields(action))
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseDeepLink decodes a git-helper:// link and checks that its action may
+// be run from a link and its flags belong to the action.
+func parseDeepLink(link string) (deepLink, error) {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != deepLinkScheme || u.Host == "" {
+		return deepLink{}, fmt.Errorf("'%s' is not a %s:// link", link, deepLinkScheme)
+	}
+	l := deepLink{Action: strings.TrimSpace(u.Host + " " + strings.ReplaceAll(strings.Trim(u.Path, "/"), "/", " "))}
+	c, err := shareableCommand(l.Action)
+	if err != nil {
+		return l, err
+	}
+	query := u.Query()
+	l.Repo = query.Get("repo")
+	l.Args = query["arg"]
+	delete(query, "repo")
+	delete(query, "arg")
+	for name := range query {
+		if c.Flags().Lookup(name) == nil {
+			return l, fmt.Errorf("unknown flag '%s' for '%s' in the link", name, l.Action)
+		}
+	}
+	l.Flags = query
+	return l, nil
+}
+
+// originRepo returns the "host/path" of the origin remote of the repository in dir.
+func originRepo(dir string) (string, error) {
+	remote, err := gitOutputIn(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	host, path, err := parseRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+	return host + "/" + path, nil
+}
+
+// findRepo returns the local clone of repo: the current repository if it is
+// one, or else a repository of the workspace.
+func findRepo(cfg Config, repo string) (string, error) {
+	if top, err := repoKey(); err == nil {
+		if r, err := originRepo(top); err == nil && r == repo {
+			return top, nil
+		}
+	}
+	for _, dir := range workspaceRepos(cfg) {
+		if r, err := originRepo(dir); err == nil && r == repo {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no clone of %s found here or in the workspace; clone it or add it to \"workspace\" in the config", repo)
+}
+
+// shareLinkCmd represents the command to create a deep link to a helper action.
+var shareLinkCmd = &cobra.Command{
+	Use:   "share-link <command> [args...]",
+	Short: "Create a link to a helper action that teammates can run in one command",
+	Long: `Encode a helper command and its arguments as a git-helper:// link that can
+be shared in Slack or JIRA, e.g.
+
+  gh share-link create-branch --ticket CPRE-11347 --type feat
+
+The link names the repository by its origin remote, so whoever opens it with
+'gh open-link <link>' runs the command in their own clone of it. Links can run
+adopt, create-branch, grep-ticket, pr checkout and what-changed,
+which only create or check out branches and read history.`,
+	Args:               cobra.MinimumNArgs(1),
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] == "-h" || args[0] == "--help" {
+			return cmd.Help()
+		}
+		// Subcommands such as "pr checkout" take two words.
+		action, rest := args[0], args[1:]
+		if len(rest) > 0 && slices.Contains(shareableActions, action+" "+rest[0]) {
+			action, rest = action+" "+rest[0], rest[1:]
+		}
+		c, err := shareableCommand(action)
+		if err != nil {
+			return err
+		}
+
+		l := deepLink{Action: action, Flags: url.Values{}}
+		for i := 0; i < len(rest); i++ {
+			arg := rest[i]
+			name, ok := strings.CutPrefix(arg, "--")
+			if !ok {
+				if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+					return fmt.Errorf("use the long form of '%s' in links", arg)
+				}
+				l.Args = append(l.Args, arg)
+				continue
+			}
+			name, value, hasValue := strings.Cut(name, "=")
+			flag := c.Flags().Lookup(name)
+			if flag == nil {
+				return fmt.Errorf("unknown flag '--%s' for '%s'", name, action)
+			}
+			if !hasValue {
+				if flag.Value.Type() == "bool" {
+					value = "true"
+				} else if i+1 < len(rest) {
+					i++
+					value = rest[i]
+				} else {
+					return fmt.Errorf("flag '--%s' needs a value", name)
+				}
+			}
+			l.Flags.Add(name, value)
+		}
+		if top, err := repoKey(); err == nil {
+			l.Repo, _ = originRepo(top)
+		}
+
+		fmt.Printf("Link:    %s\n", l)
+		fmt.Printf("Command: gh open-link '%s'\n", l)
+		return nil
+	},
+}
+
+// openLinkCmd represents the command to run the action of a deep link.
+var openLinkCmd = &cobra.Command{
+	Use:   "open-link <link>",
+	Short: "Run the helper action of a git-helper:// link",
+	Long: `Run the helper action a teammate shared with 'gh share-link'. The action runs
+in your clone of the link's repository: the current repository if it is that
+one, or else the clone in your workspace. The command is shown and confirmed
+before it runs.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		l, err := parseDeepLink(args[0])
+		if err != nil {
+			return err
+		}
+		dir := "."
+		if l.Repo != "" {
+			if dir, err = findRepo(cfg, l.Repo); err != nil {
+				return err
+			}
+		}
+
+		commandLine := l.commandLine()
+		if readOnly {
+			commandLine = append(commandLine, "--read-only")
+		}
+		fmt.Printf("Command: gh %s\n", strings.Join(commandLine, " "))
+		if l.Repo != "" {
+			fmt.Printf("In:      %s\n", dir)
+		}
+		confirm, err := confirmAction(cfg, true, "Run this command?")
+		if err != nil || !confirm {
+			return err
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the executable: %w", err)
+		}
+		c := exec.CommandContext(baseContext, exe, commandLine...)
+		c.Dir = dir
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("'gh %s' failed: %w", l.Action, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shareLinkCmd)
+	rootCmd.AddCommand(openLinkCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// confirmDeletion shows exactly what a bulk command is about to delete and
+// asks for confirmation. Deleting from a remote affects everyone, so it has
+// to be confirmed by typing the remote's name. force skips the questions,
+// for scripts, but the preview is still printed.
+func confirmDeletion(cfg Config, what string, items []string, remote string, force bool) (bool, error) {
+	where := ""
+	if remote != "" {
+		where = " from " + remote
+	}
+	fmt.Printf("The following %d %s will be deleted%s:\n", len(items), what, where)
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item)
+	}
+	if force {
+		return true, nil
+	}
+	if remote == "" {
+		return confirmAction(cfg, true, fmt.Sprintf("Delete %d %s?", len(items), what))
+	}
+
+	var typed string
+	if err := askOne(&survey.Input{
+		Message: fmt.Sprintf("Type '%s' to confirm deleting them from the remote:", remote),
+	}, &typed); err != nil {
+		return false, err
+	}
+	if typed != remote {
+		fmt.Println("Confirmation did not match; nothing was deleted.")
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// dependencyEcosystem describes the manifest and lockfiles of a package manager.
+type dependencyEcosystem struct {
+	name      string
+	manifests []string
+	lockfiles []string
+	// dependency matches one dependency line of a manifest, capturing its
+	// name and version. package.json is read as JSON instead.
+	dependency *regexp.Regexp
+}
+
+// dependencyEcosystems lists the package managers deps-commit understands.
+var dependencyEcosystems = []dependencyEcosystem{
+	{
+		name:       "go",
+		manifests:  []string{"go.mod"},
+		lockfiles:  []string{"go.sum"},
+		dependency: regexp.MustCompile(`^\s*(?:require\s+)?([\w.\-/]+\.[\w.\-/]+)\s+(v[\w.\-+]+)`),
+	},
+	{
+		name:      "npm",
+		manifests: []string{"package.json"},
+		lockfiles: []string{"package-lock.json", "yarn.lock", "pnpm-lock.yaml"},
+	},
+	{
+		name:       "pip",
+		manifests:  []string{"requirements.txt", "pyproject.toml"},
+		lockfiles:  []string{"poetry.lock", "Pipfile.lock"},
+		dependency: regexp.MustCompile(`^\s*"?([\w.\-]+)\s*(?:==|>=|~=)\s*([\w.\-+]+)`),
+	},
+	{
+		name:       "cargo",
+		manifests:  []string{"Cargo.toml"},
+		lockfiles:  []string{"Cargo.lock"},
+		dependency: regexp.MustCompile(`^\s*([\w\-]+)\s*=\s*(?:\{\s*version\s*=\s*)?"([~^=]*\d[\w.\-+]*)"`),
+	},
+	{
+		name:       "bundler",
+		manifests:  []string{"Gemfile"},
+		lockfiles:  []string{"Gemfile.lock"},
+		dependency: regexp.MustCompile(`^\s*gem\s+['"]([\w\-]+)['"]\s*,\s*['"][~><=\s]*([\d][\w.\-]*)['"]`),
+	},
+}
+
+// dependencyBump is one package whose version changed.
+type dependencyBump struct {
+	Name string
+	From string
+	To   string
+}
+
+func (b dependencyBump) String() string {
+	switch {
+	case b.From == "":
+		return fmt.Sprintf("add %s %s", b.Name, b.To)
+	case b.To == "":
+		return fmt.Sprintf("remove %s %s", b.Name, b.From)
+	default:
+		return fmt.Sprintf("bump %s from %s to %s", b.Name, b.From, b.To)
+	}
+}
+
+// dependencyChange groups the changed files and bumps of one ecosystem.
+type dependencyChange struct {
+	ecosystem dependencyEcosystem
+	files     []string
+	bumps     []dependencyBump
+}
+
+// stagedFiles returns the paths with staged changes.
+func stagedFiles() ([]string, error) {
+	out, err := gitOutput("diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// npmDependencyGroups are the objects of package.json that list dependencies.
+var npmDependencyGroups = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// tomlTablePattern matches a TOML table header, capturing its name.
+var tomlTablePattern = regexp.MustCompile(`^\s*\[+\s*([^\]]+?)\s*\]+\s*$`)
+
+// tomlDependencyArrayPattern matches the start of an array of dependencies,
+// e.g. `dependencies = [` in the [project] table of pyproject.toml.
+var tomlDependencyArrayPattern = regexp.MustCompile(`^\s*[\w-]*dependencies\s*=\s*\[`)
+
+// dependencies lists the dependencies declared in a manifest of the
+// ecosystem, in file order, with their versions. Only dependency sections
+// count, so e.g. the project's own version is never taken for one.
+func (eco dependencyEcosystem) dependencies(manifest, content string) ([]string, map[string]string) {
+	var names []string
+	versions := map[string]string{}
+	add := func(name, version string) {
+		if _, ok := versions[name]; !ok {
+			names = append(names, name)
+		}
+		versions[name] = version
+	}
+
+	if eco.name == "npm" {
+		var groups map[string]json.RawMessage
+		if json.Unmarshal([]byte(content), &groups) != nil {
+			return nil, versions
+		}
+		for _, group := range npmDependencyGroups {
+			var deps map[string]string
+			if json.Unmarshal(groups[group], &deps) != nil {
+				continue
+			}
+			for _, name := range slices.Sorted(maps.Keys(deps)) {
+				add(name, deps[name])
+			}
+		}
+		return names, versions
+	}
+
+	// go.mod has require blocks, and TOML manifests dependency tables and
+	// arrays; requirements.txt and Gemfile only list dependencies.
+	toml := path.Ext(manifest) == ".toml"
+	inSection := !toml && eco.name != "go"
+	inArray := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case eco.name == "go" && strings.HasPrefix(trimmed, "require ("):
+			inSection = true
+			continue
+		case eco.name == "go" && trimmed == ")":
+			inSection = false
+			continue
+		case toml && tomlTablePattern.MatchString(line):
+			inSection = strings.HasSuffix(tomlTablePattern.FindStringSubmatch(line)[1], "dependencies")
+			inArray = false
+			continue
+		case toml && tomlDependencyArrayPattern.MatchString(line):
+			inArray = !strings.Contains(line, "]")
+			line = line[strings.Index(line, "[")+1:]
+		case inArray && strings.HasPrefix(trimmed, "]"):
+			inArray = false
+			continue
+		}
+		if !inSection && !inArray && !(eco.name == "go" && strings.HasPrefix(trimmed, "require ")) {
+			continue
+		}
+		if m := eco.dependency.FindStringSubmatch(line); m != nil {
+			add(m[1], m[2])
+		}
+	}
+	return names, versions
+}
+
+// dependencyBumps compares the dependencies of a manifest before and after a change.
+func dependencyBumps(eco dependencyEcosystem, manifest, before, after string) []dependencyBump {
+	oldNames, oldVersions := eco.dependencies(manifest, before)
+	newNames, newVersions := eco.dependencies(manifest, after)
+	var bumps []dependencyBump
+	for _, name := range newNames {
+		if oldVersions[name] != newVersions[name] {
+			bumps = append(bumps, dependencyBump{Name: name, From: oldVersions[name], To: newVersions[name]})
+		}
+	}
+	for _, name := range oldNames {
+		if _, ok := newVersions[name]; !ok {
+			bumps = append(bumps, dependencyBump{Name: name, From: oldVersions[name]})
+		}
+	}
+	return bumps
+}
+
+// stagedDependencyChanges finds the staged manifests and lockfiles, grouped by
+// ecosystem, and the dependency bumps in the manifests. It also returns the
+// other staged files.
+func stagedDependencyChanges() ([]dependencyChange, []string, error) {
+	files, err := stagedFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	var changes []dependencyChange
+	others := slices.Clone(files)
+	for _, eco := range dependencyEcosystems {
+		change := dependencyChange{ecosystem: eco}
+		for _, file := range files {
+			base := path.Base(file)
+			switch {
+			case slices.Contains(eco.manifests, base):
+				// A new manifest has no previous version, and a deleted one no staged version.
+				before, _ := gitOutput("show", "HEAD:"+file)
+				after, _ := gitOutput("show", ":"+file)
+				change.bumps = append(change.bumps, dependencyBumps(eco, base, before, after)...)
+			case !slices.Contains(eco.lockfiles, base):
+				continue
+			}
+			change.files = append(change.files, file)
+			others = slices.DeleteFunc(others, func(f string) bool { return f == file })
+		}
+		if len(change.files) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, others, nil
+}
+
+// depsCommitMessages returns the header and body of a chore(deps) commit
+// enumerating the bumps, falling back to a count when a single bump does not
+// fit in the header.
+func depsCommitMessages(cfg Config, bumps []dependencyBump, scope string) []string {
+	if len(bumps) == 1 {
+		header := fmt.Sprintf("chore(%s): %s", scope, bumps[0])
+		if len(header) <= cfg.Style.MaxHeader() {
+			return []string{header}
+		}
+	}
+	header := fmt.Sprintf("chore(%s): update dependencies", scope)
+	if len(bumps) > 0 {
+		header = fmt.Sprintf("chore(%s): update %d dependencies", scope, len(bumps))
+	}
+	if len(bumps) == 0 {
+		return []string{header}
+	}
+	lines := make([]string, len(bumps))
+	for i, b := range bumps {
+		lines[i] = "- " + b.String()
+	}
+	return []string{header, strings.Join(lines, "\n")}
+}
+
+// depsCommitCmd represents the command to commit dependency updates.
+var depsCommitCmd = &cobra.Command{
+	Use:   "deps-commit",
+	Short: "Commit staged dependency updates with a generated chore(deps) message",
+	Long: `Detect the staged manifests and lockfiles (go.mod, package.json,
+requirements.txt, Cargo.toml, Gemfile and their lockfiles), list the packages
+whose versions changed, and commit them as:
+
+  chore(deps): bump <package> from <old> to <new>
+
+or, for several packages, "chore(deps): update N dependencies" with one line per
+package in the body. The JIRA ticket of the current branch is referenced if it
+has one. Only dependency sections count, so e.g. a change to the project's own
+version is not taken for a dependency bump. Other staged changes are refused
+rather than committed under chore(deps).
+
+With --grouped, each package manager gets its own commit, e.g. chore(deps-go)
+and chore(deps-npm). Grouped commits take the working-tree content of the
+manifests and lockfiles, and leave other staged changes staged.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		grouped, _ := cmd.Flags().GetBool("grouped")
+
+		changes, others, err := stagedDependencyChanges()
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return fmt.Errorf("no staged manifests or lockfiles found. Please stage your dependency updates first")
+		}
+		showTrunkStatus(cfg)
+
+		// Dependency bumps do not always have a ticket, so it is only referenced when the branch has one.
+		var trailer string
+		if branch, err := getCurrentBranch(); err == nil {
+			if ticketID, err := extractTicketFromBranch(branch); err == nil {
+				trailer = "Refs " + ticketID
+			}
+		}
+
+		// Each commit is a set of messages and, in grouped mode, the paths it is limited to.
+		type depsCommit struct {
+			messages []string
+			paths    []string
+		}
+		var commits []depsCommit
+		if grouped && len(changes) > 1 {
+			for _, c := range changes {
+				commits = append(commits, depsCommit{
+					messages: depsCommitMessages(cfg, c.bumps, "deps-"+c.ecosystem.name),
+					paths:    c.files,
+				})
+			}
+		} else {
+			// The commit takes everything staged, which must all be dependency updates.
+			if len(others) > 0 {
+				return fmt.Errorf("other changes are staged too (%s); unstage them with 'git restore --staged' or commit them first", strings.Join(others, ", "))
+			}
+			var bumps []dependencyBump
+			for _, c := range changes {
+				bumps = append(bumps, c.bumps...)
+			}
+			commits = append(commits, depsCommit{messages: depsCommitMessages(cfg, bumps, "deps")})
+		}
+
+		for i := range commits {
+			if trailer != "" {
+				commits[i].messages = append(commits[i].messages, trailer)
+			}
+			fmt.Printf("\nCommit %d:\n", i+1)
+			for _, msg := range commits[i].messages {
+				fmt.Println(msg)
+			}
+		}
+		fmt.Println()
+
+		confirm, err := confirmAction(cfg, false, "Do you want to proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Commit creation aborted.")
+			return nil
+		}
+
+		for _, c := range commits {
+			args := []string{"commit"}
+			for _, msg := range c.messages {
+				args = append(args, "-m", msg)
+			}
+			if len(c.paths) > 0 {
+				args = append(args, "--only", "--")
+				args = append(args, c.paths...)
+			}
+			if err := runGit(args...); err != nil {
+				return fmt.Errorf("failed to create commit: %w", err)
+			}
+		}
+		reportDone("Dependency update committed successfully!", "Dependency update would be committed.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(depsCommitCmd)
+	depsCommitCmd.Flags().Bool("grouped", false, "Create one commit per package manager")
+}
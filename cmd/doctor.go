@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Results of a doctor check.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// checkSymbols marks each result in the report.
+var checkSymbols = map[string]string{
+	checkOK:   "✓",
+	checkWarn: "!",
+	checkFail: "✗",
+}
+
+// doctorCheck is the outcome of one health check.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+// environmentChecks verifies git and the personal configuration.
+func environmentChecks() []doctorCheck {
+	var checks []doctorCheck
+	if out, err := gitOutput("version"); err != nil {
+		checks = append(checks, doctorCheck{"git", checkFail, err.Error()})
+	} else {
+		checks = append(checks, doctorCheck{"git", checkOK, out})
+	}
+
+	cfg, err := loadGlobalConfig()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{"config", checkFail, err.Error()})
+	case cfg.Abbreviation == "":
+		checks = append(checks, doctorCheck{"config", checkFail, "no abbreviation set; run 'gh config'"})
+	default:
+		path, _ := configFilePath()
+		checks = append(checks, doctorCheck{"config", checkOK, path})
+	}
+	if cfg.Jira.BaseURL == "" {
+		checks = append(checks, doctorCheck{"jira", checkWarn, "not configured; ticket summaries are not looked up"})
+	} else {
+		checks = append(checks, doctorCheck{"jira", checkOK, cfg.Jira.BaseURL})
+	}
+	if backend := cfg.Storage.Backend; backend != "" && backend != storageFile {
+		if _, err := store.load(); err != nil {
+			checks = append(checks, doctorCheck{"storage", checkFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{"storage", checkOK, backend})
+		}
+	}
+	return checks
+}
+
+// repoChecks verifies the checkout in the current directory.
+func repoChecks() []doctorCheck {
+	root, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return []doctorCheck{{"repository", checkFail, "not inside a git repository"}}
+	}
+	checks := []doctorCheck{{"repository", checkOK, root}}
+
+	// The repository configuration, with the templates it may set.
+	cfg, err := loadConfig()
+	_, ok, _ := loadRepoConfig()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{repoConfigFile, checkFail, err.Error()})
+	case !ok:
+		checks = append(checks, doctorCheck{repoConfigFile, checkOK, "none; personal settings apply"})
+	default:
+		if err := configureTemplates(cfg); err != nil {
+			checks = append(checks, doctorCheck{repoConfigFile, checkFail, err.Error()})
+		} else if err := validateReservedBranches(cfg.reservedBranchPatterns()); err != nil {
+			checks = append(checks, doctorCheck{repoConfigFile, checkFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{repoConfigFile, checkOK, "valid"})
+		}
+	}
+
+	hooksDir, hooksCheck := checkHooks(root)
+	checks = append(checks, hooksCheck, checkLFS(root, hooksDir))
+
+	if base, err := defaultBaseBranch(); err != nil {
+		checks = append(checks, doctorCheck{"base branch", checkWarn, err.Error()})
+	} else {
+		checks = append(checks, doctorCheck{"base branch", checkOK, base})
+	}
+	checks = append(checks, checkRemote())
+	if cfg.Signing.Commits {
+		if err := checkSigningKey(cfg.Signing); err != nil {
+			checks = append(checks, doctorCheck{"signing", checkFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{"signing", checkOK, "commits are signed"})
+		}
+	}
+
+	// Whether the current branch follows the convention.
+	branch, err := getCurrentBranch()
+	switch {
+	case err != nil || branch == "HEAD":
+		checks = append(checks, doctorCheck{"current branch", checkWarn, "detached HEAD"})
+	case convention.LongLivedBranchPattern.MatchString(branch):
+		checks = append(checks, doctorCheck{"current branch", checkOK, branch + " (long-lived)"})
+	default:
+		if err := cfg.conventionRules().ValidateBranch(branch); err != nil {
+			checks = append(checks, doctorCheck{"current branch", checkWarn, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{"current branch", checkOK, branch})
+		}
+	}
+	return checks
+}
+
+// checkHooks reports the active hooks, honouring core.hooksPath, and returns
+// the hooks directory.
+func checkHooks(root string) (string, doctorCheck) {
+	dir, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", doctorCheck{"hooks", checkFail, err.Error()}
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if custom, _ := gitOutput("config", "core.hooksPath"); custom != "" {
+			return dir, doctorCheck{"hooks", checkFail, fmt.Sprintf("core.hooksPath points to missing directory %s", dir)}
+		}
+		return dir, doctorCheck{"hooks", checkOK, "none installed"}
+	}
+	var active, stale []string
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() || strings.HasSuffix(e.Name(), ".sample") || info.Mode()&0o111 == 0 {
+			continue
+		}
+		active = append(active, e.Name())
+		// Hooks from install-hooks break silently when the binary they call moves.
+		if data, err := os.ReadFile(filepath.Join(dir, e.Name())); err == nil {
+			if exe, ours := helperHookBinary(string(data)); ours {
+				if _, err := os.Stat(exe); err != nil {
+					stale = append(stale, e.Name())
+				}
+			}
+		}
+	}
+	if len(active) == 0 {
+		return dir, doctorCheck{"hooks", checkOK, "none installed"}
+	}
+	if len(stale) > 0 {
+		return dir, doctorCheck{"hooks", checkWarn, fmt.Sprintf("%s call a git-helper binary that no longer exists; run 'gh install-hooks' again", strings.Join(stale, ", "))}
+	}
+	return dir, doctorCheck{"hooks", checkOK, fmt.Sprintf("%s (%s)", strings.Join(active, ", "), dir)}
+}
+
+// checkLFS reports whether a repository using Git LFS has git-lfs and its
+// hooks, which a custom core.hooksPath silently replaces.
+func checkLFS(root, hooksDir string) doctorCheck {
+	attrs, err := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	if err != nil || !strings.Contains(string(attrs), "filter=lfs") {
+		return doctorCheck{"lfs", checkOK, "not used"}
+	}
+	if _, err := exec.LookPath("git-lfs"); err != nil {
+		return doctorCheck{"lfs", checkFail, "the repository uses Git LFS but git-lfs is not installed"}
+	}
+	prePush, err := os.ReadFile(filepath.Join(hooksDir, "pre-push"))
+	if err != nil || !strings.Contains(string(prePush), "git lfs") {
+		return doctorCheck{"lfs", checkWarn, fmt.Sprintf("no LFS pre-push hook in %s; run 'git lfs install' or call it from your own hooks", hooksDir)}
+	}
+	return doctorCheck{"lfs", checkOK, "hooks installed"}
+}
+
+// checkRemote reports whether origin can be reached, without prompting for credentials.
+func checkRemote() doctorCheck {
+	url, err := gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return doctorCheck{"remote", checkWarn, "no origin remote"}
+	}
+	c, cancel := gitCommand("ls-remote", "--exit-code", "origin", "HEAD")
+	defer cancel()
+	c.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := c.CombinedOutput(); err != nil {
+		return doctorCheck{"remote", checkFail, fmt.Sprintf("%s is unreachable: %s", url, strings.TrimSpace(string(out)))}
+	}
+	return doctorCheck{"remote", checkOK, url}
+}
+
+// doctorCmd represents the command to check that the tool and a checkout are set up correctly.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that git, your configuration and (with --repo) the current checkout are healthy",
+	Long: `Check that git is available and your configuration is complete.
+
+With --repo, also check the current checkout: that its .git-helper.json is
+valid, which hooks are active, that Git LFS hooks are not lost to a custom
+core.hooksPath, that the base branch is known and origin is reachable, and that
+the current branch follows the naming convention.
+
+Warnings are marked with "!" and failures with "✗"; the command fails if any
+check does.`,
+	// Configuration problems are reported by the checks instead of stopping the command.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		baseContext = cmd.Context()
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := cmd.Flags().GetBool("repo")
+		if err != nil {
+			return err
+		}
+		checks := environmentChecks()
+		if repo {
+			checks = append(checks, repoChecks()...)
+		}
+
+		failed := 0
+		for _, c := range checks {
+			fmt.Printf("%s %-16s %s\n", checkSymbols[c.status], c.name, c.detail)
+			if c.status == checkFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().Bool("repo", false, "Also check the current repository checkout")
+	rootCmd.AddCommand(doctorCmd)
+}
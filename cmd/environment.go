@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// askEnvironment prompts for the environment a change affects, which may be
+// left out.
+func askEnvironment(cfg Config, env *string, back bool) error {
+	return askSelect("Select the affected environment:", append(slices.Clone(cfg.Environments), noChoice), env, back)
+}
+
+// checkEnvironment checks an environment given as a flag.
+func (c Config) checkEnvironment(env string) error {
+	if len(c.Environments) == 0 {
+		return fmt.Errorf("no environments are configured; add them as \"environments\" in the config")
+	}
+	return convention.ValidateChoice("environment", env, c.Environments)
+}
+
+// environmentStep returns the optional step asking for the environment, saved
+// in session as it is answered. ok is false when no environments are configured.
+func environmentStep(cfg Config, session *flowSession, env *string) (step flowStep, ok bool) {
+	if len(cfg.Environments) == 0 {
+		return flowStep{}, false
+	}
+	ask := func(back bool) error { return askEnvironment(cfg, env, back) }
+	return flowStep{name: "environment", value: env, ask: session.step("environment", env, ask), optional: true}, true
+}
+
+// givenEnvironment returns env, or "" if the user chose to leave it out.
+func givenEnvironment(env string) string {
+	if env == noChoice {
+		return ""
+	}
+	return env
+}
+
+// branchEnvironment returns the environment of a branch: the one in its name
+// or, failing that, the one recorded when it was created.
+func branchEnvironment(branch string) string {
+	if b, ok := branchTemplate.Parse(branch); ok && b.Env != "" {
+		return b.Env
+	}
+	repo, err := repoKey()
+	if err != nil {
+		return ""
+	}
+	md, err := loadMetadata()
+	if err != nil {
+		return ""
+	}
+	return md.Repos[repo][branch].Env
+}
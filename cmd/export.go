@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Levels and conditions of commitlint rules.
+const (
+	commitlintError  = 2
+	commitlintAlways = "always"
+	commitlintNever  = "never"
+)
+
+// commitlintConfig is a commitlint configuration, as in .commitlintrc.json.
+type commitlintConfig struct {
+	ParserPreset commitlintParserPreset `json:"parserPreset"`
+	Rules        map[string][]any       `json:"rules"`
+}
+
+// commitlintParserPreset tells commitlint how to split a message into the
+// parts its rules check.
+type commitlintParserPreset struct {
+	ParserOpts struct {
+		HeaderPattern        string   `json:"headerPattern"`
+		HeaderCorrespondence []string `json:"headerCorrespondence"`
+		IssuePrefixes        []string `json:"issuePrefixes"`
+	} `json:"parserOpts"`
+}
+
+// commitlintRules translates the convention rules of the config into a
+// commitlint configuration. The product is the scope and the description
+// the subject; the imperative mood rule has no commitlint counterpart.
+func commitlintRules(cfg Config) commitlintConfig {
+	rules := cfg.conventionRules()
+	var c commitlintConfig
+	c.ParserPreset.ParserOpts.HeaderPattern = convention.HeaderPattern.String()
+	c.ParserPreset.ParserOpts.HeaderCorrespondence = []string{"type", "scope", "subject"}
+	// The ticket line, e.g. "Fixes CPRE-123", is a reference to an issue.
+	c.ParserPreset.ParserOpts.IssuePrefixes = []string{`[A-Z][A-Z0-9]+-`}
+	if len(cfg.TicketProjects) > 0 {
+		c.ParserPreset.ParserOpts.IssuePrefixes = nil
+		for _, p := range cfg.TicketProjects {
+			c.ParserPreset.ParserOpts.IssuePrefixes = append(c.ParserPreset.ParserOpts.IssuePrefixes, regexp.QuoteMeta(strings.ToUpper(p))+"-")
+		}
+	}
+
+	c.Rules = map[string][]any{
+		"type-empty":         {commitlintError, commitlintNever},
+		"type-enum":          {commitlintError, commitlintAlways, rules.CommitTypes},
+		"scope-empty":        {commitlintError, commitlintNever},
+		"scope-enum":         {commitlintError, commitlintAlways, rules.Products},
+		"subject-empty":      {commitlintError, commitlintNever},
+		"subject-max-length": {commitlintError, commitlintAlways, rules.MaxCommitDescription},
+	}
+	if rules.Style.Enabled(convention.RuleHeaderMaxLength) {
+		c.Rules["header-max-length"] = []any{commitlintError, commitlintAlways, rules.Style.MaxHeader()}
+	}
+	if rules.Style.Enabled(convention.RuleSubjectCase) {
+		c.Rules["subject-case"] = []any{commitlintError, commitlintNever, []string{"sentence-case", "start-case", "pascal-case", "upper-case"}}
+	}
+	if rules.Style.Enabled(convention.RuleSubjectFullStop) {
+		c.Rules["subject-full-stop"] = []any{commitlintError, commitlintNever, "."}
+	}
+	if body := cfg.CommitTemplate.Body; body == "" || strings.Contains(body, ".Ticket") {
+		c.Rules["references-empty"] = []any{commitlintError, commitlintNever}
+	}
+	return c
+}
+
+// exportCmd groups the commands that export the convention for other tools.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the convention for other tools",
+}
+
+// exportCommitlintCmd represents the command to write a commitlint configuration.
+var exportCommitlintCmd = &cobra.Command{
+	Use:   "commitlint",
+	Short: "Export the commit convention as a commitlint configuration",
+	Long: `Translate the commit rules of your config file into a commitlint configuration,
+so JavaScript repositories that enforce commit messages with commitlint apply
+the same rules as this tool. Run it again whenever the rules change.
+
+The commit types and products become type-enum and scope-enum, the description
+limit subject-max-length, and the enabled style rules header-max-length,
+subject-case and subject-full-stop. The ticket line becomes references-empty,
+with the ticket projects as issue prefixes. commitlint has no rule for the
+imperative mood, so that rule is left to 'gh validate'.
+
+The configuration needs no shared config package; install @commitlint/cli and
+write it to .commitlintrc.json at the top of the repository.`,
+	Example: `  gh export commitlint -o .commitlintrc.json`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if subject := cfg.CommitTemplate.Subject; subject != "" && subject != convention.DefaultCommitSubjectTemplate {
+			// Printed to stderr to keep the configuration on stdout valid JSON.
+			fmt.Fprintf(os.Stderr, "Warning: commit_template.subject is customized, but commitlint will expect headers like 'type(product): description'.\n")
+		}
+
+		data, err := json.MarshalIndent(commitlintRules(cfg), "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if output == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write commitlint configuration: %w", err)
+		}
+		fmt.Printf("Wrote commitlint configuration to %s\n", output)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportCommitlintCmd)
+	exportCommitlintCmd.Flags().StringP("output", "o", "", "File to write the configuration to (default: stdout)")
+}
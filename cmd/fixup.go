@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// fixupPick is the value of a bare --fixup, which picks the commit from a list.
+const fixupPick = "pick"
+
+// branchBase returns where the current branch forked from the default branch.
+func branchBase() (string, error) {
+	base, err := defaultBaseBranch()
+	if err != nil {
+		return "", err
+	}
+	fork, err := gitOutput("merge-base", "HEAD", base)
+	if err != nil {
+		return "", fmt.Errorf("failed to find where the branch forked from '%s': %w", base, err)
+	}
+	return fork, nil
+}
+
+// fixupTarget resolves the commit a fixup commit is for: target if given, or
+// else one picked from the commits of the branch.
+func fixupTarget(target string) (string, error) {
+	if target != fixupPick {
+		hash, err := gitOutput("rev-parse", "--verify", "--quiet", target+"^{commit}")
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a commit", target)
+		}
+		return hash, nil
+	}
+	base, err := branchBase()
+	if err != nil {
+		return "", err
+	}
+	commits, err := commitHeaders(base + "..HEAD")
+	if err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("the branch has no commits of its own to fix up")
+	}
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("no terminal to pick a commit; pass it with --fixup=<commit>")
+	}
+	options := make([]string, len(commits))
+	for i, c := range commits {
+		options[i] = c[0] + " " + c[1]
+	}
+	var picked int
+	if err := askOne(&survey.Select{
+		Message:  "Which commit do the staged changes fix?",
+		Options:  options,
+		PageSize: 15,
+	}, &picked); err != nil {
+		return "", err
+	}
+	return commits[picked][0], nil
+}
+
+// autosquashCmd represents the command to fold fixup commits into the commits they fix.
+var autosquashCmd = &cobra.Command{
+	Use:   "autosquash",
+	Short: "Fold fixup! and squash! commits into the commits they fix",
+	Long: `Fold the fixup!, squash! and amend! commits of the current branch, such as
+those made with 'gh create-commit --fixup', into the commits they fix, using:
+
+  git rebase -i --autosquash <fork-point>
+
+without opening the todo list in an editor. The branch is rebased onto the
+commit it forked from (or --base), so it is not moved onto newer commits.
+Uncommitted changes are stashed and restored around the rebase. If the branch
+has been pushed, the remote branch can be updated afterwards with
+git push --force-with-lease.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		base, err := cmd.Flags().GetString("base")
+		if err != nil {
+			return err
+		}
+		if base == "" {
+			if base, err = branchBase(); err != nil {
+				return err
+			}
+		}
+
+		// 1. Preview the commits that will be folded.
+		commits, err := commitHeaders(base + "..HEAD")
+		if err != nil {
+			return err
+		}
+		var fixups []string
+		for _, c := range commits {
+			for _, prefix := range []string{"fixup! ", "squash! ", "amend! "} {
+				if strings.HasPrefix(c[1], prefix) {
+					fixups = append(fixups, c[0]+" "+c[1])
+					break
+				}
+			}
+		}
+		if len(fixups) == 0 {
+			fmt.Println("No fixup!, squash! or amend! commits to fold.")
+			return nil
+		}
+		fmt.Printf("\nThe following commits will be folded into the commits they fix:\n%s\n\n", strings.Join(fixups, "\n"))
+		confirm, err := confirmAction(cfg, true, "Rebase the branch to fold them?")
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Autosquash aborted.")
+			return nil
+		}
+
+		// 2. Rebase, accepting the todo list as git arranged it.
+		if err := runGit("-c", "sequence.editor=:", "rebase", "-i", "--autosquash", "--autostash", base); err != nil {
+			return fmt.Errorf("rebase stopped; resolve the conflicts and run 'git rebase --continue' (or 'git rebase --abort'): %w", err)
+		}
+		fmt.Printf("Folded %d commit(s).\n", len(fixups))
+
+		// 3. Update the remote branch, since its history no longer matches.
+		upstream := upstreamBranch()
+		if upstream == "" {
+			return nil
+		}
+		push, err := confirmAction(cfg, true, fmt.Sprintf("Update '%s' with git push --force-with-lease?", upstream))
+		if err != nil {
+			return err
+		}
+		if push {
+			if err := runGit("push", "--force-with-lease"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(autosquashCmd)
+	autosquashCmd.Flags().String("base", "", "Commit to rebase onto (default: where the branch forked from the default branch)")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// looseHeaderPattern matches headers that are close to the convention, e.g.
+// "fix: thing", "Feat(lego) : thing" or "fix(lego)!: thing".
+var looseHeaderPattern = regexp.MustCompile(`^(\w+)\s*(?:\(([\w-]+)\))?!?\s*:\s*(.+)$`)
+
+// ticketTrailerPattern matches ticket lines such as "Fixes CPRE-11347".
+var ticketTrailerPattern = regexp.MustCompile(`^(?i:fixes|closes|refs|ticket):?\s+([A-Za-z]+-\d+)\s*$`)
+
+// leadingTicketPattern matches a ticket at the start of a description, e.g. "CPRE-1: ".
+var leadingTicketPattern = regexp.MustCompile(`^\[?[A-Za-z]+-\d+\]?[:\s-]*`)
+
+// draftMessage is what could be recovered from a free-form commit message.
+type draftMessage struct {
+	Type        string
+	Product     string
+	Description string
+	Ticket      string
+	Body        []string
+}
+
+// parseDraft extracts as much of the convention as possible from a drafted
+// commit message. Comment lines and the diff of 'git commit -v' are dropped.
+func parseDraft(cfg Config, text string) draftMessage {
+	var d draftMessage
+	var lines []string
+	for _, line := range strings.Split(stripCommitComments(text), "\n") {
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return d
+	}
+
+	// The header.
+	header := lines[0]
+	if m := looseHeaderPattern.FindStringSubmatch(header); m != nil && slices.Contains(cfg.commitTypes(), strings.ToLower(m[1])) {
+		d.Type = strings.ToLower(m[1])
+		if slices.Contains(cfg.products(), strings.ToLower(m[2])) {
+			d.Product = strings.ToLower(m[2])
+		}
+		header = m[3]
+	}
+	if ticket := leadingTicketPattern.FindString(header); ticket != "" {
+		d.Ticket = convention.TicketRefPattern.FindString(strings.ToUpper(ticket))
+		header = header[len(ticket):]
+	}
+	d.Description = strings.TrimSpace(header)
+
+	// The body, without ticket trailers which are added back in the standard form.
+	for _, line := range lines[1:] {
+		if m := ticketTrailerPattern.FindStringSubmatch(line); m != nil {
+			d.Ticket = strings.ToUpper(m[1])
+			continue
+		}
+		d.Body = append(d.Body, line)
+	}
+	for len(d.Body) > 0 && d.Body[0] == "" {
+		d.Body = d.Body[1:]
+	}
+	for len(d.Body) > 0 && d.Body[len(d.Body)-1] == "" {
+		d.Body = d.Body[:len(d.Body)-1]
+	}
+	if d.Ticket == "" {
+		d.Ticket = convention.TicketRefPattern.FindString(strings.Join(d.Body, "\n"))
+	}
+	return d
+}
+
+// String renders the draft in the commit convention.
+func (d draftMessage) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s(%s): %s\n", d.Type, d.Product, d.Description)
+	if len(d.Body) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(d.Body, "\n"))
+	}
+	fmt.Fprintf(&b, "\n%s %s\n", convention.TicketVerb(d.Type), d.Ticket)
+	return b.String()
+}
+
+// formatCommitMsgCmd represents the command to rewrite a drafted commit message file.
+var formatCommitMsgCmd = &cobra.Command{
+	Use:   "format-commit-msg <file>",
+	Short: "Rewrite a drafted commit message file to follow the convention",
+	Long: `Read a commit message written in your editor (e.g. .git/COMMIT_EDITMSG), work
+out the type, product, description and JIRA ticket where possible, ask for
+whatever is missing or invalid, and rewrite the file in the convention:
+
+  <type>(<product>): <description>
+
+  <body>
+
+  <Fixes|Closes|Refs> <TICKET>`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		path := args[0]
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		draft := parseDraft(cfg, string(content))
+
+		// The ticket falls back to the one in the branch name.
+		if draft.Ticket == "" {
+			if branch, err := getCurrentBranch(); err == nil {
+				draft.Ticket, _ = extractTicketFromBranch(branch)
+			}
+		}
+
+		// Ask only for what is missing or invalid.
+		var steps []promptStep
+		if draft.Type == "" {
+			steps = append(steps, func(back bool) error {
+				return askSelect("Select commit type:", cfg.commitTypes(), &draft.Type, back)
+			})
+		}
+		if draft.Product == "" {
+			steps = append(steps, func(back bool) error {
+				return askSelect("Select product:", cfg.products(), &draft.Product, back)
+			})
+		}
+		steps = append(steps, func(back bool) error {
+			validate := commitDescValidator(cfg, draft.Type, draft.Product)
+			if validate(draft.Description) == nil {
+				return nil
+			}
+			message := fmt.Sprintf("Enter a short commit description (%s):", lengthHint(draft.Description, cfg.Limits.commitDescription()))
+			return askInput(message, &draft.Description, back, validate)
+		})
+		if draft.Ticket == "" {
+			steps = append(steps, func(back bool) error {
+				return askTicketID(cfg, &draft.Ticket, back)
+			})
+		}
+		if err := runSteps(steps...); err != nil {
+			return err
+		}
+
+		formatted := draft.String()
+		if skipReadOnly("rewrite " + path) {
+			fmt.Printf("\n%s", formatted)
+			return nil
+		}
+		if err := os.WriteFile(path, []byte(formatted), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Rewrote %s:\n\n%s", path, formatted)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(formatCommitMsgCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// Hooks pass whatever branch they run on, so any name must give either a
+// valid ticket ID or an error, with the default and a custom template.
+func FuzzExtractTicketFromBranch(f *testing.F) {
+	for _, seed := range []string{
+		"lv-fix-user-details-window-width/CPRE-11347",
+		"",
+		"/",
+		"//CPRE-1/",
+		"main",
+		"feature/ünïcode/ABC-1",
+		"ab/fix/CPRE-1-" + strings.Repeat("long-", 2000) + "name",
+		"\xff/CPRE-1",
+	} {
+		f.Add(seed)
+	}
+	templates := []*convention.BranchTemplate{
+		convention.MustParseBranchTemplate(convention.DefaultBranchTemplate),
+		convention.MustParseBranchTemplate("{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}"),
+	}
+	defer func(t *convention.BranchTemplate) { branchTemplate = t }(branchTemplate)
+	f.Fuzz(func(t *testing.T, branch string) {
+		for _, tmpl := range templates {
+			branchTemplate = tmpl
+			ticket, err := extractTicketFromBranch(branch)
+			if err == nil && !convention.TicketIDPattern.MatchString(ticket) {
+				t.Errorf("extractTicketFromBranch(%q) with %s = %q, not a ticket ID", branch, tmpl, ticket)
+			}
+		}
+	})
+}
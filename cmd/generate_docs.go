@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// generateCmd groups the commands that generate files for packaging.
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate man pages and shell completions for packaging",
+}
+
+// generateDocsCmd represents the command to write man pages and completion files.
+var generateDocsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Write man pages and shell completion files into directories",
+	Long: `Write a man page for every command into --man and completion files for
+bash, zsh, fish and PowerShell into --completions, so packages (e.g. a
+Homebrew formula or a Scoop manifest) and users can install them as they are.
+The directories are created if needed.
+
+The completion files are named as the shells look them up: gh for bash, _gh
+for zsh, gh.fish for fish and gh.ps1 for PowerShell. The man page of gh also
+lists the configuration files and the environment variables that are read.
+Set SOURCE_DATE_EPOCH for reproducible man pages.`,
+	Example: `  gh generate docs --man share/man/man1 --completions share/completions`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manDir, _ := cmd.Flags().GetString("man")
+		completionDir, _ := cmd.Flags().GetString("completions")
+		if manDir == "" && completionDir == "" {
+			return fmt.Errorf("nothing to generate; use --man <dir>, --completions <dir> or both")
+		}
+		root := cmd.Root()
+		root.DisableAutoGenTag = true
+		if manDir != "" {
+			if err := generateManPages(root, manDir); err != nil {
+				return err
+			}
+		}
+		if completionDir != "" {
+			if err := generateCompletions(root, completionDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// generateManPages writes the man pages of root and its subcommands to dir.
+func generateManPages(root *cobra.Command, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	header := &doc.GenManHeader{Section: "1", Source: "git-helper " + version, Manual: "git-helper manual"}
+	if err := doc.GenManTree(root, header, dir); err != nil {
+		return fmt.Errorf("failed to generate man pages: %w", err)
+	}
+	// The root page also documents what is read besides the flags.
+	path := filepath.Join(dir, root.Name()+".1")
+	page, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	before, after, found := strings.Cut(string(page), ".SH SEE ALSO")
+	if !found {
+		before, after = string(page), ""
+	} else {
+		after = ".SH SEE ALSO" + after
+	}
+	if err := os.WriteFile(path, []byte(before+manFilesSection()+after), 0o644); err != nil {
+		return err
+	}
+	pages, _ := filepath.Glob(filepath.Join(dir, "*.1"))
+	fmt.Printf("Wrote %d man pages to %s.\n", len(pages), dir)
+	return nil
+}
+
+// manFilesSection describes the configuration files and environment
+// variables in roff, for the man page of the root command.
+func manFilesSection() string {
+	var b strings.Builder
+	b.WriteString(".SH FILES\n")
+	b.WriteString(".TP\n~/.git\\-helper\\-cli/config.json\nPersonal configuration; \\fBgh config docs\\fP lists every key.\n")
+	b.WriteString(".TP\n" + manEscape(repoConfigFile) + "\nConfiguration shared by a repository, at its top level. It overrides the conventions of the personal configuration.\n")
+	b.WriteString(".SH ENVIRONMENT\n")
+	for _, key := range configKeys(reflect.TypeOf(Config{}), nil) {
+		if key.env != "" {
+			fmt.Fprintf(&b, ".TP\n%s\nTakes precedence over %s in the configuration.\n", key.env, manEscape(key.name()))
+		}
+	}
+	fmt.Fprintf(&b, ".TP\n%s\nRecord the prompts, commands and API requests of a run to this file.\n", recordEnv)
+	fmt.Fprintf(&b, ".TP\n%s\nReplay a run from a file recorded with %s.\n", replayEnv, recordEnv)
+	return b.String()
+}
+
+// manEscape escapes text for roff, where a hyphen is a typographic hyphen and
+// a leading period starts a request.
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// generateCompletions writes the completion files of every shell cobra
+// supports to dir.
+func generateCompletions(root *cobra.Command, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	name := root.Name()
+	for _, c := range []struct {
+		file     string
+		generate func(string) error
+	}{
+		{name, func(path string) error { return root.GenBashCompletionFileV2(path, true) }},
+		{"_" + name, root.GenZshCompletionFile},
+		{name + ".fish", func(path string) error { return root.GenFishCompletionFile(path, true) }},
+		{name + ".ps1", root.GenPowerShellCompletionFileWithDesc},
+	} {
+		if err := c.generate(filepath.Join(dir, c.file)); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", c.file, err)
+		}
+	}
+	fmt.Printf("Wrote completions for bash, zsh, fish and PowerShell to %s.\n", dir)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateDocsCmd)
+	generateDocsCmd.Flags().String("man", "", "Directory to write the man pages to")
+	generateDocsCmd.Flags().String("completions", "", "Directory to write the shell completion files to")
+}
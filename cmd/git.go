@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gitOutput runs a git command and returns its trimmed standard output.
+func gitOutput(args ...string) (string, error) {
+	if gitMutates(args) && skipCommand("git", args) {
+		return "", nil
+	}
+	call := shellCommand("git", args)
+	if e, ok := replayed(replayGit, call); ok {
+		return e.Output, e.err()
+	}
+	c, cancel := gitCommand(args...)
+	defer cancel()
+	out, err := c.Output()
+	if gitMutates(args) {
+		audit(call, err)
+	}
+	if err != nil {
+		err = explainCancel(err, "git "+strings.Join(args, " "), gitTimeout)
+		recordCall(replayGit, call, "", err)
+		return "", err
+	}
+	output := strings.TrimSpace(string(out))
+	recordCall(replayGit, call, output, nil)
+	return output, nil
+}
+
+// gitOutputIn runs a git command in the given repository and returns its trimmed standard output.
+func gitOutputIn(dir string, args ...string) (string, error) {
+	return gitOutput(append([]string{"-C", dir}, args...)...)
+}
+
+// runGit runs a git command with its output attached to the terminal.
+func runGit(args ...string) error {
+	if gitMutates(args) && skipCommand("git", args) {
+		return nil
+	}
+	fmt.Printf("Executing: git %s\n", strings.Join(args, " "))
+	call := shellCommand("git", args)
+	if e, ok := replayed(replayGit, call); ok {
+		fmt.Print(e.Output)
+		return e.err()
+	}
+	cmdGit, cancel := gitCommand(args...)
+	defer cancel()
+	var output bytes.Buffer
+	cmdGit.Stdin = os.Stdin
+	cmdGit.Stdout = recordOutput(os.Stdout, &output)
+	cmdGit.Stderr = recordOutput(os.Stderr, &output)
+
+	err := cmdGit.Run()
+	if gitMutates(args) {
+		audit(call, err)
+	}
+	err = explainCancel(err, "git "+strings.Join(args, " "), gitTimeout)
+	recordCall(replayGit, call, output.String(), err)
+	return err
+}
+
+// runCommand runs a non-git command with its output attached to the terminal.
+func runCommand(name string, args ...string) error {
+	if skipCommand(name, args) {
+		return nil
+	}
+	fmt.Printf("Executing: %s %s\n", name, strings.Join(args, " "))
+	call := shellCommand(name, args)
+	if e, ok := replayed(replayCommand, call); ok {
+		fmt.Print(e.Output)
+		return e.err()
+	}
+	// Other commands, such as builds, may legitimately take long, so only Ctrl+C stops them.
+	c := exec.CommandContext(baseContext, name, args...)
+	var output bytes.Buffer
+	c.Stdout = recordOutput(os.Stdout, &output)
+	c.Stderr = recordOutput(os.Stderr, &output)
+
+	err := c.Run()
+	audit(call, err)
+	recordCall(replayCommand, call, output.String(), err)
+	return err
+}
+
+// workingTreeDirty reports whether there are uncommitted or untracked changes.
+func workingTreeDirty() (bool, error) {
+	out, err := gitOutput("status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	return out != "", nil
+}
+
+// changedFile is a file with changes that are not staged, as listed by git status.
+type changedFile struct {
+	// Status is the two-letter status, e.g. " M" for modified or "??" for untracked.
+	Status string
+	Path   string
+}
+
+// unstagedFiles returns the modified, deleted and untracked files whose
+// changes are not staged.
+func unstagedFiles() ([]changedFile, error) {
+	out, err := gitOutput("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	var files []changedFile
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], entry[3:]
+		// Renames and copies are followed by their original path.
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+		if status == "??" || status[1] != ' ' {
+			files = append(files, changedFile{Status: status, Path: path})
+		}
+	}
+	return files, nil
+}
+
+// listRefs returns the short names of the refs matching the patterns, e.g.
+// "refs/heads" for local branches. Symbolic refs such as origin/HEAD are skipped.
+func listRefs(patterns ...string) ([]string, error) {
+	args := append([]string{"for-each-ref", "--format=%(refname:short)%09%(symref)"}, patterns...)
+	out, err := gitOutput(args...)
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, line := range strings.Split(out, "\n") {
+		name, symref, _ := strings.Cut(line, "\t")
+		if name == "" || symref != "" {
+			continue
+		}
+		refs = append(refs, name)
+	}
+	return refs, nil
+}
+
+// pathArgs returns the pathspec arguments limiting a git command to paths.
+func pathArgs(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, paths...)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Default GitHub API endpoints, overridable for GitHub Enterprise.
+const (
+	defaultGitHubAPIURL    = "https://api.github.com"
+	defaultGitHubUploadURL = "https://uploads.github.com"
+)
+
+// Timeout of release asset uploads.
+const githubUploadTimeout = 5 * time.Minute
+
+// GitHubConfig holds the GitHub API settings.
+type GitHubConfig struct {
+	// Token is a personal access token; the GITHUB_TOKEN environment variable takes precedence.
+	Token     string `json:"token,omitempty" env:"GITHUB_TOKEN"`
+	APIURL    string `json:"api_url,omitempty"`
+	UploadURL string `json:"upload_url,omitempty"`
+}
+
+// githubRemotePattern extracts owner and repository from GitHub remote URLs
+// such as git@github.com:owner/repo.git or https://github.com/owner/repo.
+var githubRemotePattern = regexp.MustCompile(`github[^/:]*[/:]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// githubClient is a minimal client for the GitHub REST API.
+type githubClient struct {
+	token     string
+	apiURL    string
+	uploadURL string
+	http      *http.Client
+}
+
+// newGitHubClient creates a GitHub client from the config and environment.
+func newGitHubClient(cfg Config) (*githubClient, error) {
+	token := githubTokenFromEnv()
+	if token == "" {
+		token = cfg.GitHub.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no GitHub token found. Set GITHUB_TOKEN or add \"github\": {\"token\": \"...\"} to the config file")
+	}
+	c := &githubClient{
+		token:     token,
+		apiURL:    defaultGitHubAPIURL,
+		uploadURL: defaultGitHubUploadURL,
+		http:      &http.Client{},
+	}
+	if cfg.GitHub.APIURL != "" {
+		c.apiURL = cfg.GitHub.APIURL
+	}
+	if cfg.GitHub.UploadURL != "" {
+		c.uploadURL = cfg.GitHub.UploadURL
+	}
+	return c, nil
+}
+
+// githubTokenFromEnv returns the GitHub token from the environment, if set.
+func githubTokenFromEnv() string {
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// githubRepo returns the owner and name of the GitHub repository behind the origin remote.
+func githubRepo() (string, string, error) {
+	url, err := gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	m := githubRemotePattern.FindStringSubmatch(url)
+	if m == nil {
+		return "", "", fmt.Errorf("origin remote '%s' is not a GitHub repository", url)
+	}
+	return m[1], m[2], nil
+}
+
+// do sends a request to the GitHub API and decodes the JSON response into out, if given.
+func (c *githubClient) do(method, url, contentType string, body io.Reader, out interface{}) error {
+	if method != http.MethodGet && skipReadOnly(fmt.Sprintf("GitHub API %s %s", method, url)) {
+		return nil
+	}
+	// Release assets can be large, so uploads get longer than other requests.
+	timeout := apiTimeout
+	if strings.HasPrefix(url, c.uploadURL) {
+		timeout = githubUploadTimeout
+	}
+	ctx, cancel := withTimeout(timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	// Release assets are uploaded from files, whose size GitHub needs up
+	// front: it rejects chunked uploads.
+	if f, ok := body.(*os.File); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		req.ContentLength = info.Size()
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return explainCancel(err, fmt.Sprintf("GitHub API %s %s", method, url), timeout)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("GitHub API %s %s returned %s: %s", method, url, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// postJSON sends a JSON body to a GitHub API path and decodes the response into out.
+func (c *githubClient) postJSON(path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, c.apiURL+path, "application/json", bytes.NewReader(body), out)
+}
+
+// get fetches a GitHub API path and decodes the JSON response into out.
+func (c *githubClient) get(path string, out interface{}) error {
+	return c.do(http.MethodGet, c.apiURL+path, "", nil, out)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// inGitHubActions reports whether the command runs in a GitHub Actions job.
+func inGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// Escapes of workflow command messages and of their properties, which
+// also end at commas and colons.
+var (
+	actionsDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	actionsPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// actionsViolationColumns are the columns of the job summary table.
+var actionsViolationColumns = []tableColumn{
+	{name: "ref"},
+	{name: "subject"},
+	{name: "rule"},
+	{name: "message"},
+	{name: "suggestion"},
+}
+
+// actionsReport reports convention violations to GitHub Actions: each one as
+// an error annotation, which the checks of a pull request list, and all of
+// them as a table in the job summary.
+type actionsReport struct {
+	title string
+	t     *table
+}
+
+// newActionsReport starts a report whose summary has the given heading.
+func newActionsReport(title string) *actionsReport {
+	return &actionsReport{title: title, t: newTable(actionsViolationColumns...)}
+}
+
+// add annotates a violation of a commit or branch, named by ref.
+func (r *actionsReport) add(ref, subject string, v convention.Violation) {
+	message := v.Message
+	if v.Suggestion != "" {
+		message += fmt.Sprintf(" (try: %q)", v.Suggestion)
+	}
+	properties := []string{"title=" + actionsPropertyEscaper.Replace(ref+" "+v.Rule)}
+	if file, line := violationLocation(ref, v); file != "" {
+		properties = append(properties, "file="+actionsPropertyEscaper.Replace(file))
+		if line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", line))
+		}
+	}
+	fmt.Printf("::error %s::%s\n", strings.Join(properties, ","), actionsDataEscaper.Replace(subject+": "+message))
+	r.t.add(ref, subject, v.Rule, v.Message, v.Suggestion)
+}
+
+// violationLocation returns the file, and line if known, that a violation is
+// annotated on. Unknown types and products are fixed in the configuration;
+// other commit rules concern the header, the first line of the commit
+// message. Branch names are in no file, so their annotations belong to the run.
+func violationLocation(ref string, v convention.Violation) (string, int) {
+	switch {
+	case v.Rule == convention.RuleHeaderType || v.Rule == convention.RuleHeaderProduct:
+		if _, ok, _ := loadRepoConfig(); ok {
+			return repoConfigFile, 0
+		}
+		path, _ := configFilePath()
+		return path, 0
+	case ref == "branch":
+		return "", 0
+	default:
+		return ".git/COMMIT_EDITMSG", 1
+	}
+}
+
+// writeSummary appends the report to the job summary, with result as the
+// line under the heading. Outside a step with a summary file it does nothing.
+func (r *actionsReport) writeSummary(result string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the job summary: %w", err)
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "### %s\n\n%s\n\n", r.title, result)
+	if len(r.t.rows) > 0 {
+		shown := make([]int, len(r.t.columns))
+		for i := range shown {
+			shown[i] = i
+		}
+		r.t.writeMarkdown(f, shown)
+		fmt.Fprintln(f)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// ticketEvent is one entry of a ticket's activity timeline.
+type ticketEvent struct {
+	When time.Time
+	Repo string
+	Kind string // commit, branch, note or pr
+	Ref  string
+	Text string
+}
+
+// parseUnix parses a unix timestamp as printed by git's %ct format.
+func parseUnix(s string) time.Time {
+	sec, _ := strconv.ParseInt(s, 10, 64)
+	return time.Unix(sec, 0)
+}
+
+// mentionsTicket reports whether text refers to the ticket itself, so that
+// CPRE-1 is not found in CPRE-10 or XCPRE-1.
+func mentionsTicket(text, ticket string) bool {
+	return slices.Contains(convention.TicketRefPattern.FindAllString(strings.ToUpper(text), -1), ticket)
+}
+
+// ticketCommitEvents finds commits mentioning the ticket in their message.
+func ticketCommitEvents(repo, ticket string) ([]ticketEvent, error) {
+	// git narrows the search down; mentionsTicket drops partial matches.
+	out, err := gitOutputIn(repo, "log", "--all", "--regexp-ignore-case", "--fixed-strings",
+		"--grep="+ticket, "--format=%h%x1f%ct%x1f%s%x1f%B%x1e")
+	if err != nil {
+		return nil, err
+	}
+	var events []ticketEvent
+	for _, record := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(strings.TrimSpace(record), "\x1f", 4)
+		if len(parts) == 4 && mentionsTicket(parts[3], ticket) {
+			events = append(events, ticketEvent{When: parseUnix(parts[1]), Kind: "commit", Ref: parts[0], Text: parts[2]})
+		}
+	}
+	return events, nil
+}
+
+// ticketBranchEvents finds local and remote branches whose name contains the ticket.
+func ticketBranchEvents(repo, ticket string) ([]ticketEvent, error) {
+	out, err := gitOutputIn(repo, "for-each-ref", "--format=%(refname:short)%09%(committerdate:unix)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	var events []ticketEvent
+	for _, line := range strings.Split(out, "\n") {
+		name, date, _ := strings.Cut(line, "\t")
+		if mentionsTicket(name, ticket) {
+			events = append(events, ticketEvent{When: parseUnix(date), Kind: "branch", Ref: name, Text: "last commit"})
+		}
+	}
+	return events, nil
+}
+
+// ticketNoteEvents finds git notes mentioning the ticket.
+func ticketNoteEvents(repo, ticket string) ([]ticketEvent, error) {
+	out, err := gitOutputIn(repo, "notes", "list")
+	if err != nil || out == "" {
+		// Repositories without notes have no notes ref at all.
+		return nil, nil
+	}
+	var events []ticketEvent
+	for _, line := range strings.Split(out, "\n") {
+		blob, commit, _ := strings.Cut(line, " ")
+		note, err := gitOutputIn(repo, "cat-file", "-p", blob)
+		if err != nil || !mentionsTicket(note, ticket) {
+			continue
+		}
+		date, _ := gitOutputIn(repo, "log", "-1", "--format=%ct", commit)
+		events = append(events, ticketEvent{When: parseUnix(date), Kind: "note", Ref: commit[:min(len(commit), 7)], Text: firstLine(note)})
+	}
+	return events, nil
+}
+
+// ticketPREvents searches GitHub pull request titles for the ticket.
+func ticketPREvents(cfg Config, repo, ticket string) ([]ticketEvent, error) {
+	client, err := newGitHubClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := gitOutputIn(repo, "remote", "get-url", "origin")
+	if err != nil {
+		return nil, err
+	}
+	m := githubRemotePattern.FindStringSubmatch(remote)
+	if m == nil {
+		return nil, nil
+	}
+
+	var result struct {
+		Items []struct {
+			Number    int       `json:"number"`
+			Title     string    `json:"title"`
+			State     string    `json:"state"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"items"`
+	}
+	query := url.QueryEscape(fmt.Sprintf("%s in:title repo:%s/%s is:pr", ticket, m[1], m[2]))
+	if err := client.get("/search/issues?q="+query, &result); err != nil {
+		return nil, err
+	}
+	var events []ticketEvent
+	for _, pr := range result.Items {
+		if !mentionsTicket(pr.Title, ticket) {
+			continue
+		}
+		events = append(events, ticketEvent{When: pr.CreatedAt, Kind: "pr", Ref: fmt.Sprintf("#%d", pr.Number), Text: fmt.Sprintf("%s (%s)", pr.Title, pr.State)})
+	}
+	return events, nil
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// timelineColumns are the columns of the grep-ticket timeline.
+var timelineColumns = []tableColumn{
+	{name: "when", kind: columnDateTime},
+	{name: "repo"},
+	{name: "kind"},
+	{name: "ref"},
+	{name: "text", truncate: true},
+}
+
+// grepTicketCmd represents the command to find all activity for a ticket.
+var grepTicketCmd = &cobra.Command{
+	Use:   "grep-ticket <TICKET>",
+	Short: "Show a timeline of commits, branches, notes and PRs for a ticket",
+	Long: `Search commit messages, branch names and git notes for a JIRA ticket and print
+a timeline of the related activity. If a GitHub token is configured, pull
+request titles are searched too.
+
+Use --workspace to search every repository of the configured workspace, and
+--sort, --columns and --format (table, json, csv or markdown) to shape the output.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		ticket := strings.ToUpper(args[0])
+		if err := convention.ValidateTicketID(ticket); err != nil {
+			return err
+		}
+		workspace, _ := cmd.Flags().GetBool("workspace")
+
+		var repos []string
+		if workspace {
+			repos = workspaceRepos(cfg)
+			if len(repos) == 0 {
+				return fmt.Errorf("no workspace repositories configured; add \"workspace\" to the config file")
+			}
+		} else {
+			repo, err := repoKey()
+			if err != nil {
+				return fmt.Errorf("not inside a git repository: %w", err)
+			}
+			repos = []string{repo}
+		}
+
+		var events []ticketEvent
+		for _, repo := range repos {
+			sources := []func() ([]ticketEvent, error){
+				func() ([]ticketEvent, error) { return ticketCommitEvents(repo, ticket) },
+				func() ([]ticketEvent, error) { return ticketBranchEvents(repo, ticket) },
+				func() ([]ticketEvent, error) { return ticketNoteEvents(repo, ticket) },
+			}
+			if cfg.GitHub.Token != "" || githubTokenFromEnv() != "" {
+				sources = append(sources, func() ([]ticketEvent, error) { return ticketPREvents(cfg, repo, ticket) })
+			}
+			for _, source := range sources {
+				found, err := source()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", filepath.Base(repo), err)
+					continue
+				}
+				for i := range found {
+					found[i].Repo = filepath.Base(repo)
+				}
+				events = append(events, found...)
+			}
+		}
+
+		if len(events) == 0 {
+			fmt.Printf("No activity found for %s.\n", linkTicket(ticket))
+			return nil
+		}
+		// The repository column only tells workspace results apart.
+		if !workspace && !cmd.Flags().Changed("columns") {
+			cmd.Flags().Set("columns", "when,kind,ref,text")
+		}
+		t := newTable(timelineColumns...)
+		for _, e := range events {
+			t.add(e.When, e.Repo, e.Kind, e.Ref, e.Text)
+		}
+		return t.render(cmd, "")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(grepTicketCmd)
+	grepTicketCmd.Flags().Bool("workspace", false, "Search every repository of the configured workspace")
+	addTableFlags(grepTicketCmd, timelineColumns, "when")
+}
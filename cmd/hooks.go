@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// hookFuncs are the functions of the hook templates. Configured values are
+// shell-quoted, so no type, product or template can break out of the script.
+var hookFuncs = template.FuncMap{"quote": shellQuote}
+
+// preReceiveRules is the pre-receive hook that checks pushes with rules embedded
+// in the script. Only the format, types, products and lengths are checked; the
+// style rules need the validation service.
+var preReceiveRules = template.Must(template.New("pre-receive-rules").Funcs(hookFuncs).Parse(`#!/usr/bin/env bash
+# pre-receive hook generated by git-helper-cli on {{.Generated}}.
+# Regenerate it with 'gh hooks generate-server' instead of editing it.
+set -u
+
+branch_pattern={{quote .BranchPattern}}
+long_lived_pattern={{quote .LongLivedPattern}}
+header_pattern={{quote .HeaderPattern}}
+header_example={{quote .HeaderExample}}
+desc_group={{.DescriptionGroup}}
+branch_example={{quote .BranchExample}}
+types={{quote .Types}}
+products={{quote .Products}}
+max_header={{.MaxHeader}}
+max_desc={{.MaxDescription}}
+status=0
+
+reject() {
+  echo "convention: $*" >&2
+  status=1
+}
+
+while read -r old new ref; do
+  case "$ref" in refs/heads/*) ;; *) continue ;; esac
+  branch=${ref#refs/heads/}
+  # Deleting a branch is always allowed.
+  [[ $new =~ ^0+$ ]] && continue
+
+  if ! [[ $branch =~ $long_lived_pattern || $branch =~ $branch_pattern ]]; then
+    reject "branch '$branch' must look like $branch_example"
+  fi
+
+  if [[ $old =~ ^0+$ ]]; then range=("$new" --not --all); else range=("$old..$new"); fi
+  while read -r sha header; do
+    if ! [[ $header =~ $header_pattern ]]; then
+      reject "$sha: header must look like '$header_example' (types: $types; products: $products)"
+      continue
+    fi
+    desc=""
+    (( desc_group > 0 )) && desc=${BASH_REMATCH[desc_group]}
+    if (( ${#header} > max_header )); then
+      reject "$sha: header is ${#header} characters long (max $max_header)"
+    fi
+    if (( ${#desc} > max_desc )); then
+      reject "$sha: description is ${#desc} characters long (max $max_desc)"
+    fi
+  done < <(git log --no-merges --format='%h %s' "${range[@]}")
+done
+
+exit $status
+`))
+
+// preReceiveService is the pre-receive hook that checks pushes with the
+// serve-validation service, applying exactly the rules of the CLI.
+var preReceiveService = template.Must(template.New("pre-receive-service").Funcs(hookFuncs).Parse(`#!/usr/bin/env bash
+# pre-receive hook generated by git-helper-cli on {{.Generated}}.
+# Regenerate it with 'gh hooks generate-server' instead of editing it.
+set -u
+
+service={{quote .Service}}
+status=0
+
+json_string() {
+  printf '"%s"' "$(printf '%s' "$1" | sed 's/\\/\\\\/g; s/"/\\"/g; s/\t/\\t/g')"
+}
+
+while read -r old new ref; do
+  case "$ref" in refs/heads/*) ;; *) continue ;; esac
+  branch=${ref#refs/heads/}
+  # Deleting a branch is always allowed.
+  [[ $new =~ ^0+$ ]] && continue
+
+  if [[ $old =~ ^0+$ ]]; then range=("$new" --not --all); else range=("$old..$new"); fi
+  commits=""
+  while read -r header; do
+    commits+="${commits:+,}$(json_string "$header")"
+  done < <(git log --no-merges --format='%s' "${range[@]}")
+
+  body="{\"branch\":$(json_string "$branch"),\"commits\":[$commits]}"
+  if ! response=$(curl -fsS --max-time 10 -H 'Content-Type: application/json' -d "$body" "$service"); then
+    echo "convention: validation service $service is unreachable" >&2
+    status=1
+    continue
+  fi
+  if [[ $response != '{"valid":true'* ]]; then
+    echo "convention: push to '$branch' breaks the convention:" >&2
+    echo "$response" >&2
+    status=1
+  fi
+done
+
+exit $status
+`))
+
+// posixPattern rewrites a Go regular expression for bash's [[ =~ ]], which
+// uses POSIX extended syntax without \d.
+func posixPattern(re *regexp.Regexp) string {
+	return strings.ReplaceAll(re.String(), `\d`, "[0-9]")
+}
+
+// generateServerHook renders the pre-receive hook, calling the validation
+// service at serviceURL or, when it is empty, embedding the rules.
+func generateServerHook(cfg Config, serviceURL string) (string, error) {
+	rules := cfg.conventionRules()
+	subject, err := rules.SubjectTemplate()
+	if err != nil {
+		return "", err
+	}
+	tmpl := preReceiveRules
+	if serviceURL != "" {
+		tmpl = preReceiveService
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, map[string]any{
+		"Generated":        time.Now().Format(time.DateOnly),
+		"Service":          serviceURL,
+		"BranchPattern":    posixPattern(branchTemplate.Pattern(rules.BranchTypes)),
+		"BranchExample":    branchTemplate.Example(),
+		"LongLivedPattern": posixPattern(convention.LongLivedBranchPattern),
+		"HeaderPattern":    posixPattern(subject.HeaderPattern(rules.CommitTypes, rules.Products)),
+		"HeaderExample":    subject.Example(),
+		"DescriptionGroup": subject.DescriptionGroup(),
+		"MaxHeader":        rules.Style.MaxHeader(),
+		"MaxDescription":   rules.MaxCommitDescription,
+		"Types":            strings.Join(rules.CommitTypes, ", "),
+		"Products":         strings.Join(rules.Products, ", "),
+	})
+	return b.String(), err
+}
+
+// hooksCmd groups the commands that generate git hooks.
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Generate git hooks that enforce the convention",
+}
+
+// hooksGenerateServerCmd represents the command to generate a server-side pre-receive hook.
+var hooksGenerateServerCmd = &cobra.Command{
+	Use:   "generate-server",
+	Short: "Generate a pre-receive hook that enforces the convention on the git server",
+	Long: `Generate a self-contained pre-receive hook for GitLab, Gitea or Bitbucket
+Server admins to enforce the convention centrally. The hook rejects pushes of
+branches and commits that do not follow it; deleting branches, tags and merge
+commits are always allowed.
+
+By default the rules from your config file (branch and commit types, products
+and length limits) are embedded in the script, which only needs bash and git.
+With --service, the hook posts each push to a 'gh serve-validation' endpoint
+instead, so the style rules apply too and rule changes need no new hook.
+
+Install the script as e.g. custom_hooks/pre-receive on GitLab, paste it into the
+repository's Git Hooks settings on Gitea, or use it with an external hooks add-on
+on Bitbucket Server.`,
+	Example: `  gh hooks generate-server -o pre-receive
+  gh hooks generate-server --service http://validation.internal:8083/validate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		service, _ := cmd.Flags().GetString("service")
+		output, _ := cmd.Flags().GetString("output")
+
+		script, err := generateServerHook(cfg, service)
+		if err != nil {
+			return fmt.Errorf("failed to generate hook: %w", err)
+		}
+		if output == "" {
+			fmt.Print(script)
+			return nil
+		}
+		if skipReadOnly("write pre-receive hook to " + output) {
+			return nil
+		}
+		if err := os.WriteFile(output, []byte(script), 0o755); err != nil {
+			return fmt.Errorf("failed to write hook: %w", err)
+		}
+		fmt.Printf("Wrote pre-receive hook to %s\n", output)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksGenerateServerCmd)
+	hooksGenerateServerCmd.Flags().String("service", "", "URL of a serve-validation endpoint to check pushes with")
+	hooksGenerateServerCmd.Flags().StringP("output", "o", "", "File to write the hook to (default: stdout)")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// TestTemplatedSubjectPassesHooks checks that a subject rendered from a
+// custom commit_template passes the commit-msg hook and the pre-receive hook
+// generated from the same config.
+func TestTemplatedSubjectPassesHooks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.Mkdir(filepath.Join(home, ".git-helper-cli"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"abbreviation":"lv","commit_template":{"subject":"[{{.Ticket}}] {{.Type}}: {{.Desc}}"}}`
+	if err := os.WriteFile(filepath.Join(home, ".git-helper-cli", "config.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(b *convention.BranchTemplate, m *convention.MessageTemplate) {
+		branchTemplate, commitTemplate = b, m
+	}(branchTemplate, commitTemplate)
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := configureTemplates(cfg); err != nil {
+		t.Fatal(err)
+	}
+	subject, _, err := commitTemplate.Render(convention.Message{Type: "fix", Product: "lego", Desc: "handle empty playlists", Ticket: "CPRE-11347", Verb: "Fixes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "[CPRE-11347] fix: handle empty playlists" {
+		t.Fatalf("rendered subject %q", subject)
+	}
+
+	commitMsg := func(header string) error {
+		path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		if err := os.WriteFile(path, []byte(header+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rootCmd.SetArgs([]string{"hooks", "commit-msg", path})
+		defer rootCmd.SetArgs(nil)
+		return rootCmd.Execute()
+	}
+	if err := commitMsg(subject); err != nil {
+		t.Errorf("commit-msg rejected %q: %v", subject, err)
+	}
+	if err := commitMsg("fix(lego): handle empty playlists"); err == nil {
+		t.Errorf("commit-msg accepted a header that does not follow the template")
+	}
+
+	script, err := generateServerHook(cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{}
+	for _, m := range regexp.MustCompile(`(?m)^(header_pattern|desc_group)=(.*)$`).FindAllStringSubmatch(script, -1) {
+		vars[m[1]] = m[2]
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	check := `header_pattern=` + vars["header_pattern"] + `; desc_group=` + vars["desc_group"] + `
+[[ $1 =~ $header_pattern ]] && printf '%s' "${BASH_REMATCH[desc_group]}"`
+	out, err := exec.Command(bash, "-c", check, "bash", subject).Output()
+	if err != nil {
+		t.Fatalf("pre-receive header_pattern %s does not match %q", vars["header_pattern"], subject)
+	}
+	if string(out) != "handle empty playlists" {
+		t.Errorf("pre-receive hook read the description of %q as %q", subject, out)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Supported values of hyperlinks.
+const (
+	hyperlinksAuto   = "auto"
+	hyperlinksAlways = "always"
+	hyperlinksNever  = "never"
+)
+
+// ticketLinkBase is what ticket IDs are appended to for their JIRA link, or
+// "" when printed tickets are not links.
+var ticketLinkBase string
+
+// configureHyperlinks decides whether printed tickets link to JIRA: always,
+// never, or by default when stdout is a terminal known to support OSC 8.
+func configureHyperlinks(cfg Config) error {
+	ticketLinkBase = ""
+	switch cfg.Hyperlinks {
+	case "", hyperlinksAuto:
+		if !term.IsTerminal(int(os.Stdout.Fd())) || !terminalSupportsHyperlinks() {
+			return nil
+		}
+	case hyperlinksAlways:
+	case hyperlinksNever:
+		return nil
+	default:
+		return fmt.Errorf("unknown hyperlinks '%s'; use %s, %s or %s", cfg.Hyperlinks, hyperlinksAuto, hyperlinksAlways, hyperlinksNever)
+	}
+	if url := ticketURL(cfg, ""); url != "" {
+		ticketLinkBase = url
+	}
+	return nil
+}
+
+// terminalSupportsHyperlinks guesses from the environment whether the
+// terminal shows OSC 8 hyperlinks. Terminals that do not may print the
+// escape sequences, so only those known to support them are trusted.
+func terminalSupportsHyperlinks() bool {
+	termName := os.Getenv("TERM")
+	switch {
+	case termName == "dumb", os.Getenv("TMUX") != "", strings.HasPrefix(termName, "screen"):
+		// tmux and screen only pass hyperlinks on when configured to.
+		return false
+	case os.Getenv("WT_SESSION") != "", os.Getenv("KITTY_WINDOW_ID") != "",
+		os.Getenv("KONSOLE_VERSION") != "", os.Getenv("DOMTERM") != "":
+		return true
+	case strings.Contains(termName, "kitty"), strings.Contains(termName, "ghostty"),
+		strings.HasPrefix(termName, "alacritty"), strings.HasPrefix(termName, "foot"), strings.HasPrefix(termName, "wezterm"):
+		return true
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty", "Tabby", "rio":
+		return true
+	}
+	// GNOME Terminal and the other VTE terminals support them since 0.50.
+	vte, _ := strconv.Atoi(os.Getenv("VTE_VERSION"))
+	return vte >= 5000
+}
+
+// hyperlink makes text an OSC 8 hyperlink to url.
+func hyperlink(url, text string) string {
+	return "\033]8;;" + url + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// linkTicket returns a ticket ID to print, as a link to its JIRA issue when
+// hyperlinks are enabled.
+func linkTicket(ticketID string) string {
+	if ticketLinkBase == "" || ticketID == "" || ticketID == "-" {
+		return ticketID
+	}
+	return hyperlink(ticketLinkBase+ticketID, ticketID)
+}
+
+// padTicket is linkTicket for a column of width, padded as %-*s pads the
+// plain ticket ID, since the escape sequences take no room on screen.
+func padTicket(ticketID string, width int) string {
+	return linkTicket(ticketID) + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(ticketID)))
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Default issue type of incident tickets created by the incident command.
+const defaultIncidentIssueType = "Bug"
+
+// IncidentConfig sets where the incident command files new incident tickets.
+type IncidentConfig struct {
+	// Project is the JIRA project key of incident tickets, by default the
+	// first of ticket_projects.
+	Project string `json:"project,omitempty"`
+	// IssueType is the issue type of incident tickets, by default Bug.
+	IssueType string `json:"issue_type,omitempty"`
+}
+
+// createIncidentTicket files a JIRA ticket for an incident and returns its key.
+func createIncidentTicket(cfg Config, summary string) (string, error) {
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return "", fmt.Errorf("%w; or give the incident's ticket: gh incident <TICKET>", err)
+	}
+	project := cfg.Incident.Project
+	if project == "" && len(cfg.TicketProjects) > 0 {
+		project = cfg.TicketProjects[0]
+	}
+	if project == "" {
+		return "", fmt.Errorf("no JIRA project for incident tickets; set \"incident\": {\"project\": \"...\"} in the config, or give the incident's ticket: gh incident <TICKET>")
+	}
+	issueType := cfg.Incident.IssueType
+	if issueType == "" {
+		issueType = defaultIncidentIssueType
+	}
+	key, err := client.createIssue(map[string]interface{}{
+		"project":   map[string]string{"key": project},
+		"summary":   summary,
+		"issuetype": map[string]string{"name": issueType},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the incident ticket: %w", err)
+	}
+	return key, nil
+}
+
+// incidentStartPoint fetches the default branch of origin and returns it as
+// the start point of a hotfix. Failing to fetch only warns, as the last
+// fetched state is better than nothing; without a default branch the hotfix
+// starts from HEAD.
+func incidentStartPoint() string {
+	base, err := defaultBaseBranch()
+	if err != nil {
+		fmt.Printf("Warning: %v; starting from the current HEAD.\n", err)
+		return ""
+	}
+	if err := runGit("fetch", "origin", strings.TrimPrefix(base, "origin/")); err != nil {
+		fmt.Printf("Warning: failed to fetch %s, starting from it as last fetched: %v\n", base, err)
+	}
+	return base
+}
+
+// incidentCmd represents the command to start responding to an incident.
+var incidentCmd = &cobra.Command{
+	Use:   "incident [TICKET]",
+	Short: "Start a hotfix branch for an incident with as few questions as possible",
+	Long: `Start responding to an incident: create a hotfix branch from the freshly
+fetched default branch of origin and switch to it, asking at most for a
+one-line summary of the incident.
+
+Give the incident's ticket to link it; without one, a JIRA ticket is filed in
+"incident.project" (or the first of "ticket_projects") with the summary. The
+branch is named with the hotfix type, e.g. lv-hotfix-db-failover/CPRE-11347,
+and confirmations and checks that are not critical, such as checking the
+ticket in JIRA, are skipped. Running it again for the same ticket switches
+back to its branch.
+
+Every change made while working on the incident is recorded in an audit
+trail, including later commits and pushes on its branch; see 'gh incident log'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if cfg.Abbreviation == "" {
+			return fmt.Errorf("no configuration found. Please run 'git-helper-cli config' to set your two-letter abbreviation")
+		}
+		summary, err := cmd.Flags().GetString("summary")
+		if err != nil {
+			return err
+		}
+		env, err := cmd.Flags().GetString("env")
+		if err != nil {
+			return err
+		}
+		if env != "" {
+			if err := cfg.checkEnvironment(env); err != nil {
+				return err
+			}
+		}
+
+		ticketID := ""
+		if len(args) == 1 {
+			ticketID = strings.ToUpper(args[0])
+			if err := convention.ValidateTicketID(ticketID); err != nil {
+				return err
+			}
+			// Back on an incident that already has a branch, go straight to it.
+			if branches, err := ticketBranches(ticketID); err == nil && len(branches) > 0 {
+				auditTicket = ticketID
+				return checkoutTicketBranch(cfg, branches[0])
+			}
+			if summary == "" {
+				if ticket, ok := lookupTicket(cfg, ticketID); ok {
+					summary = ticket.Summary
+				}
+			}
+		}
+		if summary == "" {
+			if err := askOne(&survey.Input{Message: "What is happening? (one line)"}, &summary, survey.WithValidator(survey.Required)); err != nil {
+				return err
+			}
+		}
+		description := convention.Slugify(summary, cfg.Limits.branchDescription())
+		if description == "" {
+			description = "incident"
+		}
+
+		if ticketID == "" {
+			if ticketID, err = createIncidentTicket(cfg, summary); err != nil {
+				return err
+			}
+			auditTicket = ticketID
+			audit("created ticket "+ticketID+": "+summary, nil)
+		} else {
+			auditTicket = ticketID
+			audit("linked ticket "+ticketID+": "+summary, nil)
+		}
+		if url := ticketURL(cfg, ticketID); url != "" {
+			fmt.Printf("Incident ticket: %s\n", url)
+		}
+
+		branchName, err := assembleBranchName(cfg, convention.HotfixBranchType, description, ticketID, env, nil)
+		if err != nil {
+			return err
+		}
+		if err := checkReservedBranch(cfg, branchName); err != nil {
+			return err
+		}
+		if branchName, err = resolveCaseCollision(branchName, false); err != nil {
+			return err
+		}
+		fmt.Printf("Branch name: %s\n", branchName)
+		meta := BranchMetadata{Ticket: ticketID, Env: env, Incident: true}
+		if err := createBranch(cfg, branchName, incidentStartPoint(), false, "", meta); err != nil {
+			return err
+		}
+		fmt.Println("Changes on this branch are recorded in the audit trail ('gh incident log').")
+		return nil
+	},
+}
+
+// incidentLogCmd represents the command to show the audit trail of incidents.
+var incidentLogCmd = &cobra.Command{
+	Use:   "log [TICKET]",
+	Short: "Show the audit trail of incidents",
+	Long: `Show every change recorded while working on incidents, oldest first: the
+tickets created or linked, and the git commands and JIRA requests that changed
+something, with when and in which repository they ran. Give a ticket to only
+show its incident.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := loadAuditTrail()
+		if err != nil {
+			return err
+		}
+		shown := 0
+		for _, e := range entries {
+			if len(args) == 1 && !strings.EqualFold(e.Ticket, args[0]) {
+				continue
+			}
+			fmt.Printf("%s  %s %-20s %s\n", formatDateTime(e.Time), padTicket(e.Ticket, 12), filepath.Base(e.Repo), strings.ReplaceAll(e.Action, "\n", `\n`))
+			shown++
+		}
+		if shown == 0 {
+			fmt.Println("No incident actions recorded.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(incidentCmd)
+	incidentCmd.AddCommand(incidentLogCmd)
+	incidentCmd.Flags().String("summary", "", "One-line summary of the incident, used for the ticket and branch description")
+	incidentCmd.Flags().String("env", "", "Affected environment, one of the configured environments")
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// IngestConfig configures the provider webhook endpoint of `ingest`.
+type IngestConfig struct {
+	// Secret verifies webhooks: the GitHub webhook secret or the GitLab secret token.
+	Secret string `json:"secret,omitempty"`
+}
+
+// providerEvent is a pull request or branch change reported by a hosting provider.
+type providerEvent struct {
+	Provider string
+	Repo     string // e.g. "owner/name"
+	Branch   string
+	PR       *PRLink
+	Merged   bool
+	Closed   bool
+	Deleted  bool // the remote branch was deleted
+}
+
+// githubEvent is the part of GitHub pull_request and delete payloads we use.
+type githubEvent struct {
+	Action      string `json:"action"`
+	Ref         string `json:"ref"`
+	RefType     string `json:"ref_type"`
+	PullRequest struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+		Head    struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// gitlabEvent is the part of GitLab merge request and push payloads we use.
+type gitlabEvent struct {
+	Ref              string `json:"ref"`
+	After            string `json:"after"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		URL          string `json:"url"`
+		State        string `json:"state"`
+		SourceBranch string `json:"source_branch"`
+	} `json:"object_attributes"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// gitlabDeletedSHA is the "after" commit of a push that deletes a branch.
+const gitlabDeletedSHA = "0000000000000000000000000000000000000000"
+
+// parseProviderEvent extracts the branch change from a GitHub or GitLab
+// webhook. It returns nil for events that do not affect branch metadata.
+func parseProviderEvent(header http.Header, body []byte) (*providerEvent, error) {
+	if kind := header.Get("X-GitHub-Event"); kind != "" {
+		var p githubEvent
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		ev := &providerEvent{Provider: providerGitHub, Repo: p.Repository.FullName}
+		switch {
+		case kind == "pull_request" && p.PullRequest.Number > 0:
+			ev.Branch = p.PullRequest.Head.Ref
+			ev.PR = &PRLink{Provider: providerGitHub, Number: p.PullRequest.Number, URL: p.PullRequest.HTMLURL}
+			ev.Closed = p.Action == "closed"
+			ev.Merged = ev.Closed && p.PullRequest.Merged
+		case kind == "delete" && p.RefType == "branch":
+			ev.Branch = p.Ref
+			ev.Deleted = true
+		default:
+			return nil, nil
+		}
+		return ev, nil
+	}
+
+	if kind := header.Get("X-Gitlab-Event"); kind != "" {
+		var p gitlabEvent
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, err
+		}
+		ev := &providerEvent{Provider: providerGitLab, Repo: p.Project.PathWithNamespace}
+		switch {
+		case kind == "Merge Request Hook":
+			a := p.ObjectAttributes
+			ev.Branch = a.SourceBranch
+			ev.PR = &PRLink{Provider: providerGitLab, Number: a.IID, URL: a.URL}
+			ev.Merged = a.State == "merged"
+			ev.Closed = a.State == "merged" || a.State == "closed"
+		case kind == "Push Hook" && p.After == gitlabDeletedSHA:
+			ev.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+			ev.Deleted = true
+		default:
+			return nil, nil
+		}
+		return ev, nil
+	}
+	return nil, fmt.Errorf("unsupported webhook: expected an X-GitHub-Event or X-Gitlab-Event header")
+}
+
+// verifyProviderWebhook checks the GitHub signature or GitLab token of a webhook.
+func verifyProviderWebhook(secret string, header http.Header, body []byte) bool {
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// remoteMatches reports whether a remote URL points at the "owner/name" repository.
+func remoteMatches(remoteURL, repo string) bool {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(remoteURL, "/"), ".git")
+	return strings.HasSuffix(strings.ToLower(trimmed), "/"+strings.ToLower(repo)) ||
+		strings.HasSuffix(strings.ToLower(trimmed), ":"+strings.ToLower(repo))
+}
+
+// ingestMu serializes the metadata updates of concurrent webhook deliveries,
+// so one does not overwrite what another just recorded.
+var ingestMu sync.Mutex
+
+// applyProviderEvent records a provider event in the metadata of every local
+// clone of the repository that knows the branch. It returns the number of
+// branches updated.
+func applyProviderEvent(ev providerEvent) (int, error) {
+	ingestMu.Lock()
+	defer ingestMu.Unlock()
+	md, err := loadMetadata()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for repo, branches := range md.Repos {
+		meta, ok := branches[ev.Branch]
+		if !ok {
+			continue
+		}
+		if remote, err := gitOutputIn(repo, "remote", "get-url", "origin"); err != nil || !remoteMatches(remote, ev.Repo) {
+			continue
+		}
+		now := time.Now()
+		if ev.PR != nil {
+			meta.PR = ev.PR
+		}
+		if ev.Merged && meta.MergedAt.IsZero() {
+			meta.MergedAt = now
+		}
+		if ev.Closed && meta.ClosedAt.IsZero() {
+			meta.ClosedAt = now
+		}
+		if ev.Deleted {
+			meta.RemoteDeletedAt = now
+		}
+		// There is no pull request left to keep up to date.
+		if ev.Closed || ev.Deleted {
+			meta.AutoUpdate = false
+		}
+		branches[ev.Branch] = meta
+		updated++
+	}
+	if updated == 0 {
+		return 0, nil
+	}
+	return updated, saveMetadata(md)
+}
+
+// describe summarises the event for logging.
+func (ev providerEvent) describe() string {
+	switch {
+	case ev.Deleted:
+		return fmt.Sprintf("%s: branch %s deleted", ev.Repo, ev.Branch)
+	case ev.Merged:
+		return fmt.Sprintf("%s: #%d (%s) merged", ev.Repo, ev.PR.Number, ev.Branch)
+	case ev.Closed:
+		return fmt.Sprintf("%s: #%d (%s) closed", ev.Repo, ev.PR.Number, ev.Branch)
+	default:
+		return fmt.Sprintf("%s: #%d (%s) updated", ev.Repo, ev.PR.Number, ev.Branch)
+	}
+}
+
+// notifyProviderEvent sends the pr-merged notification for a merged pull
+// request of one of the user's branches. Providers redeliver webhooks, so the
+// pull request URL deduplicates it.
+func notifyProviderEvent(cfg Config, ev providerEvent) {
+	if !ev.Merged || ev.PR == nil {
+		return
+	}
+	ticket, _ := extractTicketFromBranch(ev.Branch)
+	err := notify(cfg, notification{
+		Event:   eventPRMerged,
+		Key:     ev.PR.URL,
+		Title:   fmt.Sprintf("#%d merged", ev.PR.Number),
+		Message: fmt.Sprintf("%s (%s) was merged in %s: %s", ev.Branch, ev.Repo, ev.Provider, ev.PR.URL),
+		Ticket:  ticket,
+	})
+	if err != nil {
+		fmt.Printf("Failed to send notification: %v\n", err)
+	}
+}
+
+// ingestHandler serves the provider webhook endpoint.
+func ingestHandler(cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 5<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if cfg.Ingest.Secret != "" && !verifyProviderWebhook(cfg.Ingest.Secret, r.Header, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		ev, err := parseProviderEvent(r.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if ev == nil || ev.Branch == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		n, err := applyProviderEvent(*ev)
+		if err != nil {
+			http.Error(w, "failed to update metadata", http.StatusInternalServerError)
+			fmt.Printf("Failed to record %s: %v\n", ev.describe(), err)
+			return
+		}
+		fmt.Printf("%s (%d local branches updated)\n", ev.describe(), n)
+		if n > 0 {
+			notifyProviderEvent(cfg, *ev)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ingestCmd represents the command to ingest provider webhooks into the metadata store.
+var ingestCmd = &cobra.Command{
+	Use:   "ingest [payload.json]",
+	Short: "Record pull request merges and branch deletions from provider webhooks",
+	Long: `Run an HTTP server that receives GitHub and GitLab webhooks and records merged
+or closed pull requests and deleted remote branches in the metadata store, so it
+stays accurate for actions done in the web UI.
+
+Point a webhook for pull request and branch delete (GitHub) or merge request and
+push (GitLab) events at http://<host>:<port>/webhook, using the "ingest.secret"
+from the config file as the webhook secret.
+
+Given a payload file ("-" for stdin) and --event, a single payload is recorded
+instead, e.g. from a CI job.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		if len(args) == 1 {
+			event, _ := cmd.Flags().GetString("event")
+			header := http.Header{}
+			switch {
+			case strings.HasSuffix(event, "Hook"):
+				header.Set("X-Gitlab-Event", event)
+			case event != "":
+				header.Set("X-GitHub-Event", event)
+			default:
+				return fmt.Errorf("--event is required with a payload file, e.g. pull_request or \"Merge Request Hook\"")
+			}
+			var body []byte
+			if args[0] == "-" {
+				body, err = io.ReadAll(os.Stdin)
+			} else {
+				body, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read payload: %w", err)
+			}
+			ev, err := parseProviderEvent(header, body)
+			if err != nil {
+				return fmt.Errorf("failed to parse payload: %w", err)
+			}
+			if ev == nil || ev.Branch == "" {
+				fmt.Println("Nothing to record.")
+				return nil
+			}
+			n, err := applyProviderEvent(*ev)
+			if err != nil {
+				return fmt.Errorf("failed to update metadata: %w", err)
+			}
+			fmt.Printf("%s (%d local branches updated)\n", ev.describe(), n)
+			if n > 0 {
+				notifyProviderEvent(cfg, *ev)
+			}
+			return nil
+		}
+
+		addr, _ := cmd.Flags().GetString("addr")
+		if cfg.Ingest.Secret == "" {
+			fmt.Println("Warning: no ingest.secret configured; requests will not be verified.")
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/webhook", ingestHandler(cfg))
+
+		fmt.Printf("Listening for provider webhooks on %s/webhook\n", addr)
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		return serveUntilCancelled(server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ingestCmd)
+	ingestCmd.Flags().String("addr", ":8082", "Address to listen on")
+	ingestCmd.Flags().String("event", "", "Event type of a payload file (GitHub event name or GitLab hook name)")
+}
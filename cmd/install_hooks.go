@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// helperHookMarker identifies hook scripts installed by install-hooks.
+const helperHookMarker = "# Installed by git-helper-cli"
+
+// helperHooks are the client-side hooks install-hooks manages.
+var helperHooks = []string{"commit-msg", "prepare-commit-msg"}
+
+// hooksDir returns the absolute path of the repository's hooks directory,
+// honouring core.hooksPath.
+func hooksDir() (string, error) {
+	dir, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not inside a git repository: %w", err)
+	}
+	return filepath.Abs(dir)
+}
+
+// helperHookScript returns a hook script that hands the hook over to the binary at exe.
+func helperHookScript(hook, exe string) string {
+	quoted := "'" + strings.ReplaceAll(exe, "'", `'\''`) + "'"
+	return fmt.Sprintf("#!/bin/sh\n%s; remove with 'gh uninstall-hooks'.\nexec %s hooks %s \"$@\"\n", helperHookMarker, quoted, hook)
+}
+
+// helperHookBinary returns the binary a hook script installed by
+// install-hooks calls. ok is false for other scripts.
+func helperHookBinary(script string) (exe string, ok bool) {
+	if !strings.Contains(script, helperHookMarker) {
+		return "", false
+	}
+	for _, line := range strings.Split(script, "\n") {
+		if rest, found := strings.CutPrefix(line, "exec '"); found {
+			exe, _, _ = strings.Cut(rest, "' hooks ")
+			return strings.ReplaceAll(exe, `'\''`, "'"), true
+		}
+	}
+	return "", true
+}
+
+// scissorsLine follows the comment prefix on the line that 'git commit -v'
+// puts above the diff; git drops it and everything below it.
+const scissorsLine = " ------------------------ >8 ------------------------"
+
+// commentPrefix returns what starts git's comment lines in the commit message
+// text: core.commentString or core.commentChar, '#' by default. With "auto",
+// git picks a character the message does not use, which the scissors line
+// shows if there is one.
+func commentPrefix(text string) string {
+	prefix := cmp.Or(gitConfigValue("core.commentString"), gitConfigValue("core.commentChar"), "#")
+	if prefix != "auto" {
+		return prefix
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if c, ok := strings.CutSuffix(line, scissorsLine); ok && c != "" {
+			return c
+		}
+	}
+	return "#"
+}
+
+// stripCommitComments removes what git strips from a commit message file: the
+// comment lines, and the scissors line and the diff below it.
+func stripCommitComments(text string) string {
+	prefix := commentPrefix(text)
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line == prefix+scissorsLine {
+			break
+		}
+		if !strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readCommitMessage reads a commit message file as git will commit it,
+// without comment lines or the diff of 'git commit -v'.
+func readCommitMessage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+	return strings.TrimSpace(stripCommitComments(string(data))), nil
+}
+
+// installHooksCmd represents the command to install the client-side hooks.
+var installHooksCmd = &cobra.Command{
+	Use:   "install-hooks",
+	Short: "Install git hooks that check commit messages and add the ticket reference",
+	Long: `Install two hooks in the current repository:
+
+  commit-msg          rejects commits whose header does not follow the convention
+  prepare-commit-msg  adds "Fixes <TICKET>" (or Closes/Refs) from the branch name
+
+The hooks call back into this binary, so they follow your configuration and
+stay current as the tool is updated. Merge, revert, fixup and squash commits
+are left alone. Running the command again updates the hooks, e.g. after moving
+the binary.
+
+Existing hooks of the same name are kept unless --force is given, which moves
+them aside to <hook>.bak; 'gh uninstall-hooks' restores them. An existing
+<hook>.bak is never overwritten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the git-helper binary: %w", err)
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return fmt.Errorf("failed to find the git-helper binary: %w", err)
+		}
+
+		// Check every hook before changing any, so a conflict leaves nothing half-installed.
+		for _, hook := range helperHooks {
+			data, err := os.ReadFile(filepath.Join(dir, hook))
+			if err != nil {
+				continue
+			}
+			if _, ours := helperHookBinary(string(data)); ours {
+				continue
+			}
+			if !force {
+				return fmt.Errorf("a %s hook already exists in %s; rerun with --force to move it to %s.bak", hook, dir, hook)
+			}
+			// The backup may be the only copy of an earlier hook, so it is never overwritten.
+			if _, err := os.Lstat(filepath.Join(dir, hook+".bak")); err == nil {
+				return fmt.Errorf("a %s hook already exists in %s, and so does %s.bak; move one of them out of the way first", hook, dir, hook)
+			}
+		}
+		if skipReadOnly("install hooks in " + dir) {
+			return nil
+		}
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		for _, hook := range helperHooks {
+			path := filepath.Join(dir, hook)
+			if data, err := os.ReadFile(path); err == nil {
+				if _, ours := helperHookBinary(string(data)); !ours {
+					if err := os.Rename(path, path+".bak"); err != nil {
+						return fmt.Errorf("failed to move the existing %s hook aside: %w", hook, err)
+					}
+					fmt.Printf("Moved the existing %s hook to %s.bak.\n", hook, path)
+				}
+			}
+			if err := os.WriteFile(path, []byte(helperHookScript(hook, exe)), 0o755); err != nil {
+				return fmt.Errorf("failed to install the %s hook: %w", hook, err)
+			}
+			fmt.Printf("Installed %s\n", path)
+		}
+		return nil
+	},
+}
+
+// uninstallHooksCmd represents the command to remove the client-side hooks.
+var uninstallHooksCmd = &cobra.Command{
+	Use:   "uninstall-hooks",
+	Short: "Remove the git hooks installed by install-hooks",
+	Long: `Remove the commit-msg and prepare-commit-msg hooks installed by
+'gh install-hooks', restoring any hooks it moved aside. Hooks installed by other
+tools are never touched. The hooks to be removed are listed first; --force
+removes them without asking.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+
+		var installed []string
+		for _, hook := range helperHooks {
+			path := filepath.Join(dir, hook)
+			if data, err := os.ReadFile(path); err == nil {
+				if _, ours := helperHookBinary(string(data)); ours {
+					installed = append(installed, path)
+				}
+			}
+		}
+		if len(installed) == 0 {
+			fmt.Println("No git-helper hooks are installed in this repository.")
+			return nil
+		}
+
+		confirm, err := confirmDeletion(cfg, "hooks", installed, "", force)
+		if err != nil || !confirm {
+			return err
+		}
+		if skipReadOnly("remove hooks from " + dir) {
+			return nil
+		}
+		for _, path := range installed {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			if _, err := os.Stat(path + ".bak"); err == nil {
+				if err := os.Rename(path+".bak", path); err != nil {
+					return fmt.Errorf("failed to restore %s: %w", path, err)
+				}
+				fmt.Printf("Restored the previous %s hook.\n", filepath.Base(path))
+			}
+		}
+		fmt.Printf("Removed %d hooks.\n", len(installed))
+		return nil
+	},
+}
+
+// hookAnnotation marks the commands that git hooks and CI run on every commit
+// or push. They must finish in a few milliseconds.
+const hookAnnotation = "hook"
+
+// hooksCommitMsgCmd is run by the commit-msg hook to check the commit header.
+var hooksCommitMsgCmd = &cobra.Command{
+	Use:         "commit-msg <message-file>",
+	Short:       "Check a commit message against the convention (run by the commit-msg hook)",
+	Hidden:      true,
+	Annotations: map[string]string{hookAnnotation: "true"},
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		message, err := readCommitMessage(args[0])
+		if err != nil {
+			return err
+		}
+		header := firstLine(message)
+		// An empty message aborts the commit anyway; generated messages are left alone.
+		for _, prefix := range []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "} {
+			if header == "" || strings.HasPrefix(header, prefix) {
+				return nil
+			}
+		}
+		violations := cfg.conventionRules().ValidateCommitHeader(header)
+		if len(violations) == 0 {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Commit header '%s' does not follow the convention:\n", header)
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "  - %s\n", v)
+		}
+		cmd.SilenceUsage = true
+		return fmt.Errorf("commit rejected; fix the message or use 'gh create-commit'")
+	},
+}
+
+// hooksPrepareCommitMsgCmd is run by the prepare-commit-msg hook to add the ticket reference.
+var hooksPrepareCommitMsgCmd = &cobra.Command{
+	Use:         "prepare-commit-msg <message-file> [source] [commit]",
+	Short:       "Add the ticket reference to a commit message (run by the prepare-commit-msg hook)",
+	Hidden:      true,
+	Annotations: map[string]string{hookAnnotation: "true"},
+	Args:        cobra.RangeArgs(1, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Merges, squashes and amended or reused commits already have their message.
+		if len(args) > 1 && (args[1] == "merge" || args[1] == "squash" || args[1] == "commit") {
+			return nil
+		}
+		branch, err := getCurrentBranch()
+		if err != nil {
+			return nil
+		}
+		ticketID, err := extractTicketFromBranch(branch)
+		if err != nil {
+			return nil
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read commit message: %w", err)
+		}
+		message, err := readCommitMessage(args[0])
+		if err != nil {
+			return err
+		}
+		if strings.Contains(strings.ToUpper(message), strings.ToUpper(ticketID)) {
+			return nil
+		}
+
+		verb := "Refs"
+		if b, ok := branchTemplate.Parse(branch); ok && b.Type != "" {
+			verb = convention.TicketVerb(b.Type)
+		}
+		// Add the reference after the message and before git's comment lines
+		// and the diff of 'git commit -v'.
+		content := string(data)
+		body, comments := content, ""
+		if i := strings.Index("\n"+content, "\n"+commentPrefix(content)); i >= 0 {
+			body, comments = content[:i], content[i:]
+		}
+		body = strings.TrimRight(body, "\n") + "\n\n" + verb + " " + ticketID + "\n"
+		if comments != "" {
+			body += "\n" + comments
+		}
+		return os.WriteFile(args[0], []byte(body), 0o644)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(installHooksCmd)
+	rootCmd.AddCommand(uninstallHooksCmd)
+	hooksCmd.AddCommand(hooksCommitMsgCmd)
+	hooksCmd.AddCommand(hooksPrepareCommitMsgCmd)
+	installHooksCmd.Flags().Bool("force", false, "Move existing hooks of the same name to <hook>.bak")
+	uninstallHooksCmd.Flags().Bool("force", false, "Remove the hooks without prompting")
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Default statuses that make `listen` queue a ticket for a new branch.
+var defaultReadyStatuses = []string{"Ready for Dev"}
+
+// Default issue type used for sub-tasks created from TODO items.
+const defaultJiraSubtaskType = "Sub-task"
+
+// JiraConfig holds the JIRA settings.
+type JiraConfig struct {
+	// BaseURL is the JIRA site, e.g. https://amagi.atlassian.net.
+	BaseURL string `json:"base_url,omitempty"`
+	// Email and Token authenticate against JIRA Cloud. Without an email the
+	// token is sent as a bearer token, as JIRA Server personal access tokens
+	// expect. The JIRA_API_TOKEN environment variable takes precedence.
+	Email string `json:"email,omitempty"`
+	Token string `json:"token,omitempty" env:"JIRA_API_TOKEN"`
+	// ReadyStatuses are the statuses that make `listen` suggest a branch.
+	ReadyStatuses []string `json:"ready_statuses,omitempty"`
+	// WebhookSecret must be passed as ?secret=... on webhook requests to `listen`.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	// SubtaskType is the issue type of sub-tasks created by `todo add --jira`.
+	SubtaskType string `json:"subtask_type,omitempty"`
+	// Transitions move the ticket along its workflow after commits and pull requests.
+	Transitions JiraTransitions `json:"transitions,omitzero"`
+	// CommentLinks comments on the ticket with the commit, branch and pull
+	// request after create-commit and create-pr, as --jira-comment does once.
+	CommentLinks bool `json:"comment_links,omitempty"`
+}
+
+// JiraTransitions names the workflow transitions applied to the ticket of a
+// branch, by transition or target status name, e.g. "In Review".
+type JiraTransitions struct {
+	// Commit maps commit types to the transition made after a commit of that
+	// type; "*" stands for the types not listed.
+	Commit map[string]string `json:"commit,omitempty"`
+	// PR is the transition made after opening a pull request.
+	PR string `json:"pr,omitempty"`
+}
+
+// forCommit returns the transition for a commit type, or "" for none.
+func (t JiraTransitions) forCommit(commitType string) string {
+	if name, ok := t.Commit[commitType]; ok {
+		return name
+	}
+	return t.Commit["*"]
+}
+
+// ticketURL returns the browser link for a ticket, or "" if no JIRA base URL is configured.
+func ticketURL(cfg Config, ticketID string) string {
+	if cfg.Jira.BaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(cfg.Jira.BaseURL, "/") + "/browse/" + ticketID
+}
+
+// errJiraNotFound is returned for JIRA API requests of issues that do not exist.
+var errJiraNotFound = errors.New("not found")
+
+// jiraClient is a minimal client for the JIRA REST API.
+type jiraClient struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// newJiraClient creates a JIRA client from the config and environment.
+func newJiraClient(cfg Config) (*jiraClient, error) {
+	token := os.Getenv("JIRA_API_TOKEN")
+	if token == "" {
+		token = cfg.Jira.Token
+	}
+	if cfg.Jira.BaseURL == "" || token == "" {
+		return nil, fmt.Errorf("JIRA is not configured. Add \"jira\": {\"base_url\": \"...\", \"email\": \"...\", \"token\": \"...\"} to the config file")
+	}
+	return &jiraClient{
+		baseURL: strings.TrimRight(cfg.Jira.BaseURL, "/"),
+		email:   cfg.Jira.Email,
+		token:   token,
+		http:    &http.Client{},
+	}, nil
+}
+
+// do sends a request to a JIRA API path, encoding in as JSON if given, and
+// decodes the JSON response into out, if given.
+func (c *jiraClient) do(method, path string, in, out interface{}) error {
+	if method != http.MethodGet && skipReadOnly(fmt.Sprintf("JIRA API %s %s", method, path)) {
+		return nil
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if method != http.MethodGet {
+		audit(fmt.Sprintf("JIRA API %s %s", method, path), err)
+	}
+	if err != nil {
+		return explainCancel(err, fmt.Sprintf("JIRA API %s %s", method, path), apiTimeout)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("JIRA API %s %s: %w", method, path, errJiraNotFound)
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("JIRA API %s %s returned %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// createSubtask creates a sub-task of a ticket and returns its key.
+func (c *jiraClient) createSubtask(cfg Config, parent, summary string) (string, error) {
+	issueType := cfg.Jira.SubtaskType
+	if issueType == "" {
+		issueType = defaultJiraSubtaskType
+	}
+	project, _, _ := strings.Cut(parent, "-")
+	return c.createIssue(map[string]interface{}{
+		"project":   map[string]string{"key": project},
+		"parent":    map[string]string{"key": parent},
+		"summary":   summary,
+		"issuetype": map[string]string{"name": issueType},
+	})
+}
+
+// createIssue creates an issue with the given fields and returns its key.
+func (c *jiraClient) createIssue(fields map[string]interface{}) (string, error) {
+	var out struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &out); err != nil {
+		return "", err
+	}
+	return out.Key, nil
+}
+
+// jiraIssue is the part of a JIRA issue the helper uses.
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary   string `json:"summary"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+// issue fetches the summary, type and status of a ticket.
+func (c *jiraClient) issue(key string) (jiraIssue, error) {
+	var out jiraIssue
+	err := c.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary,issuetype,status", nil, &out)
+	return out, err
+}
+
+// verifyTicket checks that a ticket exists in JIRA and is not closed.
+func verifyTicket(cfg Config, ticketID string) error {
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return err
+	}
+	issue, err := client.issue(ticketID)
+	if errors.Is(err, errJiraNotFound) {
+		return fmt.Errorf("ticket %s does not exist in JIRA", ticketID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify ticket %s: %w", ticketID, err)
+	}
+	if issue.Fields.Status.StatusCategory.Key == "done" {
+		return fmt.Errorf("ticket %s is %s", ticketID, issue.Fields.Status.Name)
+	}
+	return nil
+}
+
+// search returns up to max issues matching a JQL query, with their summary, type and status.
+func (c *jiraClient) search(jql string, max int) ([]jiraIssue, error) {
+	query := url.Values{
+		"jql":        {jql},
+		"fields":     {"summary,issuetype,status"},
+		"maxResults": {strconv.Itoa(max)},
+	}
+	var out struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	err := c.do(http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &out)
+	return out.Issues, err
+}
+
+// transition moves a ticket through the workflow transition with the given
+// name, or the one leading to the status with that name. It returns the
+// status the ticket is in afterwards, and false if it was already there.
+func (c *jiraClient) transition(key, name string) (string, bool, error) {
+	issue, err := c.issue(key)
+	if err != nil {
+		return "", false, err
+	}
+	if strings.EqualFold(issue.Fields.Status.Name, name) {
+		return issue.Fields.Status.Name, false, nil
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	var out struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.do(http.MethodGet, path, nil, &out); err != nil {
+		return "", false, err
+	}
+	var available []string
+	for _, t := range out.Transitions {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+			in := map[string]any{"transition": map[string]string{"id": t.ID}}
+			return t.To.Name, true, c.do(http.MethodPost, path, in, nil)
+		}
+		available = append(available, t.Name)
+	}
+	return "", false, fmt.Errorf("no transition '%s' from %s; available: %s", name, issue.Fields.Status.Name, strings.Join(available, ", "))
+}
+
+// transitionTicket applies a configured transition to a ticket after the work
+// was done, warning rather than failing as the commit or pull request stands.
+func transitionTicket(cfg Config, ticketID, name string) {
+	if name == "" || ticketID == "" {
+		return
+	}
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		fmt.Printf("Warning: not moving %s to '%s': %v\n", ticketID, name, err)
+		return
+	}
+	status, moved, err := client.transition(ticketID, name)
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: failed to move %s to '%s': %v\n", ticketID, name, err)
+	case moved && !readOnly:
+		fmt.Printf("Moved %s to %s.\n", linkTicket(ticketID), status)
+	}
+}
+
+// jiraComment reports whether to comment on the ticket after creating a
+// commit or pull request: as --jira-comment says, or else the config.
+func jiraComment(cmd *cobra.Command, cfg Config) bool {
+	if cmd.Flags().Changed("jira-comment") {
+		comment, _ := cmd.Flags().GetBool("jira-comment")
+		return comment
+	}
+	return cfg.Jira.CommentLinks
+}
+
+// commentLinks comments on a ticket with the latest commit of a branch and
+// its pull request, if it has one, for processes that want the work linked
+// from the issue. A failure is only a warning, like a failed transition.
+func commentLinks(cfg Config, ticketID, branch string, pr *PRLink) {
+	if ticketID == "" {
+		return
+	}
+	head, err := gitOutput("log", "-1", "--format=%H%x09%s")
+	if err != nil {
+		fmt.Printf("Warning: not commenting on %s: %v\n", ticketID, err)
+		return
+	}
+	sha, subject, _ := strings.Cut(head, "\t")
+	lines := []string{
+		fmt.Sprintf("Commit %s: %s", sha, subject),
+		"Branch: " + branch,
+	}
+	if pr != nil && pr.URL != "" {
+		lines = append(lines, fmt.Sprintf("Pull request: %s", pr.URL))
+	}
+	client, err := newJiraClient(cfg)
+	if err == nil {
+		err = client.comment(ticketID, strings.Join(lines, "\n"))
+	}
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: failed to comment on %s: %v\n", ticketID, err)
+	case !readOnly:
+		fmt.Printf("Commented on %s with the links.\n", linkTicket(ticketID))
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// LimitsConfig overrides the maximum description lengths, for teams with
+// different conventions.
+type LimitsConfig struct {
+	// BranchDescription is measured after spaces are replaced with hyphens.
+	BranchDescription int `json:"branch_description,omitempty"`
+	CommitDescription int `json:"commit_description,omitempty"`
+}
+
+// branchDescription returns the maximum branch description length.
+func (l LimitsConfig) branchDescription() int {
+	if l.BranchDescription > 0 {
+		return l.BranchDescription
+	}
+	return convention.DefaultMaxBranchDescription
+}
+
+// commitDescription returns the maximum commit description length.
+func (l LimitsConfig) commitDescription() int {
+	if l.CommitDescription > 0 {
+		return l.CommitDescription
+	}
+	return convention.DefaultMaxCommitDescription
+}
+
+// lengthHint describes the length limit in a prompt, including how much of it
+// an existing answer leaves, e.g. "max 30 characters, 12 left".
+func lengthHint(current string, maxLength int) string {
+	if current == "" {
+		return fmt.Sprintf("max %d characters", maxLength)
+	}
+	return fmt.Sprintf("max %d characters, %d left", maxLength, maxLength-utf8.RuneCountInString(current))
+}
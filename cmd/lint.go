@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// commitHeaders returns the abbreviated hash and header of each commit in the range.
+func commitHeaders(revRange string) ([][2]string, error) {
+	out, err := gitOutput("log", "--no-merges", "--format=%h%x09%s", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits in '%s': %w", revRange, err)
+	}
+	var commits [][2]string
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, "\t")
+		commits = append(commits, [2]string{hash, subject})
+	}
+	return commits, nil
+}
+
+// lintCommitsCmd represents the command to check commit messages against the style rules.
+var lintCommitsCmd = &cobra.Command{
+	Use:   "lint-commits [range]",
+	Short: "Check commit messages against the convention and style rules",
+	Long: `Check the headers of the commits in a range (default: commits not yet pushed
+to the upstream branch) against the commit convention and the style rules.
+
+Rules can be disabled with the "style.disabled" list in the config file:
+` + styleRulesHelp() + `
+Violations are reported with their rule ID and the command exits with status 1.
+In GitHub Actions (GITHUB_ACTIONS=true), each one is also reported as an error
+annotation, shown with the checks of the pull request, and all of them as a
+table in the job summary.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		revRange := "@{upstream}..HEAD"
+		if len(args) == 1 {
+			revRange = args[0]
+		}
+		commits, err := commitHeaders(revRange)
+		if err != nil {
+			return err
+		}
+
+		var report *actionsReport
+		if inGitHubActions() {
+			report = newActionsReport("Commit convention")
+		}
+		rules := cfg.conventionRules()
+		count := 0
+		for _, c := range commits {
+			for _, v := range rules.LintHeader(c[1]) {
+				fmt.Printf("%s %s\n", c[0], v)
+				if report != nil {
+					report.add(c[0], c[1], v)
+				}
+				count++
+			}
+		}
+		result := fmt.Sprintf("All %d commit(s) follow the convention.", len(commits))
+		if count > 0 {
+			result = fmt.Sprintf("%d style violation(s) found in %d commit(s)", count, len(commits))
+		}
+		if report != nil {
+			if err := report.writeSummary(result); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+		if count > 0 {
+			cmd.SilenceUsage = true
+			return errors.New(result)
+		}
+
+		fmt.Println(result)
+		return nil
+	},
+}
+
+// styleRulesHelp lists the configurable style rules for the help text.
+func styleRulesHelp() string {
+	ids := make([]string, 0, len(convention.StyleRules))
+	for id := range convention.StyleRules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	for _, id := range ids {
+		fmt.Fprintf(&b, "  %-20s %s\n", id, convention.StyleRules[id])
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(lintCommitsCmd)
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// jiraWebhook is the part of a JIRA issue webhook payload we use.
+type jiraWebhook struct {
+	WebhookEvent string `json:"webhookEvent"`
+	Issue        struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary   string `json:"summary"`
+			IssueType struct {
+				Name string `json:"name"`
+			} `json:"issuetype"`
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	} `json:"issue"`
+	Changelog struct {
+		Items []struct {
+			Field    string `json:"field"`
+			ToString string `json:"toString"`
+		} `json:"items"`
+	} `json:"changelog"`
+}
+
+// movedTo returns the status the issue was moved to, or "" if the status did not change.
+func (w jiraWebhook) movedTo() string {
+	for _, item := range w.Changelog.Items {
+		if item.Field == "status" {
+			return item.ToString
+		}
+	}
+	return ""
+}
+
+// queueSuggestion adds a ticket to the branch suggestion queue, replacing any
+// earlier suggestion for the same ticket.
+func queueSuggestion(s TicketSuggestion) error {
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	md.Suggestions = slices.DeleteFunc(md.Suggestions, func(old TicketSuggestion) bool {
+		return old.Ticket == s.Ticket
+	})
+	md.Suggestions = append(md.Suggestions, s)
+	return saveMetadata(md)
+}
+
+// dropSuggestion removes a ticket from the branch suggestion queue.
+func dropSuggestion(ticketID string) error {
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	n := len(md.Suggestions)
+	md.Suggestions = slices.DeleteFunc(md.Suggestions, func(s TicketSuggestion) bool {
+		return s.Ticket == ticketID
+	})
+	if len(md.Suggestions) == n {
+		return nil
+	}
+	return saveMetadata(md)
+}
+
+// pickSuggestion offers the tickets queued by `listen` and returns the chosen
+// one, or nil if the queue is empty or the user wants to start from scratch.
+func pickSuggestion() (*TicketSuggestion, error) {
+	md, err := loadMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+	if len(md.Suggestions) == 0 {
+		return nil, nil
+	}
+
+	options := make([]string, 0, len(md.Suggestions)+1)
+	for _, s := range md.Suggestions {
+		options = append(options, fmt.Sprintf("%s: %s", s.Ticket, s.Summary))
+	}
+	options = append(options, "None, start from scratch")
+
+	var index int
+	if err := askOne(&survey.Select{
+		Message: "These tickets are ready for development. Start work on one?",
+		Options: options,
+	}, &index); err != nil {
+		return nil, err
+	}
+	if index == len(md.Suggestions) {
+		return nil, nil
+	}
+	return &md.Suggestions[index], nil
+}
+
+// jiraWebhookHandler queues tickets moved to one of the ready statuses.
+func jiraWebhookHandler(cfg Config, notifications bool) http.HandlerFunc {
+	ready := cfg.Jira.ReadyStatuses
+	if len(ready) == 0 {
+		ready = defaultReadyStatuses
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if cfg.Jira.WebhookSecret != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("secret")), []byte(cfg.Jira.WebhookSecret)) != 1 {
+			http.Error(w, "invalid secret", http.StatusUnauthorized)
+			return
+		}
+
+		var payload jiraWebhook
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		status := payload.movedTo()
+		if payload.Issue.Key == "" || !slices.ContainsFunc(ready, func(s string) bool { return strings.EqualFold(s, status) }) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		suggestion := TicketSuggestion{
+			Ticket:     payload.Issue.Key,
+			Summary:    payload.Issue.Fields.Summary,
+			IssueType:  payload.Issue.Fields.IssueType.Name,
+			ReceivedAt: time.Now(),
+		}
+		if err := queueSuggestion(suggestion); err != nil {
+			http.Error(w, "failed to queue ticket", http.StatusInternalServerError)
+			fmt.Printf("Failed to queue %s: %v\n", suggestion.Ticket, err)
+			return
+		}
+
+		fmt.Printf("%s moved to %q: %s\n", linkTicket(suggestion.Ticket), status, suggestion.Summary)
+		if notifications {
+			if err := notify(cfg, notification{
+				Event:   eventTicketReady,
+				Key:     suggestion.Ticket,
+				Title:   suggestion.Ticket + " is ready for dev",
+				Message: suggestion.Summary,
+				Ticket:  suggestion.Ticket,
+			}); err != nil {
+				fmt.Printf("Failed to send notification: %v\n", err)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// listenCmd represents the command to listen for JIRA webhooks.
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Listen for JIRA webhooks and queue tickets that are ready for development",
+	Long: `Run an HTTP server that receives JIRA issue webhooks. When a ticket is moved to
+one of the "jira.ready_statuses" (default: "Ready for Dev"), it is added to a
+queue and, unless --notify=false, you are notified (a desktop notification
+unless "notifications.events" says otherwise). The next
+time you run create-branch, queued tickets are offered as a starting point.
+
+Point a JIRA webhook for "issue updated" events at
+http://<host>:<port>/jira/webhook?secret=<jira.webhook_secret>.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		notify, _ := cmd.Flags().GetBool("notify")
+		if cfg.Jira.WebhookSecret == "" {
+			fmt.Println("Warning: no jira.webhook_secret configured; requests will not be verified.")
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/jira/webhook", jiraWebhookHandler(cfg, notify))
+
+		fmt.Printf("Listening for JIRA webhooks on %s/jira/webhook\n", addr)
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		return serveUntilCancelled(server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listenCmd)
+	listenCmd.Flags().String("addr", ":8081", "Address to listen on")
+	listenCmd.Flags().Bool("notify", true, "Send a notification for each queued ticket")
+}
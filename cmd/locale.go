@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocaleConfig sets how reports format dates and numbers and on which day
+// weekly reports start.
+type LocaleConfig struct {
+	// Name is one of the supported locales, e.g. "en-GB" or "de-DE".
+	Name string `json:"name,omitempty"`
+	// WeekStart overrides the locale's first day of the week, e.g. "sunday".
+	WeekStart string `json:"week_start,omitempty"`
+}
+
+// localeFormat holds the formatting conventions of a locale.
+type localeFormat struct {
+	date      string
+	dateTime  string
+	decimal   string
+	thousands string
+	weekStart time.Weekday
+}
+
+// defaultLocale formats dates as ISO 8601 and starts weeks on Monday.
+var defaultLocale = localeFormat{"2006-01-02", "2006-01-02 15:04", ".", "", time.Monday}
+
+// locales are the supported locales by name.
+var locales = map[string]localeFormat{
+	"en-US": {"01/02/2006", "01/02/2006 3:04 PM", ".", ",", time.Sunday},
+	"en-GB": {"02/01/2006", "02/01/2006 15:04", ".", ",", time.Monday},
+	"en-IN": {"02/01/2006", "02/01/2006 15:04", ".", ",", time.Monday},
+	"de-DE": {"02.01.2006", "02.01.2006 15:04", ",", ".", time.Monday},
+	"fr-FR": {"02/01/2006", "02/01/2006 15:04", ",", " ", time.Monday},
+	"es-ES": {"02/01/2006", "02/01/2006 15:04", ",", ".", time.Monday},
+	"ja-JP": {"2006/01/02", "2006/01/02 15:04", ".", ",", time.Sunday},
+	"ar-AE": {"02/01/2006", "02/01/2006 15:04", ".", ",", time.Saturday},
+}
+
+// reportLocale is the locale in effect, resolved from the config before any command runs.
+var reportLocale = defaultLocale
+
+// configureLocale sets the report locale from the config.
+func configureLocale(cfg LocaleConfig) error {
+	reportLocale = defaultLocale
+	if cfg.Name != "" {
+		l, ok := locales[cfg.Name]
+		if !ok {
+			names := make([]string, 0, len(locales))
+			for name := range locales {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("unknown locale.name '%s'; use one of %s", cfg.Name, strings.Join(names, ", "))
+		}
+		reportLocale = l
+	}
+	if cfg.WeekStart != "" {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(cfg.WeekStart, d.String()) {
+				reportLocale.weekStart = d
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid locale.week_start '%s': expected a day such as \"monday\"", cfg.WeekStart)
+	}
+	return nil
+}
+
+// formatDate formats the date of t for reports.
+func formatDate(t time.Time) string {
+	return t.Format(reportLocale.date)
+}
+
+// formatDateTime formats the date and time of t for reports.
+func formatDateTime(t time.Time) string {
+	return t.Format(reportLocale.dateTime)
+}
+
+// formatNumber formats an integer with the locale's thousands separator.
+func formatNumber(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if reportLocale.thousands == "" {
+		return sign + digits
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(reportLocale.thousands)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// formatPercent formats a percentage with the given number of decimals and
+// the locale's decimal separator.
+func formatPercent(value float64, decimals int) string {
+	s := strconv.FormatFloat(value, 'f', decimals, 64)
+	return strings.Replace(s, ".", reportLocale.decimal, 1) + "%"
+}
+
+// startOfWeek returns midnight on the first day of the week containing t.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(reportLocale.weekStart) + 7) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BranchMetadata holds what the helper knows about a branch beyond its name.
+type BranchMetadata struct {
+	Ticket      string  `json:"ticket,omitempty"`
+	AdoptedFrom string  `json:"adopted_from,omitempty"`
+	PR          *PRLink `json:"pr,omitempty"`
+	// AutoUpdate keeps the branch up to date with its base, see `pr auto-update`.
+	AutoUpdate bool      `json:"auto_update,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// ExpiresAt is when a spike branch, created with `create-branch --spike`, expires.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// MergedAt, ClosedAt and RemoteDeletedAt are recorded by `ingest` from provider webhooks.
+	MergedAt        time.Time `json:"merged_at,omitzero"`
+	ClosedAt        time.Time `json:"closed_at,omitzero"`
+	RemoteDeletedAt time.Time `json:"remote_deleted_at,omitzero"`
+	// Fields holds the answers to the custom steps of create-branch, see "prompts".
+	Fields map[string]string `json:"fields,omitempty"`
+	// Env is the affected environment chosen in create-branch.
+	Env string `json:"env,omitempty"`
+	// Incident marks branches created by `incident`, whose changes are audited.
+	Incident bool `json:"incident,omitempty"`
+	// Repo is the origin repository as host/path, e.g. github.com/org/app,
+	// which identifies the branch's repository in a shared store.
+	Repo string `json:"repo,omitempty"`
+}
+
+// PairSession records a pair-programming session started with `pair start`.
+type PairSession struct {
+	Partner   string    `json:"partner"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitzero"`
+}
+
+// TicketSuggestion is a ticket that is ready for work, queued by `listen`.
+type TicketSuggestion struct {
+	Ticket     string    `json:"ticket"`
+	Summary    string    `json:"summary"`
+	IssueType  string    `json:"issue_type,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Metadata is the local metadata store, keyed by repository and branch name.
+type Metadata struct {
+	Repos map[string]map[string]BranchMetadata `json:"repos"`
+	// Pair is the active pairing session, if any.
+	Pair *PairSession `json:"pair,omitempty"`
+	// PairHistory holds the finished pairing sessions.
+	PairHistory []PairSession `json:"pair_history,omitempty"`
+	// Suggestions are tickets waiting for a branch, offered by create-branch.
+	Suggestions []TicketSuggestion `json:"suggestions,omitempty"`
+	// Todos holds the TODO items of each ticket.
+	Todos map[string][]TodoItem `json:"todos,omitempty"`
+	// Notifications remembers recently sent notifications, to send each only once.
+	Notifications []SentNotification `json:"notifications,omitempty"`
+}
+
+// metadataFilePath returns the path to the metadata file next to the config file.
+func metadataFilePath() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "metadata.json"), nil
+}
+
+// loadMetadata reads the metadata from the configured store.
+func loadMetadata() (Metadata, error) {
+	md, err := store.load()
+	if md.Repos == nil {
+		md.Repos = map[string]map[string]BranchMetadata{}
+	}
+	return md, err
+}
+
+// saveMetadata writes the metadata to the configured store, unless in
+// read-only mode.
+func saveMetadata(md Metadata) error {
+	if skipReadOnly("save branch metadata") {
+		return nil
+	}
+	return store.save(md)
+}
+
+// repoKey identifies the current repository in the metadata store by its
+// top-level path. The configuration, metadata and audit trail all need it, so
+// git is only asked once per run.
+func repoKey() (string, error) {
+	repoRoot.Do(func() {
+		if root, ok := workTreeRoot(); ok {
+			repoRoot.path = root
+			return
+		}
+		repoRoot.path, repoRoot.err = gitOutput("rev-parse", "--show-toplevel")
+	})
+	return repoRoot.path, repoRoot.err
+}
+
+// workTreeRoot returns the working directory if it is the top level of a
+// repository, which is where git runs hooks, without starting git. Recordings
+// keep the git call, so they replay anywhere.
+func workTreeRoot() (string, bool) {
+	if os.Getenv("GIT_DIR") != "" || os.Getenv("GIT_WORK_TREE") != "" || recordFile != nil || replayPath != "" {
+		return "", false
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err != nil {
+		return "", false
+	}
+	// Like git, report the path with symlinks resolved.
+	dir, err = filepath.EvalSymlinks(dir)
+	return dir, err == nil
+}
+
+// repoRoot is the result of repoKey.
+var repoRoot struct {
+	sync.Once
+	path string
+	err  error
+}
+
+// branchPR returns the recorded pull request of a branch of the current
+// repository, or nil if it has none.
+func branchPR(branch string) *PRLink {
+	repo, err := repoKey()
+	if err != nil {
+		return nil
+	}
+	md, err := loadMetadata()
+	if err != nil {
+		return nil
+	}
+	return md.Repos[repo][branch].PR
+}
+
+// recordBranch stores metadata for a branch of the current repository.
+func recordBranch(branch string, meta BranchMetadata) error {
+	repo, err := repoKey()
+	if err != nil {
+		return err
+	}
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	if md.Repos[repo] == nil {
+		md.Repos[repo] = map[string]BranchMetadata{}
+	}
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = time.Now()
+	}
+	if meta.Repo == "" {
+		meta.Repo, _ = originRepo(repo)
+	}
+	md.Repos[repo][branch] = meta
+	return saveMetadata(md)
+}
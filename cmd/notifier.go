@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification channels.
+const (
+	channelDesktop = "desktop"
+	channelSlack   = "slack"
+	channelJira    = "jira"
+)
+
+// Notification events and the channels they go to unless configured otherwise.
+const (
+	eventTicketReady      = "ticket-ready"
+	eventPRMerged         = "pr-merged"
+	eventAutoUpdateFailed = "auto-update-failed"
+)
+
+var defaultEventChannels = map[string][]string{
+	eventTicketReady:      {channelDesktop},
+	eventPRMerged:         {channelDesktop},
+	eventAutoUpdateFailed: {channelDesktop},
+}
+
+// defaultMaxNotificationsPerHour caps each channel unless notifications.max_per_hour is set.
+const defaultMaxNotificationsPerHour = 10
+
+// notificationHistoryAge is how long sent notifications are remembered for deduplication.
+const notificationHistoryAge = 30 * 24 * time.Hour
+
+// NotificationsConfig routes the helper's notifications to channels.
+type NotificationsConfig struct {
+	// Events sets the channels ("desktop", "slack", "jira") of each event, e.g.
+	// {"pr-merged": ["slack", "jira"]}; an empty list turns an event off.
+	Events map[string][]string `json:"events,omitempty"`
+	// SlackWebhook is the incoming webhook URL the "slack" channel posts to.
+	SlackWebhook string `json:"slack_webhook,omitempty"`
+	// QuietHours holds back desktop and Slack notifications during a daily
+	// window in local time, e.g. "22:00-08:00". JIRA comments are still posted.
+	QuietHours string `json:"quiet_hours,omitempty"`
+	// MaxPerHour caps the notifications sent to each channel in any hour.
+	MaxPerHour int `json:"max_per_hour,omitempty"`
+}
+
+// SentNotification records a notification delivered to a channel.
+type SentNotification struct {
+	Event   string    `json:"event"`
+	Key     string    `json:"key"`
+	Channel string    `json:"channel"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// notification is a message about an event. Key identifies what it is about,
+// such as a pull request URL, so the same thing is only notified once per
+// channel; Ticket is the JIRA issue the "jira" channel comments on.
+type notification struct {
+	Event   string
+	Key     string
+	Title   string
+	Message string
+	Ticket  string
+}
+
+// notifyMu serialises notifications from concurrent webhook handlers, which
+// share the history in the metadata store.
+var notifyMu sync.Mutex
+
+// channels returns the channels of an event.
+func (c NotificationsConfig) channels(event string) []string {
+	if channels, ok := c.Events[event]; ok {
+		return channels
+	}
+	return defaultEventChannels[event]
+}
+
+// inQuietHours reports whether t falls in the configured quiet hours.
+func (c NotificationsConfig) inQuietHours(t time.Time) (bool, error) {
+	if c.QuietHours == "" {
+		return false, nil
+	}
+	from, to, ok := strings.Cut(c.QuietHours, "-")
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if !ok || err1 != nil || err2 != nil {
+		return false, fmt.Errorf("invalid notifications.quiet_hours '%s': expected a range such as \"22:00-08:00\"", c.QuietHours)
+	}
+	minute := t.Hour()*60 + t.Minute()
+	startMin, endMin := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if startMin <= endMin {
+		return minute >= startMin && minute < endMin, nil
+	}
+	// The window spans midnight.
+	return minute >= startMin || minute < endMin, nil
+}
+
+// notify sends a notification to the channels of its event, skipping
+// channels it was already sent to, channels over their hourly limit and,
+// during quiet hours, desktop and Slack. Failures of individual channels are
+// returned together after the others have been tried.
+func notify(cfg Config, n notification) error {
+	channels := cfg.Notifications.channels(n.Event)
+	if len(channels) == 0 {
+		return nil
+	}
+	quiet, err := cfg.Notifications.inQuietHours(time.Now())
+	if err != nil {
+		return err
+	}
+	limit := cfg.Notifications.MaxPerHour
+	if limit <= 0 {
+		limit = defaultMaxNotificationsPerHour
+	}
+
+	notifyMu.Lock()
+	defer notifyMu.Unlock()
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	md.Notifications = slices.DeleteFunc(md.Notifications, func(s SentNotification) bool {
+		return now.Sub(s.SentAt) > notificationHistoryAge
+	})
+
+	var failed []string
+	for _, channel := range channels {
+		if slices.ContainsFunc(md.Notifications, func(s SentNotification) bool {
+			return s.Event == n.Event && s.Key == n.Key && s.Channel == channel
+		}) {
+			continue
+		}
+		if quiet && channel != channelJira {
+			fmt.Printf("Quiet hours: not sending %s notification '%s'.\n", channel, n.Title)
+			continue
+		}
+		recent := 0
+		for _, s := range md.Notifications {
+			if s.Channel == channel && now.Sub(s.SentAt) < time.Hour {
+				recent++
+			}
+		}
+		if recent >= limit {
+			fmt.Printf("Rate limit of %d %s notifications per hour reached: dropping '%s'.\n", limit, channel, n.Title)
+			continue
+		}
+		if err := sendNotification(cfg, channel, n); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", channel, err))
+			continue
+		}
+		md.Notifications = append(md.Notifications, SentNotification{Event: n.Event, Key: n.Key, Channel: channel, SentAt: now})
+	}
+	// In read-only mode nothing was sent, so nothing is remembered.
+	if !readOnly {
+		if err := saveMetadata(md); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to notify %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// sendNotification delivers a notification to one channel.
+func sendNotification(cfg Config, channel string, n notification) error {
+	switch channel {
+	case channelDesktop:
+		return notifyDesktop(n.Title, n.Message)
+	case channelSlack:
+		return postSlackNotification(cfg.Notifications.SlackWebhook, n)
+	case channelJira:
+		if n.Ticket == "" {
+			return nil
+		}
+		client, err := newJiraClient(cfg)
+		if err != nil {
+			return err
+		}
+		return client.comment(n.Ticket, n.Title+"\n\n"+n.Message)
+	default:
+		return fmt.Errorf("unknown channel '%s'; use desktop, slack or jira", channel)
+	}
+}
+
+// postSlackNotification posts a notification to a Slack incoming webhook.
+func postSlackNotification(webhook string, n notification) error {
+	if webhook == "" {
+		return fmt.Errorf("no notifications.slack_webhook configured")
+	}
+	if skipReadOnly("post '" + n.Title + "' to Slack") {
+		return nil
+	}
+	body, err := json.Marshal(map[string]string{"text": "*" + n.Title + "*\n" + n.Message})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return explainCancel(err, "POST Slack webhook", apiTimeout)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Slack webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// comment adds a comment to a ticket.
+func (c *jiraClient) comment(key, body string) error {
+	return c.do(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// notifyDesktop shows a desktop notification where the platform supports it.
+func notifyDesktop(title, message string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "linux":
+		c = exec.Command("notify-send", title, message)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	return c.Run()
+}
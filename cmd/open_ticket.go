@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// openBrowser opens a URL in the default browser.
+func openBrowser(url string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+	return c.Run()
+}
+
+// openTicketCmd represents the command to open a JIRA issue in the browser.
+var openTicketCmd = &cobra.Command{
+	Use:   "open-ticket [TICKET]",
+	Short: "Open the JIRA issue of the current branch, or of any ticket, in the browser",
+	Long: `Open the JIRA issue of the current branch's ticket in the default browser, or
+the one of the ticket given, e.g. 'gh open-ticket CPRE-11347'. The link is
+built from jira.base_url in the config, and printed when no browser can be
+opened, e.g. over SSH.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeTicketArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		var ticketID string
+		if len(args) == 1 {
+			ticketID = args[0]
+		} else {
+			branch, err := getCurrentBranch()
+			if err != nil {
+				return err
+			}
+			if ticketID, err = extractTicketFromBranch(branch); err != nil {
+				return fmt.Errorf("no ticket found in branch '%s'; give one, e.g. 'gh open-ticket ABC-123'", branch)
+			}
+		}
+		ticketID = strings.ToUpper(ticketID)
+		if err := convention.ValidateTicketID(ticketID); err != nil {
+			return err
+		}
+		url := ticketURL(cfg, ticketID)
+		if url == "" {
+			return fmt.Errorf("no JIRA site configured; add \"jira\": {\"base_url\": \"https://<site>.atlassian.net\"} to the config file")
+		}
+		if err := openBrowser(url); err != nil {
+			fmt.Printf("Could not open a browser (%v); open %s\n", err, url)
+			return nil
+		}
+		fmt.Printf("Opened %s\n", url)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openTicketCmd)
+}
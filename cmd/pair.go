@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// coAuthorPattern matches the "Name <email>" form used in Co-authored-by trailers.
+var coAuthorPattern = regexp.MustCompile(`^[^<>]+ <[^<>@\s]+@[^<>\s]+>$`)
+
+// coAuthorKey is the key of the trailers crediting co-authors.
+const coAuthorKey = "Co-authored-by"
+
+// coAuthorTrailer formats a Co-authored-by trailer for the given partner.
+func coAuthorTrailer(partner string) string {
+	return coAuthorKey + ": " + partner
+}
+
+// Collaborator is someone you often commit with, offered as a co-author by
+// create-commit.
+type Collaborator struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// String returns the collaborator as "Name <email>".
+func (c Collaborator) String() string {
+	return fmt.Sprintf("%s <%s>", c.Name, c.Email)
+}
+
+// resolveCoAuthor turns a --co-author value into "Name <email>". It is either
+// given in that form or the name or e-mail address of a collaborator.
+func resolveCoAuthor(cfg Config, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if coAuthorPattern.MatchString(value) {
+		return value, nil
+	}
+	for _, c := range cfg.Collaborators {
+		if strings.EqualFold(c.Name, value) || strings.EqualFold(c.Email, value) {
+			return c.String(), nil
+		}
+	}
+	return "", fmt.Errorf("co-author must look like 'Name <email>' or be the name or e-mail address of one of the collaborators in the config, got '%s'", value)
+}
+
+// coAuthorStep returns the create-commit step picking co-authors from the
+// collaborators and previous, the co-authors of an amended commit. The
+// answer holds one co-author per line, or noChoice. ok is false when there is
+// nobody to pick.
+func coAuthorStep(cfg Config, session *flowSession, coAuthors *string, previous []string) (step flowStep, ok bool) {
+	// The pairing partner is credited anyway.
+	partner, _ := activePair()
+	var options []string
+	for _, c := range cfg.Collaborators {
+		if !coAuthorPattern.MatchString(c.String()) {
+			fmt.Printf("Warning: collaborator %q is not offered as a co-author; it needs a name and an e-mail address.\n", c.String())
+			continue
+		}
+		options = append(options, c.String())
+	}
+	for _, p := range previous {
+		if !slices.Contains(options, p) {
+			options = append(options, p)
+		}
+	}
+	options = slices.DeleteFunc(options, func(o string) bool { return o == partner })
+	if len(options) == 0 {
+		return flowStep{}, false
+	}
+	ask := func(back bool) error {
+		prompt := &survey.MultiSelect{Message: "Co-authors (optional):", Options: options}
+		if back {
+			prompt.Options = append(slices.Clone(options), backOption)
+		}
+		if *coAuthors != "" && *coAuthors != noChoice {
+			prompt.Default = strings.Split(*coAuthors, "\n")
+		}
+		var picked []string
+		if err := askOne(prompt, &picked); err != nil {
+			return err
+		}
+		if slices.Contains(picked, backOption) {
+			return errGoBack
+		}
+		*coAuthors = strings.Join(picked, "\n")
+		if len(picked) == 0 {
+			*coAuthors = noChoice
+		}
+		return nil
+	}
+	return flowStep{name: "co-authors", value: coAuthors, ask: session.step("co-authors", coAuthors, ask), optional: true}, true
+}
+
+// coAuthorTrailers returns a Co-authored-by trailer for each co-author,
+// leaving out repeats and the pairing partner, whom commitMessages credits.
+func coAuthorTrailers(coAuthors []string) []convention.Trailer {
+	partner, _ := activePair()
+	var trailers []convention.Trailer
+	seen := map[string]bool{partner: true}
+	for _, c := range coAuthors {
+		if c == "" || c == noChoice || seen[c] {
+			continue
+		}
+		seen[c] = true
+		trailers = append(trailers, convention.Trailer{Key: coAuthorKey, Value: c})
+	}
+	return trailers
+}
+
+// activePair returns the partner of the active pairing session, or "" if there is none.
+func activePair() (string, error) {
+	md, err := loadMetadata()
+	if err != nil {
+		return "", err
+	}
+	if md.Pair == nil {
+		return "", nil
+	}
+	return md.Pair.Partner, nil
+}
+
+// pairCmd represents the pair command, which shows the active pairing session.
+var pairCmd = &cobra.Command{
+	Use:   "pair",
+	Short: "Show or manage the active pair-programming session",
+	Long: `While a pairing session is active, every commit created with create-commit
+gets a Co-authored-by trailer for your partner.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		if md.Pair == nil {
+			fmt.Println("No active pairing session. Start one with 'git-helper-cli pair start <name>'.")
+			return nil
+		}
+		fmt.Printf("Pairing with %s since %s.\n", md.Pair.Partner, md.Pair.StartedAt.Format(time.Kitchen))
+		return nil
+	},
+}
+
+// pairStartCmd represents the command to start a pairing session.
+var pairStartCmd = &cobra.Command{
+	Use:   "start <name>",
+	Short: "Start pairing; commits get a Co-authored-by trailer for your partner",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		partner := strings.Join(args, " ")
+
+		// Ask for the e-mail address if only a name was given.
+		if !coAuthorPattern.MatchString(partner) {
+			var email string
+			if err := askOne(&survey.Input{
+				Message: fmt.Sprintf("Enter %s's e-mail address:", partner),
+			}, &email, survey.WithValidator(survey.Required)); err != nil {
+				return err
+			}
+			partner = fmt.Sprintf("%s <%s>", partner, strings.TrimSpace(email))
+			if !coAuthorPattern.MatchString(partner) {
+				return fmt.Errorf("partner must look like 'Name <email>', got '%s'", partner)
+			}
+		}
+
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		if md.Pair != nil {
+			return fmt.Errorf("already pairing with %s. Run 'git-helper-cli pair stop' first", md.Pair.Partner)
+		}
+		md.Pair = &PairSession{Partner: partner, StartedAt: time.Now()}
+		if err := saveMetadata(md); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+
+		fmt.Printf("Pairing with %s. Commits will include:\n  %s\n", partner, coAuthorTrailer(partner))
+		return nil
+	},
+}
+
+// pairStopCmd represents the command to stop the active pairing session.
+var pairStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the active pairing session",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		if md.Pair == nil {
+			fmt.Println("No active pairing session.")
+			return nil
+		}
+
+		session := *md.Pair
+		session.EndedAt = time.Now()
+		md.PairHistory = append(md.PairHistory, session)
+		md.Pair = nil
+		if err := saveMetadata(md); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+
+		fmt.Printf("Stopped pairing with %s after %s.\n", session.Partner, session.EndedAt.Sub(session.StartedAt).Round(time.Minute))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pairCmd)
+	pairCmd.AddCommand(pairStartCmd)
+	pairCmd.AddCommand(pairStopCmd)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// fuzzyMatch reports whether all characters of pattern appear in s in order,
+// ignoring case. An empty pattern matches everything.
+func fuzzyMatch(pattern, s string) bool {
+	runes := []rune(strings.ToLower(pattern))
+	i := 0
+	for _, r := range strings.ToLower(s) {
+		if i == len(runes) {
+			break
+		}
+		if r == runes[i] {
+			i++
+		}
+	}
+	return i == len(runes)
+}
+
+// paletteCommands returns the subcommands that can be launched from the palette.
+func paletteCommands(root *cobra.Command) []*cobra.Command {
+	var cmds []*cobra.Command
+	for _, c := range root.Commands() {
+		if c.Hidden || !c.IsAvailableCommand() || c.Name() == "help" || c.Name() == "completion" {
+			continue
+		}
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+// runPalette presents a fuzzy-searchable list of commands and runs the chosen one.
+func runPalette(root *cobra.Command) error {
+	// Without a terminal there is nothing to pick from, so fall back to the help text.
+	if !stdinIsTerminal() {
+		return root.Help()
+	}
+
+	cmds := paletteCommands(root)
+	options := make([]string, len(cmds))
+	width := 0
+	for _, c := range cmds {
+		if len(c.Name()) > width {
+			width = len(c.Name())
+		}
+	}
+	for i, c := range cmds {
+		options[i] = fmt.Sprintf("%-*s  %s", width, c.Name(), c.Short)
+	}
+
+	var index int
+	prompt := &survey.Select{
+		Message:  "What would you like to do?",
+		Options:  options,
+		PageSize: 15,
+		Filter: func(filter string, value string, _ int) bool {
+			return fuzzyMatch(filter, value)
+		},
+	}
+	if err := askOne(prompt, &index); err != nil {
+		return err
+	}
+	chosen := cmds[index]
+
+	// Parent commands such as "config" open a palette of their own subcommands.
+	if !chosen.Runnable() && chosen.HasAvailableSubCommands() {
+		return runPalette(chosen)
+	}
+
+	// Commands that take positional arguments advertise them in their usage line.
+	var args []string
+	if strings.Contains(chosen.Use, " ") {
+		var raw string
+		if err := askOne(&survey.Input{
+			Message: fmt.Sprintf("Arguments for %s:", chosen.Use),
+		}, &raw); err != nil {
+			return err
+		}
+		args = strings.Fields(raw)
+	}
+
+	// The root command's persistent hooks already ran when the palette opened,
+	// so the chosen command is run directly rather than through Execute, which
+	// would load the configuration and resume the audit trail a second time.
+	top := root.Root()
+	top.SilenceUsage = true
+	chosen.SetContext(top.Context())
+	if err := chosen.ParseFlags(args); err != nil {
+		return fmt.Errorf("%s: %w", chosen.CommandPath(), err)
+	}
+	args = chosen.Flags().Args()
+	if err := chosen.ValidateArgs(args); err != nil {
+		return fmt.Errorf("%s: %w", chosen.CommandPath(), err)
+	}
+	if err := chosen.ValidateRequiredFlags(); err != nil {
+		return fmt.Errorf("%s: %w", chosen.CommandPath(), err)
+	}
+	return runChosen(chosen, args)
+}
+
+// runChosen runs the pre-run, run and post-run functions of a command,
+// without the persistent ones of its parents.
+func runChosen(cmd *cobra.Command, args []string) error {
+	if cmd.PreRunE != nil {
+		if err := cmd.PreRunE(cmd, args); err != nil {
+			return err
+		}
+	} else if cmd.PreRun != nil {
+		cmd.PreRun(cmd, args)
+	}
+	if cmd.RunE != nil {
+		if err := cmd.RunE(cmd, args); err != nil {
+			return err
+		}
+	} else {
+		cmd.Run(cmd, args)
+	}
+	if cmd.PostRunE != nil {
+		return cmd.PostRunE(cmd, args)
+	} else if cmd.PostRun != nil {
+		cmd.PostRun(cmd, args)
+	}
+	return nil
+}
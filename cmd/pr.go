@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Supported hosting providers.
+const (
+	providerGitHub          = "github"
+	providerGitLab          = "gitlab"
+	providerBitbucketServer = "bitbucket-server"
+	providerBitbucketCloud  = "bitbucket-cloud"
+)
+
+// prHeadRefs maps a provider to the remote ref holding a pull request's head commit.
+var prHeadRefs = map[string]string{
+	providerGitHub:          "refs/pull/%d/head",
+	providerGitLab:          "refs/merge-requests/%d/head",
+	providerBitbucketServer: "refs/pull-requests/%d/from",
+}
+
+// prURLPattern extracts the number from pull/merge request URLs of all providers.
+var prURLPattern = regexp.MustCompile(`/(?:pull|pulls|merge_requests|pull-requests)/(\d+)`)
+
+// PRLink records which pull request a local branch belongs to.
+type PRLink struct {
+	Provider string `json:"provider"`
+	Number   int    `json:"number"`
+	URL      string `json:"url,omitempty"`
+}
+
+// String names the pull request the way its provider does, e.g. "merge request !12" on GitLab.
+func (l PRLink) String() string {
+	if l.Provider == providerGitLab {
+		return fmt.Sprintf("merge request !%d", l.Number)
+	}
+	return fmt.Sprintf("pull request #%d", l.Number)
+}
+
+// detectProvider works out the hosting provider from a remote URL. The
+// "provider" config setting overrides detection for self-hosted servers.
+func detectProvider(cfg Config, remoteURL string) string {
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	switch {
+	case strings.Contains(remoteURL, "github"):
+		return providerGitHub
+	case strings.Contains(remoteURL, "gitlab"):
+		return providerGitLab
+	case strings.Contains(remoteURL, "bitbucket.org"):
+		return providerBitbucketCloud
+	case strings.Contains(remoteURL, "bitbucket") || strings.Contains(remoteURL, "/scm/"):
+		return providerBitbucketServer
+	}
+	return ""
+}
+
+// originProvider returns the hosting provider of the origin remote.
+func originProvider(cfg Config) (string, error) {
+	url, err := gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	provider := detectProvider(cfg, url)
+	if provider == "" {
+		return "", fmt.Errorf("could not detect the hosting provider of '%s'; set \"provider\" in the config file", url)
+	}
+	return provider, nil
+}
+
+// parsePRArg accepts a pull request number or URL and returns the number.
+func parsePRArg(arg string) (int, error) {
+	if n, err := strconv.Atoi(strings.TrimPrefix(arg, "#")); err == nil && n > 0 {
+		return n, nil
+	}
+	if m := prURLPattern.FindStringSubmatch(arg); m != nil {
+		return strconv.Atoi(m[1])
+	}
+	return 0, fmt.Errorf("'%s' is not a pull request number or URL", arg)
+}
+
+// prCmd groups the pull request commands.
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Work with pull requests",
+}
+
+// prCheckoutCmd represents the command to check out a pull request locally.
+var prCheckoutCmd = &cobra.Command{
+	Use:   "checkout <number-or-url>",
+	Short: "Check out a pull request into a local branch",
+	Long: `Fetch the head of a pull request (GitHub), merge request (GitLab) or pull
+request (Bitbucket Server) from origin into a local branch and switch to it.
+
+The branch is named <abbreviation>-pr-<number>/<TICKET> when a JIRA ticket is
+found in the pull request's commits, or pr-<number> otherwise, and remembers
+which pull request it belongs to. Running it again fast-forwards the branch to
+the pull request; a branch with local commits the pull request lacks is never
+reset.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		number, err := parsePRArg(args[0])
+		if err != nil {
+			return err
+		}
+		provider, err := originProvider(cfg)
+		if err != nil {
+			return err
+		}
+		headRef, ok := prHeadRefs[provider]
+		if !ok {
+			return fmt.Errorf("checking out pull requests is not supported for %s", provider)
+		}
+
+		// 1. Fetch the pull request head.
+		if err := runGit("fetch", "origin", fmt.Sprintf(headRef, number)); err != nil {
+			return fmt.Errorf("failed to fetch pull request #%d: %w", number, err)
+		}
+
+		// 2. Name the branch after the ticket found in its commits.
+		branchName := fmt.Sprintf("pr-%d", number)
+		ticketID := ""
+		if messages, err := gitOutput("log", "--format=%B", "-n", "20", "FETCH_HEAD"); err == nil {
+			ticketID = convention.TicketRefPattern.FindString(messages)
+		}
+		if ticketID != "" && cfg.Abbreviation != "" {
+			branchName = fmt.Sprintf("%s-pr-%d/%s", strings.ToLower(cfg.Abbreviation), number, ticketID)
+		}
+
+		// 3. Create or fast-forward the local branch and switch to it. A local
+		// branch with commits the pull request lacks is left alone.
+		if refExists("", "refs/heads/"+branchName) && !isAncestor("", "refs/heads/"+branchName, "FETCH_HEAD") {
+			return fmt.Errorf("local branch '%s' has commits that are not in pull request #%d; push, move or delete them and run the command again", branchName, number)
+		}
+		if err := runGit("checkout", "-B", branchName, "FETCH_HEAD"); err != nil {
+			return fmt.Errorf("failed to check out pull request #%d: %w", number, err)
+		}
+
+		link := &PRLink{Provider: provider, Number: number}
+		if strings.Contains(args[0], "://") {
+			link.URL = args[0]
+		}
+		if err := recordBranch(branchName, BranchMetadata{Ticket: ticketID, PR: link}); err != nil {
+			fmt.Printf("Warning: failed to record branch metadata: %v\n", err)
+		}
+
+		enteredBranch(cfg, branchName)
+		fmt.Printf("Checked out pull request #%d as '%s'.\n", number, branchName)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prCheckoutCmd)
+}
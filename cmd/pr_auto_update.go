@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// githubPull is the part of a GitHub pull request the helper uses.
+type githubPull struct {
+	Number  int    `json:"number"`
+	NodeID  string `json:"node_id"`
+	HTMLURL string `json:"html_url"`
+	Base    struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// findOpenGitHubPull returns the open pull request for a branch, or nil if there is none.
+func findOpenGitHubPull(client *githubClient, owner, repo, branch string) (*githubPull, error) {
+	var pulls []githubPull
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=open&head=%s", owner, repo, url.QueryEscape(owner+":"+branch))
+	if err := client.get(path, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return &pulls[0], nil
+}
+
+// graphql runs a GraphQL query against the GitHub API.
+func (c *githubClient) graphql(query string, variables map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	// GitHub Enterprise serves GraphQL at /api/graphql next to the REST API at /api/v3.
+	endpoint := strings.TrimSuffix(strings.TrimSuffix(c.apiURL, "/"), "/v3") + "/graphql"
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.do(http.MethodPost, endpoint, "application/json", bytes.NewReader(body), &result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%s", result.Errors[0].Message)
+	}
+	return nil
+}
+
+// setGitHubAutoMerge enables or disables auto-merge of a pull request.
+func setGitHubAutoMerge(client *githubClient, pull *githubPull, enable bool) error {
+	mutation := `mutation($id: ID!) { disablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId } }`
+	if enable {
+		mutation = `mutation($id: ID!) { enablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId } }`
+	}
+	return client.graphql(mutation, map[string]interface{}{"id": pull.NodeID})
+}
+
+// isAncestor reports whether commit a is an ancestor of commit b in the repository.
+func isAncestor(repo, a, b string) bool {
+	return gitRun("-C", repo, "merge-base", "--is-ancestor", a, b) == nil
+}
+
+// rebaseAndPush rebases a branch of a repository onto base and force-pushes
+// it, as long as origin still has it at lease. The rebase happens in a
+// temporary worktree, so a branch that is checked out is left alone: its
+// working tree and index belong to the user.
+func rebaseAndPush(repo, branch, base, lease string) error {
+	worktrees, err := gitOutputIn(repo, "worktree", "list", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	if slices.Contains(strings.Split(worktrees, "\n"), "branch refs/heads/"+branch) {
+		return fmt.Errorf("'%s' is checked out; rebase it onto %s yourself or switch to another branch", branch, base)
+	}
+
+	tmp, err := os.MkdirTemp("", "git-helper-rebase-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	// The worktree checks out the branch itself, so the rebase moves the local branch.
+	if _, err := gitOutputIn(repo, "worktree", "add", "--quiet", tmp, branch); err != nil {
+		return fmt.Errorf("failed to create a worktree for '%s': %w", branch, err)
+	}
+	defer gitOutputIn(repo, "worktree", "remove", "--force", tmp)
+
+	if _, err := gitOutputIn(tmp, "rebase", base); err != nil {
+		gitOutputIn(tmp, "rebase", "--abort")
+		return fmt.Errorf("'%s' does not rebase cleanly onto %s; rebase it manually", branch, base)
+	}
+	// An explicit lease fails the push if anyone pushed to the branch since
+	// it was last fetched, instead of overwriting their commits.
+	if _, err := gitOutputIn(tmp, "push", "--force-with-lease="+branch+":"+lease, "origin", branch); err != nil {
+		return fmt.Errorf("failed to push '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// autoUpdateBranch brings a branch up to date with its base when the base has
+// moved. Open GitHub pull requests are updated by GitHub; on other providers
+// the branch is rebased locally and force-pushed. keep is false when there is
+// no open pull request to keep up to date, or no provider to ask, so the
+// branch should be opted out.
+func autoUpdateBranch(cfg Config, repo, branch string) (keep bool, err error) {
+	remote, err := gitOutputIn(repo, "remote", "get-url", "origin")
+	if err != nil {
+		return true, fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	provider := detectProvider(cfg, remote)
+	if m := githubRemotePattern.FindStringSubmatch(remote); m != nil && provider == providerGitHub {
+		client, err := newGitHubClient(cfg)
+		if err != nil {
+			return false, nil
+		}
+		pull, err := findOpenGitHubPull(client, m[1], m[2], branch)
+		if err != nil {
+			return true, err
+		}
+		if pull == nil {
+			return false, nil
+		}
+		if _, err := gitOutputIn(repo, "fetch", "--quiet", "origin"); err != nil {
+			return true, fmt.Errorf("failed to fetch: %w", err)
+		}
+		if isAncestor(repo, "origin/"+pull.Base.Ref, "origin/"+branch) {
+			return true, nil
+		}
+		return true, client.do(http.MethodPut, fmt.Sprintf("%s/repos/%s/%s/pulls/%d/update-branch", client.apiURL, m[1], m[2], pull.Number),
+			"application/json", strings.NewReader("{}"), nil)
+	}
+
+	prs, err := remotePRProvider(cfg, provider, remote)
+	if err != nil {
+		return false, nil
+	}
+	pr, err := prs.findOpen(branch)
+	if err != nil {
+		return true, err
+	}
+	if pr == nil {
+		return false, nil
+	}
+
+	// The lease is where origin had the branch before fetching moves it.
+	lease, err := gitOutputIn(repo, "rev-parse", "--verify", "-q", "refs/remotes/origin/"+branch)
+	if err != nil {
+		return true, fmt.Errorf("'%s' has never been fetched from origin", branch)
+	}
+	if _, err := gitOutputIn(repo, "fetch", "--quiet", "origin"); err != nil {
+		return true, fmt.Errorf("failed to fetch: %w", err)
+	}
+	if !isAncestor(repo, "origin/"+branch, branch) {
+		return true, fmt.Errorf("'%s' is behind origin/%s; pull the new commits first", branch, branch)
+	}
+	base, err := gitOutputIn(repo, "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		return true, fmt.Errorf("failed to find the default branch of origin: %w", err)
+	}
+	if isAncestor(repo, base, branch) {
+		return true, nil
+	}
+	return true, rebaseAndPush(repo, branch, base, lease)
+}
+
+// autoUpdateBranches updates every branch that has opted in with `pr auto-update`.
+func autoUpdateBranches(cfg Config) error {
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	var failed []string
+	done := map[string][]string{}
+	for repo, branches := range md.Repos {
+		for branch, meta := range branches {
+			if !meta.AutoUpdate {
+				continue
+			}
+			keep, err := autoUpdateBranch(cfg, repo, branch)
+			if !keep {
+				done[repo] = append(done[repo], branch)
+			}
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s %s: %v", filepath.Base(repo), branch, err))
+				// Notify once per failing commit rather than on every daemon run.
+				head, _ := gitOutputIn(repo, "rev-parse", branch)
+				ticket, _ := extractTicketFromBranch(branch)
+				if err := notify(cfg, notification{
+					Event:   eventAutoUpdateFailed,
+					Key:     repo + ":" + branch + "@" + head,
+					Title:   "Could not update " + branch,
+					Message: fmt.Sprintf("%s: %v", filepath.Base(repo), err),
+					Ticket:  ticket,
+				}); err != nil {
+					fmt.Printf("Failed to send notification: %v\n", err)
+				}
+			}
+		}
+	}
+	if len(done) > 0 {
+		if err := optOutAutoUpdate(done); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// optOutAutoUpdate stops updating branches, by repository, that have no open
+// pull request left. The metadata is read again, since updating the branches
+// took a while.
+func optOutAutoUpdate(branches map[string][]string) error {
+	md, err := loadMetadata()
+	if err != nil {
+		return err
+	}
+	for repo, names := range branches {
+		for _, branch := range names {
+			if meta, ok := md.Repos[repo][branch]; ok {
+				meta.AutoUpdate = false
+				md.Repos[repo][branch] = meta
+				fmt.Printf("Stopped updating %s %s: it has no open pull request.\n", filepath.Base(repo), branch)
+			}
+		}
+	}
+	return saveMetadata(md)
+}
+
+// toggleGitHubAutoMerge enables or disables auto-merge of the open pull request of a branch.
+func toggleGitHubAutoMerge(cfg Config, branch string, enable bool) error {
+	client, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+	owner, name, err := githubRepo()
+	if err != nil {
+		return err
+	}
+	pull, err := findOpenGitHubPull(client, owner, name, branch)
+	if err != nil {
+		return err
+	}
+	if pull == nil {
+		fmt.Println("No open pull request yet; auto-merge can be enabled once there is one.")
+		return nil
+	}
+	if err := setGitHubAutoMerge(client, pull, enable); err != nil {
+		return fmt.Errorf("pull request #%d: %w", pull.Number, err)
+	}
+	if enable {
+		fmt.Printf("Auto-merge enabled for #%d.\n", pull.Number)
+	}
+	return nil
+}
+
+// prAutoUpdateCmd represents the command to keep the current branch's pull request mergeable.
+var prAutoUpdateCmd = &cobra.Command{
+	Use:   "auto-update",
+	Short: "Keep the current branch's pull request up to date with its base",
+	Long: `Opt the current branch in to automatic updates. On GitHub, auto-merge is
+enabled for its pull request and the daemon asks GitHub to update the branch
+whenever the base branch moves. Elsewhere, the daemon rebases the branch onto
+the default branch of origin and pushes it with --force-with-lease, unless
+someone else pushed to it, it is behind origin or it is checked out. Branches
+without an open pull request are opted out again.
+
+Run 'gh daemon install' so updates happen in the background. Use --off to opt
+the branch out again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		off, _ := cmd.Flags().GetBool("off")
+		branch, err := getCurrentBranch()
+		if err != nil {
+			return err
+		}
+		repo, err := repoKey()
+		if err != nil {
+			return err
+		}
+
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		meta := md.Repos[repo][branch]
+		meta.AutoUpdate = !off
+		if err := recordBranch(branch, meta); err != nil {
+			return fmt.Errorf("failed to record branch metadata: %w", err)
+		}
+
+		// Use the provider's auto-merge where available.
+		if provider, err := originProvider(cfg); err == nil && provider == providerGitHub {
+			if err := toggleGitHubAutoMerge(cfg, branch, !off); err != nil {
+				fmt.Printf("Warning: failed to change auto-merge: %v\n", err)
+			}
+		}
+
+		if off {
+			fmt.Printf("Automatic updates disabled for '%s'.\n", branch)
+			return nil
+		}
+		fmt.Printf("Automatic updates enabled for '%s'. The daemon keeps it up to date with its base.\n", branch)
+		return nil
+	},
+}
+
+func init() {
+	prCmd.AddCommand(prAutoUpdateCmd)
+	prAutoUpdateCmd.Flags().Bool("off", false, "Stop updating the branch automatically")
+	daemonTasks = append(daemonTasks, daemonTask{name: "auto-update branches", run: autoUpdateBranches})
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Default API endpoints of the hosted providers, overridable for self-hosted servers.
+const (
+	defaultGitLabAPIURL         = "https://gitlab.com/api/v4"
+	defaultBitbucketCloudAPIURL = "https://api.bitbucket.org/2.0"
+)
+
+// GitLabConfig holds the GitLab API settings.
+type GitLabConfig struct {
+	// Token is a personal access token; the GITLAB_TOKEN environment variable takes precedence.
+	Token string `json:"token,omitempty" env:"GITLAB_TOKEN"`
+	// APIURL defaults to https://<remote host>/api/v4.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// BitbucketConfig holds the Bitbucket Cloud or Server API settings.
+type BitbucketConfig struct {
+	// Username is sent with Token as an app password; without it, Token is
+	// used as a bearer (HTTP access) token.
+	Username string `json:"username,omitempty"`
+	// Token is an app password or access token; the BITBUCKET_TOKEN environment variable takes precedence.
+	Token string `json:"token,omitempty" env:"BITBUCKET_TOKEN"`
+	// APIURL defaults to https://api.bitbucket.org/2.0 for Bitbucket Cloud and
+	// https://<remote host>/rest/api/1.0 for Bitbucket Server.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// prRequest describes a pull request to open.
+type prRequest struct {
+	Branch string
+	Base   string
+	Title  string
+	Body   string
+	Draft  bool
+}
+
+// prProvider opens pull requests (merge requests on GitLab) on a hosting provider.
+type prProvider interface {
+	// findOpen returns the open pull request of a branch, or nil if there is none.
+	findOpen(branch string) (*PRLink, error)
+	// create opens a pull request.
+	create(req prRequest) (*PRLink, error)
+}
+
+// parseRemoteURL splits a remote URL such as git@host:group/repo.git,
+// ssh://git@host:7999/proj/repo.git or https://host/scm/proj/repo.git into its
+// host and repository path, without the port and .git suffix.
+func parseRemoteURL(remote string) (host, path string, err error) {
+	if u, parseErr := url.Parse(remote); parseErr == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		// scp-like syntax: [user@]host:path
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		path = rest
+	} else {
+		return "", "", fmt.Errorf("cannot parse remote URL '%s'", remote)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", "", fmt.Errorf("cannot parse remote URL '%s'", remote)
+	}
+	return host, path, nil
+}
+
+// newPRProvider returns the pull request provider for the origin remote.
+func newPRProvider(cfg Config, provider string) (prProvider, error) {
+	remote, err := gitOutput("remote", "get-url", "origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	return remotePRProvider(cfg, provider, remote)
+}
+
+// remotePRProvider returns the pull request provider for a remote URL, for
+// repositories other than the current one.
+func remotePRProvider(cfg Config, provider, remote string) (prProvider, error) {
+	switch provider {
+	case providerGitHub:
+		client, err := newGitHubClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		m := githubRemotePattern.FindStringSubmatch(remote)
+		if m == nil {
+			return nil, fmt.Errorf("origin remote '%s' is not a GitHub repository", remote)
+		}
+		return githubPRProvider{client, m[1], m[2]}, nil
+	case providerGitLab:
+		return newGitLabPRProvider(cfg, remote)
+	case providerBitbucketCloud:
+		return newBitbucketCloudPRProvider(cfg, remote)
+	case providerBitbucketServer:
+		return newBitbucketServerPRProvider(cfg, remote)
+	default:
+		return nil, fmt.Errorf("pull requests are not supported on provider '%s'", provider)
+	}
+}
+
+// restClient is a minimal JSON client for the GitLab and Bitbucket APIs.
+type restClient struct {
+	name    string
+	baseURL string
+	auth    func(*http.Request)
+	http    *http.Client
+}
+
+// do sends a request to an API path, encoding in as JSON if given, and decodes
+// the JSON response into out, if given.
+func (c *restClient) do(method, path string, in, out interface{}) error {
+	if method != http.MethodGet && skipReadOnly(fmt.Sprintf("%s API %s %s", c.name, method, path)) {
+		return nil
+	}
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	c.auth(req)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return explainCancel(err, fmt.Sprintf("%s API %s %s", c.name, method, path), apiTimeout)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s API %s %s returned %s: %s", c.name, method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// bitbucketAuth authenticates with an app password when a username is
+// configured and with a bearer token otherwise.
+func bitbucketAuth(cfg Config) (func(*http.Request), error) {
+	token := os.Getenv("BITBUCKET_TOKEN")
+	if token == "" {
+		token = cfg.Bitbucket.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no Bitbucket token found. Set BITBUCKET_TOKEN or add \"bitbucket\": {\"token\": \"...\"} to the config file")
+	}
+	if cfg.Bitbucket.Username != "" {
+		return func(r *http.Request) { r.SetBasicAuth(cfg.Bitbucket.Username, token) }, nil
+	}
+	return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }, nil
+}
+
+// githubPRProvider opens pull requests on GitHub.
+type githubPRProvider struct {
+	client      *githubClient
+	owner, repo string
+}
+
+func (p githubPRProvider) findOpen(branch string) (*PRLink, error) {
+	pull, err := findOpenGitHubPull(p.client, p.owner, p.repo, branch)
+	if err != nil || pull == nil {
+		return nil, err
+	}
+	return &PRLink{Provider: providerGitHub, Number: pull.Number, URL: pull.HTMLURL}, nil
+}
+
+func (p githubPRProvider) create(req prRequest) (*PRLink, error) {
+	in := map[string]interface{}{
+		"title": req.Title,
+		"head":  req.Branch,
+		"base":  req.Base,
+		"body":  req.Body,
+		"draft": req.Draft,
+	}
+	var out githubPull
+	if err := p.client.postJSON(fmt.Sprintf("/repos/%s/%s/pulls", p.owner, p.repo), in, &out); err != nil {
+		return nil, err
+	}
+	return &PRLink{Provider: providerGitHub, Number: out.Number, URL: out.HTMLURL}, nil
+}
+
+// gitlabPRProvider opens merge requests on GitLab.
+type gitlabPRProvider struct {
+	client  *restClient
+	project string // URL-escaped "group/name"
+}
+
+// gitlabMergeRequest is the part of a GitLab merge request the helper uses.
+type gitlabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+func newGitLabPRProvider(cfg Config, remote string) (prProvider, error) {
+	host, path, err := parseRemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		token = cfg.GitLab.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no GitLab token found. Set GITLAB_TOKEN or add \"gitlab\": {\"token\": \"...\"} to the config file")
+	}
+	apiURL := cfg.GitLab.APIURL
+	if apiURL == "" {
+		apiURL = defaultGitLabAPIURL
+		if host != "gitlab.com" {
+			apiURL = "https://" + host + "/api/v4"
+		}
+	}
+	return gitlabPRProvider{
+		client: &restClient{
+			name:    "GitLab",
+			baseURL: strings.TrimRight(apiURL, "/"),
+			auth:    func(r *http.Request) { r.Header.Set("PRIVATE-TOKEN", token) },
+			http:    &http.Client{},
+		},
+		project: url.PathEscape(path),
+	}, nil
+}
+
+func (p gitlabPRProvider) findOpen(branch string) (*PRLink, error) {
+	var mrs []gitlabMergeRequest
+	path := fmt.Sprintf("/projects/%s/merge_requests?state=opened&source_branch=%s", p.project, url.QueryEscape(branch))
+	if err := p.client.do(http.MethodGet, path, nil, &mrs); err != nil || len(mrs) == 0 {
+		return nil, err
+	}
+	return &PRLink{Provider: providerGitLab, Number: mrs[0].IID, URL: mrs[0].WebURL}, nil
+}
+
+func (p gitlabPRProvider) create(req prRequest) (*PRLink, error) {
+	title := req.Title
+	if req.Draft {
+		title = "Draft: " + title
+	}
+	in := map[string]interface{}{
+		"source_branch": req.Branch,
+		"target_branch": req.Base,
+		"title":         title,
+		"description":   req.Body,
+	}
+	var out gitlabMergeRequest
+	if err := p.client.do(http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", p.project), in, &out); err != nil {
+		return nil, err
+	}
+	return &PRLink{Provider: providerGitLab, Number: out.IID, URL: out.WebURL}, nil
+}
+
+// bitbucketCloudPRProvider opens pull requests on Bitbucket Cloud.
+type bitbucketCloudPRProvider struct {
+	client *restClient
+	repo   string // "workspace/slug"
+}
+
+// bitbucketCloudPull is the part of a Bitbucket Cloud pull request the helper uses.
+type bitbucketCloudPull struct {
+	ID    int `json:"id"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+func newBitbucketCloudPRProvider(cfg Config, remote string) (prProvider, error) {
+	_, path, err := parseRemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := bitbucketAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := cfg.Bitbucket.APIURL
+	if apiURL == "" {
+		apiURL = defaultBitbucketCloudAPIURL
+	}
+	return bitbucketCloudPRProvider{
+		client: &restClient{name: "Bitbucket", baseURL: strings.TrimRight(apiURL, "/"), auth: auth, http: &http.Client{}},
+		repo:   path,
+	}, nil
+}
+
+func (p bitbucketCloudPRProvider) findOpen(branch string) (*PRLink, error) {
+	var out struct {
+		Values []bitbucketCloudPull `json:"values"`
+	}
+	query := url.Values{"state": {"OPEN"}, "q": {fmt.Sprintf("source.branch.name = %q", branch)}}
+	if err := p.client.do(http.MethodGet, "/repositories/"+p.repo+"/pullrequests?"+query.Encode(), nil, &out); err != nil || len(out.Values) == 0 {
+		return nil, err
+	}
+	pull := out.Values[0]
+	return &PRLink{Provider: providerBitbucketCloud, Number: pull.ID, URL: pull.Links.HTML.Href}, nil
+}
+
+func (p bitbucketCloudPRProvider) create(req prRequest) (*PRLink, error) {
+	in := map[string]interface{}{
+		"title":       req.Title,
+		"description": req.Body,
+		"source":      map[string]interface{}{"branch": map[string]string{"name": req.Branch}},
+		"destination": map[string]interface{}{"branch": map[string]string{"name": req.Base}},
+		"draft":       req.Draft,
+	}
+	var out bitbucketCloudPull
+	if err := p.client.do(http.MethodPost, "/repositories/"+p.repo+"/pullrequests", in, &out); err != nil {
+		return nil, err
+	}
+	return &PRLink{Provider: providerBitbucketCloud, Number: out.ID, URL: out.Links.HTML.Href}, nil
+}
+
+// bitbucketServerPRProvider opens pull requests on Bitbucket Server (Data Center).
+type bitbucketServerPRProvider struct {
+	client *restClient
+	repo   string // "/projects/<key>/repos/<slug>"
+}
+
+// bitbucketServerPull is the part of a Bitbucket Server pull request the helper uses.
+type bitbucketServerPull struct {
+	ID    int `json:"id"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// link returns the pull request link, with the browser URL when given.
+func (p bitbucketServerPull) link() *PRLink {
+	link := &PRLink{Provider: providerBitbucketServer, Number: p.ID}
+	if len(p.Links.Self) > 0 {
+		link.URL = p.Links.Self[0].Href
+	}
+	return link
+}
+
+func newBitbucketServerPRProvider(cfg Config, remote string) (prProvider, error) {
+	host, path, err := parseRemoteURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	// HTTP clone URLs are /scm/<project>/<repo>, SSH ones /<project>/<repo>.
+	parts := strings.Split(strings.TrimPrefix(path, "scm/"), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("cannot find the project and repository in remote URL '%s'", remote)
+	}
+	auth, err := bitbucketAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := cfg.Bitbucket.APIURL
+	if apiURL == "" {
+		apiURL = "https://" + host + "/rest/api/1.0"
+	}
+	return bitbucketServerPRProvider{
+		client: &restClient{name: "Bitbucket", baseURL: strings.TrimRight(apiURL, "/"), auth: auth, http: &http.Client{}},
+		repo:   fmt.Sprintf("/projects/%s/repos/%s", url.PathEscape(parts[0]), url.PathEscape(parts[1])),
+	}, nil
+}
+
+func (p bitbucketServerPRProvider) findOpen(branch string) (*PRLink, error) {
+	var out struct {
+		Values []bitbucketServerPull `json:"values"`
+	}
+	query := url.Values{"state": {"OPEN"}, "direction": {"OUTGOING"}, "at": {"refs/heads/" + branch}}
+	if err := p.client.do(http.MethodGet, p.repo+"/pull-requests?"+query.Encode(), nil, &out); err != nil || len(out.Values) == 0 {
+		return nil, err
+	}
+	return out.Values[0].link(), nil
+}
+
+func (p bitbucketServerPRProvider) create(req prRequest) (*PRLink, error) {
+	in := map[string]interface{}{
+		"title":       req.Title,
+		"description": req.Body,
+		"fromRef":     map[string]string{"id": "refs/heads/" + req.Branch},
+		"toRef":       map[string]string{"id": "refs/heads/" + req.Base},
+		"draft":       req.Draft,
+	}
+	var out bitbucketServerPull
+	if err := p.client.do(http.MethodPost, p.repo+"/pull-requests", in, &out); err != nil {
+		return nil, err
+	}
+	return out.link(), nil
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+)
+
+// Supported values of prompt_backend.
+const (
+	promptBackendSurvey    = "survey"
+	promptBackendBubbleTea = "bubbletea"
+)
+
+// promptBackends lists the prompt backends.
+var promptBackends = []string{promptBackendSurvey, promptBackendBubbleTea}
+
+// promptBackend shows questions to the user and returns the answers.
+// Commands still describe their questions as survey prompts, which askOne
+// turns into questions, so a backend can offer richer widgets without every
+// command being rewritten at once.
+type promptBackend interface {
+	ask(q question) (answer, error)
+}
+
+// prompter is the backend chosen with prompt_backend.
+var prompter promptBackend = surveyBackend{}
+
+// configurePrompts selects the prompt backend.
+func configurePrompts(backend string) error {
+	switch backend {
+	case "", promptBackendSurvey:
+		prompter = surveyBackend{}
+	case promptBackendBubbleTea:
+		prompter = bubbleTeaBackend{}
+	default:
+		return fmt.Errorf("unknown prompt_backend '%s'; use %s", backend, strings.Join(promptBackends, " or "))
+	}
+	return nil
+}
+
+// Kinds of questions.
+const (
+	questionSelect = iota
+	questionMultiSelect
+	questionInput
+	questionConfirm
+)
+
+// question is a prompt independent of the backend showing it.
+type question struct {
+	kind     int
+	message  string
+	help     string
+	options  []string
+	pageSize int
+	// defaultIndex is the option selected at first, or -1; defaultIndexes
+	// are the options checked at first.
+	defaultIndex   int
+	defaultIndexes []int
+	defaultText    string
+	defaultYes     bool
+	// description, filter and suggest are as in the survey prompts.
+	description func(value string, index int) string
+	filter      func(filter, value string, index int) bool
+	suggest     func(toComplete string) []string
+	validators  []survey.Validator
+}
+
+// answer is the answer to a question: the index or indexes of the chosen
+// options, the text entered or whether the user said yes.
+type answer struct {
+	index   int
+	indexes []int
+	text    string
+	yes     bool
+}
+
+// questionOf describes a survey prompt as a question.
+func questionOf(p survey.Prompt) (question, error) {
+	q := question{defaultIndex: -1}
+	switch p := p.(type) {
+	case *survey.Select:
+		q.kind, q.message, q.help, q.options, q.pageSize = questionSelect, p.Message, p.Help, p.Options, p.PageSize
+		q.description, q.filter = p.Description, p.Filter
+		switch d := p.Default.(type) {
+		case string:
+			q.defaultIndex = slices.Index(p.Options, d)
+		case int:
+			q.defaultIndex = d
+		}
+	case *survey.MultiSelect:
+		q.kind, q.message, q.help, q.options, q.pageSize = questionMultiSelect, p.Message, p.Help, p.Options, p.PageSize
+		q.description, q.filter = p.Description, p.Filter
+		switch d := p.Default.(type) {
+		case []string:
+			for _, value := range d {
+				if i := slices.Index(p.Options, value); i >= 0 {
+					q.defaultIndexes = append(q.defaultIndexes, i)
+				}
+			}
+		case []int:
+			q.defaultIndexes = d
+		}
+	case *survey.Input:
+		q.kind, q.message, q.help, q.defaultText, q.suggest = questionInput, p.Message, p.Help, p.Default, p.Suggest
+	case *survey.Confirm:
+		q.kind, q.message, q.help, q.defaultYes = questionConfirm, p.Message, p.Help, p.Default
+	default:
+		return q, fmt.Errorf("unsupported prompt %T", p)
+	}
+	return q, nil
+}
+
+// value returns an answer as survey passes it to validators.
+func (q question) value(a answer) interface{} {
+	switch q.kind {
+	case questionSelect:
+		return core.OptionAnswer{Value: q.options[a.index], Index: a.index}
+	case questionMultiSelect:
+		values := make([]core.OptionAnswer, len(a.indexes))
+		for i, index := range a.indexes {
+			values[i] = core.OptionAnswer{Value: q.options[index], Index: index}
+		}
+		return values
+	case questionConfirm:
+		return a.yes
+	}
+	return a.text
+}
+
+// validate checks an answer with the validators of the question.
+func (q question) validate(a answer) error {
+	for _, v := range q.validators {
+		if err := v(q.value(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write stores an answer in response, which may be of any type survey
+// accepts for the prompt that is used in this repository.
+func (q question) write(a answer, response interface{}) error {
+	switch r := response.(type) {
+	case *int:
+		*r = a.index
+	case *[]int:
+		*r = a.indexes
+	case *bool:
+		*r = a.yes
+	case *string:
+		if q.kind == questionSelect {
+			*r = q.options[a.index]
+		} else {
+			*r = a.text
+		}
+	case *[]string:
+		values := make([]string, len(a.indexes))
+		for i, index := range a.indexes {
+			values[i] = q.options[index]
+		}
+		*r = values
+	default:
+		return fmt.Errorf("unsupported answer type %T", response)
+	}
+	return nil
+}
+
+// ask shows a survey prompt with the configured backend.
+func ask(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+	q, err := questionOf(p)
+	if err != nil {
+		return err
+	}
+	var options survey.AskOptions
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return err
+		}
+	}
+	q.validators = options.Validators
+	a, err := prompter.ask(q)
+	if err != nil {
+		return err
+	}
+	return q.write(a, response)
+}
+
+// surveyBackend shows questions with survey, as the tool always has.
+type surveyBackend struct{}
+
+func (surveyBackend) ask(q question) (answer, error) {
+	var opts []survey.AskOpt
+	for _, v := range q.validators {
+		opts = append(opts, survey.WithValidator(v))
+	}
+	var a answer
+	switch q.kind {
+	case questionSelect:
+		p := &survey.Select{Message: q.message, Help: q.help, Options: q.options, PageSize: q.pageSize, Description: q.description, Filter: q.filter}
+		if q.defaultIndex >= 0 {
+			p.Default = q.defaultIndex
+		}
+		return a, survey.AskOne(p, &a.index, opts...)
+	case questionMultiSelect:
+		p := &survey.MultiSelect{Message: q.message, Help: q.help, Options: q.options, PageSize: q.pageSize, Description: q.description, Filter: q.filter}
+		if len(q.defaultIndexes) > 0 {
+			p.Default = q.defaultIndexes
+		}
+		return a, survey.AskOne(p, &a.indexes, opts...)
+	case questionConfirm:
+		return a, survey.AskOne(&survey.Confirm{Message: q.message, Help: q.help, Default: q.defaultYes}, &a.yes, opts...)
+	}
+	return a, survey.AskOne(&survey.Input{Message: q.message, Help: q.help, Default: q.defaultText, Suggest: q.suggest}, &a.text, opts...)
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultPageSize is how many options a list shows at once, as in survey.
+const defaultPageSize = 7
+
+// bubbleTeaBackend shows questions with Bubble Tea. Lists are filtered as
+// you type, like with survey, and answers are validated the same way.
+type bubbleTeaBackend struct{}
+
+func (bubbleTeaBackend) ask(q question) (answer, error) {
+	m := questionModel{q: q, checked: map[int]bool{}}
+	for _, i := range q.defaultIndexes {
+		m.checked[i] = true
+	}
+	if q.defaultIndex >= 0 && q.defaultIndex < len(q.options) {
+		m.cursor = q.defaultIndex
+	}
+	// Prompts follow stdout, which --print moves to stderr.
+	final, err := tea.NewProgram(m, tea.WithInput(os.Stdin), tea.WithOutput(os.Stdout)).Run()
+	if err != nil {
+		return answer{}, err
+	}
+	m = final.(questionModel)
+	if m.interrupted {
+		// Callers treat Ctrl+C the same with either backend.
+		return answer{}, terminal.InterruptErr
+	}
+	return m.result, nil
+}
+
+// questionModel is the Bubble Tea model of one question.
+type questionModel struct {
+	q question
+	// filter is typed to narrow down the options of a list, text is the
+	// answer to an input question.
+	filter, text []rune
+	// cursor is the position of the highlighted option among the visible ones.
+	cursor  int
+	checked map[int]bool
+	// suggestions are cycled through with Tab.
+	suggestions []string
+	suggestion  int
+	showHelp    bool
+	err         error
+
+	done, interrupted bool
+	result            answer
+}
+
+func (m questionModel) Init() tea.Cmd {
+	return nil
+}
+
+// visible returns the indexes of the options matching the filter.
+func (m questionModel) visible() []int {
+	var indexes []int
+	filter := string(m.filter)
+	for i, option := range m.q.options {
+		match := strings.Contains(strings.ToLower(option), strings.ToLower(filter))
+		if m.q.filter != nil {
+			match = filter == "" || m.q.filter(filter, option, i)
+		}
+		if match {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (m questionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	list := m.q.kind == questionSelect || m.q.kind == questionMultiSelect
+	if key.Type != tea.KeyTab {
+		m.suggestions = nil
+	}
+	switch key.Type {
+	case tea.KeyCtrlC:
+		m.interrupted = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		return m.submit()
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(m.visible())-1 {
+			m.cursor++
+		}
+	case tea.KeySpace:
+		if m.q.kind == questionMultiSelect {
+			if vis := m.visible(); m.cursor < len(vis) {
+				m.checked[vis[m.cursor]] = !m.checked[vis[m.cursor]]
+			}
+			return m, nil
+		}
+		m = m.typed([]rune{' '}, list)
+	case tea.KeyBackspace:
+		if list && len(m.filter) > 0 {
+			m.filter, m.cursor = m.filter[:len(m.filter)-1], 0
+		} else if !list && len(m.text) > 0 {
+			m.text = m.text[:len(m.text)-1]
+		}
+	case tea.KeyCtrlU, tea.KeyEsc:
+		m.filter, m.text, m.cursor = nil, nil, 0
+	case tea.KeyTab:
+		if m.q.suggest == nil {
+			return m, nil
+		}
+		if m.suggestions == nil {
+			m.suggestions, m.suggestion = m.q.suggest(string(m.text)), -1
+		}
+		if len(m.suggestions) > 0 {
+			m.suggestion = (m.suggestion + 1) % len(m.suggestions)
+			m.text = []rune(m.suggestions[m.suggestion])
+		}
+	case tea.KeyRunes:
+		if m.q.kind == questionConfirm {
+			switch strings.ToLower(string(key.Runes)) {
+			case "y":
+				m.result.yes = true
+				return m.finish()
+			case "n":
+				m.result.yes = false
+				return m.finish()
+			}
+		}
+		if string(key.Runes) == "?" && m.q.help != "" && len(m.filter) == 0 && len(m.text) == 0 {
+			m.showHelp = !m.showHelp
+			return m, nil
+		}
+		m = m.typed(key.Runes, list)
+	}
+	return m, nil
+}
+
+// typed adds typed runes to the filter of a list or the text of an input.
+func (m questionModel) typed(runes []rune, list bool) questionModel {
+	switch {
+	case list:
+		m.filter, m.cursor = append(m.filter, runes...), 0
+	case m.q.kind == questionInput:
+		m.text = append(m.text, runes...)
+	}
+	return m
+}
+
+// submit takes the answer as it stands, if it is valid.
+func (m questionModel) submit() (tea.Model, tea.Cmd) {
+	switch m.q.kind {
+	case questionSelect:
+		vis := m.visible()
+		if m.cursor >= len(vis) {
+			return m, nil
+		}
+		m.result.index = vis[m.cursor]
+	case questionMultiSelect:
+		m.result.indexes = nil
+		for i := range m.q.options {
+			if m.checked[i] {
+				m.result.indexes = append(m.result.indexes, i)
+			}
+		}
+	case questionInput:
+		m.result.text = string(m.text)
+		if m.result.text == "" {
+			m.result.text = m.q.defaultText
+		}
+	case questionConfirm:
+		m.result.yes = m.q.defaultYes
+	}
+	return m.finish()
+}
+
+// finish ends the question, unless a validator rejects the answer.
+func (m questionModel) finish() (tea.Model, tea.Cmd) {
+	if m.err = m.q.validate(m.result); m.err != nil {
+		return m, nil
+	}
+	m.done = true
+	return m, tea.Quit
+}
+
+func (m questionModel) View() string {
+	var b strings.Builder
+	if m.done {
+		fmt.Fprintf(&b, "? %s %s\n", m.q.message, m.answerText())
+		return b.String()
+	}
+	if m.interrupted {
+		return ""
+	}
+	if m.err != nil {
+		fmt.Fprintf(&b, "✘ Sorry, your reply was invalid: %v\n", m.err)
+	}
+	fmt.Fprintf(&b, "? %s ", m.q.message)
+	if m.q.help != "" && !m.showHelp {
+		b.WriteString("[? for help] ")
+	}
+	switch m.q.kind {
+	case questionSelect, questionMultiSelect:
+		switch {
+		case len(m.filter) > 0:
+			b.WriteString(string(m.filter))
+		case m.q.kind == questionMultiSelect:
+			b.WriteString("[Use arrows to move, space to select, type to filter]")
+		default:
+			b.WriteString("[Use arrows to move, type to filter]")
+		}
+	case questionInput:
+		if m.q.defaultText != "" {
+			fmt.Fprintf(&b, "(%s) ", m.q.defaultText)
+		}
+		b.WriteString(string(m.text))
+	case questionConfirm:
+		if m.q.defaultYes {
+			b.WriteString("(Y/n) ")
+		} else {
+			b.WriteString("(y/N) ")
+		}
+	}
+	b.WriteString("\n")
+	if m.showHelp {
+		fmt.Fprintf(&b, "ⓘ %s\n", m.q.help)
+	}
+	for i, s := range m.suggestions {
+		marker := "  "
+		if i == m.suggestion {
+			marker = "> "
+		}
+		b.WriteString(marker + s + "\n")
+	}
+	m.writeOptions(&b)
+	return b.String()
+}
+
+// writeOptions lists the page of visible options around the cursor.
+func (m questionModel) writeOptions(b *strings.Builder) {
+	if m.q.kind != questionSelect && m.q.kind != questionMultiSelect {
+		return
+	}
+	vis := m.visible()
+	size := m.q.pageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	start := max(0, min(m.cursor-size/2, len(vis)-size))
+	for pos := start; pos < len(vis) && pos < start+size; pos++ {
+		index := vis[pos]
+		line := "  "
+		if pos == m.cursor {
+			line = "> "
+		}
+		if m.q.kind == questionMultiSelect {
+			if m.checked[index] {
+				line += "[x] "
+			} else {
+				line += "[ ] "
+			}
+		}
+		line += m.q.options[index]
+		if m.q.description != nil {
+			if d := m.q.description(m.q.options[index], index); d != "" {
+				line += " - " + d
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+}
+
+// answerText shows the answer after the question, once answered.
+func (m questionModel) answerText() string {
+	switch m.q.kind {
+	case questionSelect:
+		return m.q.options[m.result.index]
+	case questionMultiSelect:
+		values := make([]string, len(m.result.indexes))
+		for i, index := range m.result.indexes {
+			values[i] = m.q.options[index]
+		}
+		return strings.Join(values, ", ")
+	case questionConfirm:
+		if m.result.yes {
+			return "Yes"
+		}
+		return "No"
+	}
+	return m.result.text
+}
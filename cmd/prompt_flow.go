@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// FlowPromptsConfig changes the questions of an interactive flow such as
+// create-branch or create-commit.
+type FlowPromptsConfig struct {
+	// Order lists the steps in the order they are asked; steps it leaves out
+	// follow in their usual order.
+	Order []string `json:"order,omitempty"`
+	// Hide lists built-in choice steps that are not asked, e.g. "product" in
+	// a single-product repository; they take their first option.
+	Hide []string `json:"hide,omitempty"`
+	// Steps adds questions to the flow.
+	Steps []CustomStep `json:"steps,omitempty"`
+}
+
+// CustomStep is a question added to a flow, or a custom field. With choices
+// it is a select, otherwise free text that must match Pattern, if set.
+//
+// Answers to the steps of a flow are added to create-commit's commit as
+// trailers, e.g. "Component: api", and kept in the metadata of create-branch's
+// branch. Custom fields are asked in both flows and used in the templates.
+type CustomStep struct {
+	Name     string   `json:"name"`
+	Message  string   `json:"message,omitempty"`
+	Choices  []string `json:"choices,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Optional bool     `json:"optional,omitempty"`
+}
+
+// noChoice is offered by optional custom selects to leave them unanswered.
+const noChoice = "(none)"
+
+// flowStep is a named question of a flow. Steps with a fallback can be
+// hidden, which answers them with the fallback.
+type flowStep struct {
+	name     string
+	value    *string
+	ask      promptStep
+	fallback string
+	optional bool
+}
+
+// customAnswer is the answer to a custom step.
+type customAnswer struct {
+	Name  string
+	Value string
+}
+
+// given reports whether the step was answered.
+func (a customAnswer) given() bool {
+	return a.Value != "" && a.Value != noChoice
+}
+
+// trailer returns the answer as a commit trailer, e.g. "Component: api".
+func (a customAnswer) trailer() convention.Trailer {
+	words := strings.FieldsFunc(a.Name, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return convention.Trailer{Key: strings.Join(words, "-"), Value: a.Value}
+}
+
+// flowPrompts returns the prompts config of a flow.
+func (c Config) flowPrompts(flow string) FlowPromptsConfig {
+	return c.Prompts[flow]
+}
+
+// customFieldNames returns the names of the custom fields.
+func (c Config) customFieldNames() []string {
+	names := make([]string, len(c.CustomFields))
+	for i, f := range c.CustomFields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// customSteps returns steps asking the given questions, saved in session as
+// they are answered, and their answers.
+func customSteps(session *flowSession, questions []CustomStep) ([]flowStep, []*customAnswer, error) {
+	var steps []flowStep
+	var answers []*customAnswer
+	for _, s := range questions {
+		answer := &customAnswer{Name: s.Name}
+		message := s.Message
+		if message == "" {
+			message = fmt.Sprintf("Enter %s:", s.Name)
+		}
+		var ask promptStep
+		if len(s.Choices) > 0 {
+			options := s.Choices
+			if s.Optional {
+				options = append(slices.Clone(options), noChoice)
+			}
+			ask = func(back bool) error { return askSelect(message, options, &answer.Value, back) }
+		} else {
+			pattern, err := regexp.Compile("^(?:" + s.Pattern + ")$")
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid pattern of %s: %w", s.Name, err)
+			}
+			validator := func(val interface{}) error {
+				str, _ := val.(string)
+				switch {
+				case str == "" && !s.Optional:
+					return fmt.Errorf("%s is required", s.Name)
+				case str != "" && s.Pattern != "" && !pattern.MatchString(str):
+					return fmt.Errorf("%s must match %s", s.Name, s.Pattern)
+				}
+				return nil
+			}
+			ask = func(back bool) error { return askInput(message, &answer.Value, back, validator) }
+		}
+		steps = append(steps, flowStep{name: s.Name, value: &answer.Value, ask: session.step(s.Name, &answer.Value, ask), optional: s.Optional})
+		answers = append(answers, answer)
+	}
+	return steps, answers, nil
+}
+
+// arrange adds the custom steps to the built-in ones, answers and removes
+// hidden steps, and orders the rest. Names in the config that are not steps
+// of the flow are reported, so typos do not go unnoticed.
+func (p FlowPromptsConfig) arrange(flow string, builtin, custom []flowStep) ([]flowStep, error) {
+	steps := append(slices.Clone(builtin), custom...)
+	index := func(name string) int {
+		return slices.IndexFunc(steps, func(s flowStep) bool { return s.name == name })
+	}
+	for i, s := range custom {
+		if s.name == "" || index(s.name) < len(builtin)+i {
+			return nil, fmt.Errorf("invalid prompts.%s: custom steps need a name that no other step has, got '%s'", flow, s.name)
+		}
+	}
+
+	// Built-in steps come first, so the ones left are those before the custom steps.
+	builtinLeft := len(builtin)
+	for _, name := range p.Hide {
+		i := index(name)
+		if i < 0 || i >= builtinLeft {
+			return nil, fmt.Errorf("invalid prompts.%s: cannot hide '%s'; built-in steps are %s", flow, name, stepNames(builtin))
+		}
+		if steps[i].fallback == "" {
+			return nil, fmt.Errorf("invalid prompts.%s: the %s step cannot be hidden", flow, name)
+		}
+		if *steps[i].value == "" {
+			*steps[i].value = steps[i].fallback
+		}
+		steps = slices.Delete(steps, i, i+1)
+		builtinLeft--
+	}
+
+	var ordered []flowStep
+	for _, name := range p.Order {
+		i := index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid prompts.%s: unknown or hidden step '%s' in order; steps are %s", flow, name, stepNames(steps))
+		}
+		ordered = append(ordered, steps[i])
+		steps = slices.Delete(steps, i, i+1)
+	}
+	return append(ordered, steps...), nil
+}
+
+// branchFieldValues returns the custom fields known for a branch: those in
+// its name and those recorded when it was created.
+func branchFieldValues(branch string) map[string]string {
+	values := map[string]string{}
+	if repo, err := repoKey(); err == nil {
+		if md, err := loadMetadata(); err == nil {
+			maps.Copy(values, md.Repos[repo][branch].Fields)
+		}
+	}
+	if b, ok := branchTemplate.Parse(branch); ok {
+		for name, value := range b.Fields {
+			if value != "" {
+				values[name] = value
+			}
+		}
+	}
+	return values
+}
+
+// templateFields returns fields with every configured custom field present,
+// as templates fail on missing ones.
+func templateFields(cfg Config, fields map[string]string) map[string]string {
+	all := map[string]string{}
+	for _, name := range cfg.customFieldNames() {
+		all[name] = fields[name]
+	}
+	return all
+}
+
+// answerMap returns the given answers by name, or nil if there are none.
+func answerMap(answers []*customAnswer) map[string]string {
+	var m map[string]string
+	for _, a := range givenAnswers(answers) {
+		if m == nil {
+			m = map[string]string{}
+		}
+		m[a.Name] = a.Value
+	}
+	return m
+}
+
+// setFields answers custom fields with values given as flags, checking them
+// like the prompts would.
+func setFields(questions []CustomStep, answers []*customAnswer, values map[string]string) error {
+	for name, value := range values {
+		i := slices.IndexFunc(questions, func(q CustomStep) bool { return q.Name == name })
+		if i < 0 {
+			return fmt.Errorf("unknown custom field '%s'; see custom_fields in the config", name)
+		}
+		q := questions[i]
+		if len(q.Choices) > 0 {
+			if err := convention.ValidateChoice(name, value, q.Choices); err != nil {
+				return err
+			}
+		} else if q.Pattern != "" && !regexp.MustCompile("^(?:"+q.Pattern+")$").MatchString(value) {
+			return fmt.Errorf("%s must match %s", name, q.Pattern)
+		}
+		answers[i].Value = value
+	}
+	return nil
+}
+
+// requiredFieldsGiven reports whether every required custom field has an answer.
+func requiredFieldsGiven(questions []CustomStep, answers []*customAnswer) bool {
+	for i, q := range questions {
+		if !q.Optional && !answers[i].given() {
+			return false
+		}
+	}
+	return true
+}
+
+// givenAnswers returns the custom answers that were given.
+func givenAnswers(answers []*customAnswer) []customAnswer {
+	var given []customAnswer
+	for _, a := range answers {
+		if a.given() {
+			given = append(given, *a)
+		}
+	}
+	return given
+}
+
+// stepNames lists the names of steps for error messages.
+func stepNames(steps []flowStep) string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = s.name
+	}
+	return strings.Join(names, ", ")
+}
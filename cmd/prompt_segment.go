@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Cached pull request status older than this is refreshed in the background.
+const prStatusMaxAge = 5 * time.Minute
+
+// ciSymbols are shown in the prompt segment for each combined CI state.
+var ciSymbols = map[string]string{
+	"success": "✓",
+	"failure": "✗",
+	"error":   "✗",
+	"pending": "●",
+}
+
+// PRStatus is the cached pull request and CI state of a branch.
+type PRStatus struct {
+	Number    int       `json:"number,omitempty"`
+	State     string    `json:"state,omitempty"` // open, draft, merged or closed
+	CI        string    `json:"ci,omitempty"`    // success, failure, error or pending
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// prStatusCachePath returns the path of the pull request status cache.
+func prStatusCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pr_status.json"), nil
+}
+
+// loadPRStatusCache reads the cached statuses, keyed by repository and branch.
+func loadPRStatusCache() (map[string]map[string]PRStatus, error) {
+	cache := map[string]map[string]PRStatus{}
+	path, err := prStatusCachePath()
+	if err != nil {
+		return cache, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// A missing cache just means nothing has been fetched yet.
+		return cache, nil
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return map[string]map[string]PRStatus{}, err
+	}
+	return cache, nil
+}
+
+// savePRStatusCache writes the cached statuses to disk.
+func savePRStatusCache(cache map[string]map[string]PRStatus) error {
+	path, err := prStatusCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	// A concurrent prompt never reads a partial cache.
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// recordPRStatus caches the status of a branch of a repository. The cache is
+// locked while it is updated, so concurrent refreshes do not drop each other's
+// entries.
+func recordPRStatus(repo, branch string, status PRStatus) error {
+	path, err := prStatusCachePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cache, err := loadPRStatusCache()
+	if err != nil {
+		return err
+	}
+	if cache[repo] == nil {
+		cache[repo] = map[string]PRStatus{}
+	}
+	cache[repo][branch] = status
+	return savePRStatusCache(cache)
+}
+
+// fetchPRStatus looks up the latest pull request for a branch and the CI state of its head commit.
+func fetchPRStatus(client *githubClient, owner, repo, branch string) (PRStatus, error) {
+	status := PRStatus{CheckedAt: time.Now()}
+	var pulls []struct {
+		Number   int        `json:"number"`
+		State    string     `json:"state"`
+		Draft    bool       `json:"draft"`
+		MergedAt *time.Time `json:"merged_at"`
+		Head     struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=all&per_page=1&head=%s", owner, repo, url.QueryEscape(owner+":"+branch))
+	if err := client.get(path, &pulls); err != nil {
+		return status, err
+	}
+	if len(pulls) == 0 {
+		return status, nil
+	}
+
+	pr := pulls[0]
+	status.Number = pr.Number
+	switch {
+	case pr.MergedAt != nil:
+		status.State = "merged"
+	case pr.State == "open" && pr.Draft:
+		status.State = "draft"
+	default:
+		status.State = pr.State
+	}
+
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := client.get(fmt.Sprintf("/repos/%s/%s/commits/%s/status", owner, repo, pr.Head.SHA), &combined); err != nil {
+		return status, err
+	}
+	// Commits without any status report "pending", which would be misleading.
+	if combined.TotalCount > 0 {
+		status.CI = combined.State
+	}
+	return status, nil
+}
+
+// refreshPRStatus fetches and caches the pull request status of a branch of a repository.
+func refreshPRStatus(cfg Config, repo, branch string) error {
+	client, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+	remote, err := gitOutputIn(repo, "remote", "get-url", "origin")
+	if err != nil {
+		return fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	m := githubRemotePattern.FindStringSubmatch(remote)
+	if m == nil {
+		// Only GitHub is supported for now; other providers simply show no PR state.
+		return nil
+	}
+	status, err := fetchPRStatus(client, m[1], m[2], branch)
+	if err != nil {
+		return err
+	}
+	return recordPRStatus(repo, branch, status)
+}
+
+// refreshWorkspacePRStatus refreshes the pull request status of the checked
+// out branch of every workspace repository.
+func refreshWorkspacePRStatus(cfg Config) error {
+	if cfg.GitHub.Token == "" && githubTokenFromEnv() == "" {
+		return nil
+	}
+	var failed []string
+	for _, repo := range workspaceRepos(cfg) {
+		branch, err := gitOutputIn(repo, "symbolic-ref", "--short", "HEAD")
+		if err != nil || convention.LongLivedBranchPattern.MatchString(branch) {
+			continue
+		}
+		if err := refreshPRStatus(cfg, repo, branch); err != nil {
+			failed = append(failed, filepath.Base(repo))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to refresh %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// promptSegment renders the compact status of a branch, e.g. "fix CPRE-11347 #42 open ✓".
+func promptSegment(branch string, status PRStatus, stale bool) string {
+	parts := []string{}
+	if b, ok := branchTemplate.Parse(branch); ok {
+		parts = append(parts, b.Type, strings.ToUpper(b.Ticket))
+	} else {
+		parts = append(parts, branch)
+	}
+	if status.Number > 0 {
+		parts = append(parts, fmt.Sprintf("#%d", status.Number), status.State)
+		if symbol, ok := ciSymbols[status.CI]; ok {
+			parts = append(parts, symbol)
+		}
+	}
+	if stale {
+		parts = append(parts, "stale")
+	}
+	return strings.Join(parts, " ")
+}
+
+// promptSegmentCmd represents the command to print branch context for a shell prompt.
+var promptSegmentCmd = &cobra.Command{
+	Use:   "prompt-segment",
+	Short: "Print a compact branch status for embedding in a shell prompt",
+	Long: `Print the ticket, branch type, and pull request and CI state of the current
+branch, e.g. "fix CPRE-11347 #42 open ✓", for use in PS1 or a starship custom
+module. Branches past the branch policy's expiry are marked "stale".
+
+The command never touches the network itself: pull request state comes from a
+cache that is refreshed in the background when it is older than five minutes,
+and by the daemon. Nothing is printed outside a repository or on base branches.
+
+  PS1='$(gh prompt-segment) \$ '`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		// The prompt is drawn after every command, so failures are silent.
+		repo, err := repoKey()
+		if err != nil {
+			return nil
+		}
+		branch, err := gitOutput("symbolic-ref", "--short", "HEAD")
+		if err != nil || convention.LongLivedBranchPattern.MatchString(branch) {
+			return nil
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil
+		}
+
+		if refresh {
+			return refreshPRStatus(cfg, repo, branch)
+		}
+
+		cache, _ := loadPRStatusCache()
+		status, cached := cache[repo][branch]
+		hasToken := cfg.GitHub.Token != "" || githubTokenFromEnv() != ""
+		if hasToken && (!cached || time.Since(status.CheckedAt) > prStatusMaxAge) {
+			// Refresh in a detached process so the prompt is never delayed.
+			// The entry is marked as checked first, so the prompts drawn
+			// meanwhile, and those after a failed refresh, do not start
+			// another one until it is due again.
+			checked := status
+			checked.CheckedAt = time.Now()
+			if exe, err := os.Executable(); err == nil && recordPRStatus(repo, branch, checked) == nil {
+				exec.Command(exe, "prompt-segment", "--refresh").Start()
+			}
+		}
+
+		stale := false
+		if last, err := gitOutput("log", "-1", "--format=%ct"); err == nil {
+			age := int(time.Since(parseUnix(last)).Hours() / 24)
+			stale = age > cfg.BranchPolicy.expiryDays()
+		}
+
+		fmt.Println(promptSegment(branch, status, stale))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(promptSegmentCmd)
+	promptSegmentCmd.Flags().Bool("refresh", false, "Fetch the pull request status now instead of printing the segment")
+	daemonTasks = append(daemonTasks, daemonTask{name: "refresh pull request status", run: refreshWorkspacePRStatus})
+}
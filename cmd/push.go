@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Supported values of the push settings.
+const (
+	pushAsk    = "ask"
+	pushAlways = "always"
+	pushNever  = "never"
+)
+
+// pushModes lists the push settings.
+var pushModes = []string{pushAsk, pushAlways, pushNever}
+
+// PushConfig sets whether branches are pushed to origin right after they are
+// created or committed to.
+type PushConfig struct {
+	// AfterBranch is whether create-branch pushes the new branch: ask
+	// (the default), always or never.
+	AfterBranch string `json:"after_branch,omitempty"`
+	// AfterCommit is whether create-commit pushes the branch: ask, always or
+	// never (the default).
+	AfterCommit string `json:"after_commit,omitempty"`
+}
+
+// pushMode returns whether to push, as chosen with --push or --no-push or,
+// failing that, the configured setting, which is checked here; key names
+// the setting in errors.
+func pushMode(cmd *cobra.Command, configured, fallback, key string) (string, error) {
+	push, err := cmd.Flags().GetBool("push")
+	if err != nil {
+		return "", err
+	}
+	noPush, err := cmd.Flags().GetBool("no-push")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case push && noPush:
+		return "", fmt.Errorf("--push cannot be combined with --no-push")
+	case push:
+		return pushAlways, nil
+	case noPush:
+		return pushNever, nil
+	case configured == "":
+		return fallback, nil
+	}
+	if err := convention.ValidateChoice(key, configured, pushModes); err != nil {
+		return "", err
+	}
+	return configured, nil
+}
+
+// pushBranch pushes branch, asking first in ask mode. A branch without an
+// upstream is pushed to origin and set to track it, so later pushes and pulls
+// need no arguments. Without a terminal, ask mode does not push.
+func pushBranch(mode, branch string) error {
+	if mode == pushNever {
+		return nil
+	}
+	args := []string{"push"}
+	message := fmt.Sprintf("Push '%s'?", branch)
+	if _, err := gitOutput("rev-parse", "--abbrev-ref", branch+"@{upstream}"); err != nil {
+		args = append(args, "-u", "origin", branch)
+		message = fmt.Sprintf("Push '%s' to origin and track it?", branch)
+	}
+	if mode == pushAsk {
+		if !stdinIsTerminal() {
+			return nil
+		}
+		push := true
+		if err := askOne(&survey.Confirm{Message: message, Default: true}, &push); err != nil || !push {
+			return err
+		}
+	}
+	if err := runGit(args...); err != nil {
+		return fmt.Errorf("failed to push '%s': %w", branch, err)
+	}
+	return nil
+}
+
+// addPushFlags registers --push and --no-push on a command that creates something to push.
+func addPushFlags(cmd *cobra.Command, what string) {
+	cmd.Flags().Bool("push", false, "Push "+what+" without asking")
+	cmd.Flags().Bool("no-push", false, "Do not push "+what)
+}
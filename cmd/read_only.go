@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// readOnly blocks every operation that changes a repository or a remote
+// service; it is set from --read-only, --dry-run or the read_only config setting.
+var readOnly bool
+
+// dryRun is read-only mode that prints the exact commands that would have run,
+// ready to copy into a shell; it is set from --dry-run.
+var dryRun bool
+
+// mutatingGitCommands are the git subcommands that change a repository or a remote.
+var mutatingGitCommands = []string{
+	"add", "am", "apply", "checkout", "cherry-pick", "clean", "commit", "merge",
+	"mv", "pull", "push", "rebase", "reset", "restore", "revert", "rm", "switch", "update-ref",
+}
+
+// readOnlySubcommands are the subcommands of otherwise mutating commands that only query.
+var readOnlySubcommands = map[string][]string{
+	"stash":    {"list", "show"},
+	"worktree": {"list"},
+	"notes":    {"list", "show"},
+	"remote":   {"get-url", "show", "-v"},
+	"branch":   {"--list", "-l", "-r", "--remotes", "-a", "--all", "--contains", "--show-current"},
+	"tag":      {"--list", "-l"},
+}
+
+// gitMutates reports whether a git invocation changes the repository or a remote.
+func gitMutates(args []string) bool {
+	// Skip global options such as "-C <dir>".
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return false
+	}
+	if slices.Contains(mutatingGitCommands, args[0]) {
+		return true
+	}
+	if queries, ok := readOnlySubcommands[args[0]]; ok {
+		return len(args) > 1 && !slices.Contains(queries, args[1])
+	}
+	return false
+}
+
+// skipReadOnly reports whether an operation must be skipped because of
+// read-only mode, printing what would have been done.
+func skipReadOnly(operation string) bool {
+	if dryRun {
+		fmt.Printf("Dry run, skipping: %s\n", operation)
+	} else if readOnly {
+		fmt.Printf("Read-only mode, skipping: %s\n", operation)
+	}
+	return readOnly
+}
+
+// reportDone prints message once a change has been made, or wouldMessage
+// when read-only mode skipped it.
+func reportDone(message, wouldMessage string) {
+	if readOnly {
+		fmt.Println(wouldMessage)
+		return
+	}
+	fmt.Println(message)
+}
+
+// skipCommand is skipReadOnly for running a program, which a dry run prints
+// as a shell command.
+func skipCommand(name string, args []string) bool {
+	if !dryRun {
+		return skipReadOnly(name + " " + strings.Join(args, " "))
+	}
+	fmt.Printf("Would run: %s\n", shellCommand(name, args))
+	return true
+}
+
+// shellCommand returns a command line that runs name with args in a shell.
+func shellCommand(name string, args []string) string {
+	words := []string{name}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes s for a POSIX shell unless it only has safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+%^") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
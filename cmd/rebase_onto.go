@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// listAllBranches returns local and remote-tracking branch names.
+func listAllBranches() ([]string, error) {
+	branches, err := listRefs("refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
+// upstreamBranch returns the upstream of the current branch, or "" if it has none.
+func upstreamBranch() string {
+	upstream, err := gitOutput("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return upstream
+}
+
+// rebaseOntoCmd represents the command to move the current branch to a different base.
+var rebaseOntoCmd = &cobra.Command{
+	Use:   "rebase-onto",
+	Short: "Move the current branch from one base branch to another",
+	Long: `Move the commits of the current branch from its old base to a new one,
+e.g. from develop to release/1.2, using:
+
+  git rebase --onto <new-base> <old-base>
+
+The commits that will be moved are shown before anything happens. If the branch
+has been pushed, the remote branch can be updated afterwards with
+git push --force-with-lease.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		branch, err := getCurrentBranch()
+		if err != nil {
+			return err
+		}
+
+		if err := runGit("fetch", "--prune"); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+		branches, err := listAllBranches()
+		if err != nil {
+			return err
+		}
+
+		// 1. Pick the old and new bases.
+		oldBase, _ := cmd.Flags().GetString("from")
+		newBase, _ := cmd.Flags().GetString("onto")
+		if oldBase == "" {
+			if err := askOne(&survey.Select{
+				Message: fmt.Sprintf("Which branch is '%s' currently based on?", branch),
+				Options: branches,
+			}, &oldBase); err != nil {
+				return err
+			}
+		}
+		if newBase == "" {
+			if err := askOne(&survey.Select{
+				Message: "Which branch should it be moved onto?",
+				Options: branches,
+			}, &newBase); err != nil {
+				return err
+			}
+		}
+		if oldBase == newBase {
+			return fmt.Errorf("the old and new base are both '%s'", oldBase)
+		}
+
+		// 2. Preview the commits that will be moved.
+		commits, err := gitOutput("log", "--oneline", "--no-decorate", oldBase+"..HEAD")
+		if err != nil {
+			return fmt.Errorf("failed to list commits since '%s': %w", oldBase, err)
+		}
+		if commits == "" {
+			return fmt.Errorf("'%s' has no commits on top of '%s'", branch, oldBase)
+		}
+		fmt.Printf("\nThe following commits will be moved from '%s' onto '%s':\n%s\n\n", oldBase, newBase, commits)
+
+		confirm, err := confirmAction(cfg, true, "Rebase these commits?")
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Rebase aborted.")
+			return nil
+		}
+
+		// 3. Rebase.
+		if err := runGit("rebase", "--onto", newBase, oldBase); err != nil {
+			return fmt.Errorf("rebase stopped; resolve the conflicts and run 'git rebase --continue' (or 'git rebase --abort'): %w", err)
+		}
+		fmt.Printf("Moved '%s' onto '%s'.\n", branch, newBase)
+
+		// 4. Update the remote branch, since its history no longer matches.
+		upstream := upstreamBranch()
+		if upstream == "" {
+			return nil
+		}
+		push, err := confirmAction(cfg, true, fmt.Sprintf("Update '%s' with git push --force-with-lease?", upstream))
+		if err != nil {
+			return err
+		}
+		if push {
+			if err := runGit("push", "--force-with-lease"); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseOntoCmd)
+	rebaseOntoCmd.Flags().String("from", "", "Branch the current branch is currently based on")
+	rebaseOntoCmd.Flags().String("onto", "", "Branch to move the current branch onto")
+}
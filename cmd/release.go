@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// breakingPattern matches headers such as "feat(lego)!: ..." that mark a breaking change.
+var breakingPattern = regexp.MustCompile(`^\w+(\([\w-]+\))?!:`)
+
+// releaseScope limits a release to one product of a monorepo. The zero value
+// covers the whole repository.
+type releaseScope struct {
+	product string
+	paths   []string
+}
+
+// newReleaseScope returns the scope for a product, using the paths mapped to
+// it in the config. An empty product covers the whole repository.
+func newReleaseScope(cfg Config, product string) (releaseScope, error) {
+	if product == "" {
+		return releaseScope{}, nil
+	}
+	paths := cfg.ProductPaths[product]
+	if len(paths) == 0 {
+		return releaseScope{}, fmt.Errorf("no paths configured for product '%s'; add them under \"product_paths\" in the config file", product)
+	}
+	return releaseScope{product: product, paths: paths}, nil
+}
+
+// tag returns the tag name for a version, e.g. "lego/v1.4.0" for the lego product.
+func (s releaseScope) tag(v Version) string {
+	if s.product == "" {
+		return v.String()
+	}
+	return s.product + "/" + v.String()
+}
+
+// versionTags returns the versions tagged in the scope, highest first.
+func versionTags(scope releaseScope) ([]Version, error) {
+	out, err := gitOutput("tag", "--list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	prefix := ""
+	if scope.product != "" {
+		prefix = scope.product + "/"
+	}
+	var versions []Version
+	for _, tag := range strings.Split(out, "\n") {
+		name, ok := strings.CutPrefix(tag, prefix)
+		if !ok {
+			continue
+		}
+		if v, err := parseVersion(name); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	slices.SortFunc(versions, func(a, b Version) int { return compareVersions(b, a) })
+	return versions, nil
+}
+
+// latestFinal returns the highest version without a pre-release, if any.
+func latestFinal(versions []Version) (Version, bool) {
+	for _, v := range versions {
+		if v.Pre == "" {
+			return v, true
+		}
+	}
+	return Version{}, false
+}
+
+// bumpFromCommits works out which part of the version to bump from the
+// commits since the given tag that touch the given paths: breaking changes
+// bump the major version, features the minor version, and anything else the
+// patch version.
+func bumpFromCommits(since string, paths ...string) (string, error) {
+	revRange := "HEAD"
+	if since != "" {
+		revRange = since + "..HEAD"
+	}
+	out, err := gitOutput(append([]string{"log", "--no-merges", "--format=%s%n%b%x00", revRange}, pathArgs(paths)...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commits in '%s': %w", revRange, err)
+	}
+
+	part := "patch"
+	for _, msg := range strings.Split(out, "\x00") {
+		msg = strings.TrimSpace(msg)
+		switch {
+		case breakingPattern.MatchString(msg) || strings.Contains(msg, "BREAKING CHANGE"):
+			return "major", nil
+		case strings.HasPrefix(msg, "feat"):
+			part = "minor"
+		}
+	}
+	return part, nil
+}
+
+// nextVersion returns the version to release after the latest final version.
+// With a channel such as "rc", the next pre-release of that channel is
+// returned, e.g. v1.4.0-rc.2 if v1.4.0-rc.1 already exists.
+func nextVersion(versions []Version, part, channel, build string) Version {
+	final, ok := latestFinal(versions)
+	if !ok {
+		final = Version{Prefix: "v"}
+	}
+	next := final.bump(part)
+
+	if channel != "" {
+		n := 0
+		for _, v := range versions {
+			if name, num := v.channel(); v.core() == next && name == channel && num > n {
+				n = num
+			}
+		}
+		next.Pre = channel + "." + strconv.Itoa(n+1)
+	}
+	next.Build = build
+	return next
+}
+
+// createReleaseTag shows the annotation for a release tag, asks for
+// confirmation, and creates the tag on target. With publish, the tag is then
+// published as a GitHub Release.
+func createReleaseTag(cfg Config, scope releaseScope, v Version, previous, target string, publish bool) error {
+	version := scope.tag(v)
+	message, err := renderTagMessage(version, previous, target, scope.paths...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nThe following tag will be created:\n\n%s\n", message)
+
+	prompt := fmt.Sprintf("Create release tag '%s'?", version)
+	if publish {
+		prompt = fmt.Sprintf("Create release tag '%s', push it and publish a GitHub release?", version)
+	}
+	confirm, err := confirmAction(cfg, true, prompt)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		fmt.Println("Release aborted.")
+		return nil
+	}
+
+	if err := runGit("tag", "-a", version, "-m", message, target); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	if !publish {
+		fmt.Printf("Tag '%s' created. Push it with: git push origin %s\n", version, version)
+		return nil
+	}
+
+	return publishGitHubRelease(cfg, version, message, v.Pre != "")
+}
+
+// releaseCmd represents the command to tag the next release.
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Tag the next release, versioned from the commits since the last one",
+	Long: `Work out the next version from the commits since the latest release tag
+(breaking changes bump the major version, feat commits the minor version, and
+everything else the patch version) and create an annotated release tag.
+
+Use --pre to cut a pre-release on a channel such as rc or beta (v1.4.0-rc.1,
+v1.4.0-rc.2, ...) and --build to attach build metadata (v1.4.0+build.7).
+Promote a pre-release with 'release promote'.
+
+In a monorepo, --product releases a single product: tags look like
+lego/v1.4.0 and only commits touching the product's paths (the
+"product_paths" mapping in the config file) count towards the version bump.
+
+With --publish, the "release.build_commands" from the config file are run
+(with $VERSION set to the tag) and the files matching "release.assets" are
+checked before the tag is pushed, so a failed build never leaves a published
+tag behind. A GitHub Release is then created with the files attached.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		part, _ := cmd.Flags().GetString("bump")
+		channel, _ := cmd.Flags().GetString("pre")
+		build, _ := cmd.Flags().GetString("build")
+		product, _ := cmd.Flags().GetString("product")
+		scope, err := newReleaseScope(cfg, product)
+		if err != nil {
+			return err
+		}
+
+		versions, err := versionTags(scope)
+		if err != nil {
+			return err
+		}
+		previous := ""
+		if final, ok := latestFinal(versions); ok {
+			previous = scope.tag(final)
+		}
+
+		if part == "" {
+			if part, err = bumpFromCommits(previous, scope.paths...); err != nil {
+				return err
+			}
+		} else if part != "major" && part != "minor" && part != "patch" {
+			return fmt.Errorf("--bump must be major, minor or patch")
+		}
+
+		publish, _ := cmd.Flags().GetBool("publish")
+		next := nextVersion(versions, part, channel, build)
+		fmt.Printf("Releasing %s (%s bump since %s).\n", scope.tag(next), part, orNone(previous))
+		return createReleaseTag(cfg, scope, next, previous, "HEAD", publish)
+	},
+}
+
+// releasePromoteCmd represents the command to promote a pre-release to a final release.
+var releasePromoteCmd = &cobra.Command{
+	Use:   "promote <pre-release-tag>",
+	Short: "Promote a pre-release tag such as v1.4.0-rc.2 to the final v1.4.0",
+	Long: `Promote a pre-release tag such as v1.4.0-rc.2 to the final v1.4.0, tagging the
+same commit. Product tags such as lego/v1.4.0-rc.2 are promoted to lego/v1.4.0.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		product, name, found := strings.Cut(args[0], "/")
+		if !found {
+			name = args[0]
+			product, _ = cmd.Flags().GetString("product")
+		}
+		scope, err := newReleaseScope(cfg, product)
+		if err != nil {
+			return err
+		}
+		pre, err := parseVersion(name)
+		if err != nil {
+			return err
+		}
+		if pre.Pre == "" {
+			return fmt.Errorf("'%s' is not a pre-release", args[0])
+		}
+
+		versions, err := versionTags(scope)
+		if err != nil {
+			return err
+		}
+		final := pre.core()
+		previous := ""
+		for _, v := range versions {
+			if v.core() == final && v.Pre == "" {
+				return fmt.Errorf("'%s' has already been released", scope.tag(final))
+			}
+			if v.Pre == "" && compareVersions(v, final) < 0 && previous == "" {
+				previous = scope.tag(v)
+			}
+		}
+
+		// The final tag points at the same commit as the pre-release.
+		publish, _ := cmd.Flags().GetBool("publish")
+		return createReleaseTag(cfg, scope, final, previous, scope.tag(pre)+"^{commit}", publish)
+	},
+}
+
+// releaseChangesCmd represents the command to list what changed between two releases.
+var releaseChangesCmd = &cobra.Command{
+	Use:   "changes [from] [to]",
+	Short: "List commits and tickets between two releases or channels",
+	Long: `List the commits and JIRA tickets between two tags, e.g. between v1.4.0-beta.2
+and v1.4.0-rc.1. By default, compares the latest final release with the latest tag.
+With --product, only the product's tags and the commits touching its paths are used.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		product, _ := cmd.Flags().GetString("product")
+		scope, err := newReleaseScope(cfg, product)
+		if err != nil {
+			return err
+		}
+		versions, err := versionTags(scope)
+		if err != nil {
+			return err
+		}
+
+		var from, to string
+		switch len(args) {
+		case 2:
+			from, to = args[0], args[1]
+		case 1:
+			from, to = args[0], "HEAD"
+		default:
+			if len(versions) == 0 {
+				return fmt.Errorf("no release tags found")
+			}
+			if final, ok := latestFinal(versions); ok {
+				from = scope.tag(final)
+			}
+			to = scope.tag(versions[0])
+		}
+		if from == to {
+			fmt.Printf("'%s' is the latest release; nothing to compare.\n", to)
+			return nil
+		}
+
+		revRange := to
+		if from != "" {
+			revRange = from + ".." + to
+		}
+		commits, err := gitOutput(append([]string{"log", "--oneline", "--no-decorate", "--no-merges", revRange}, pathArgs(scope.paths)...)...)
+		if err != nil {
+			return fmt.Errorf("failed to list commits in '%s': %w", revRange, err)
+		}
+		tickets, err := ticketsBetween(from, to, scope.paths...)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Changes from %s to %s:\n\n", orNone(from), to)
+		if commits == "" {
+			fmt.Println("No commits.")
+			return nil
+		}
+		fmt.Println(commits)
+		if len(tickets) > 0 {
+			links := make([]string, len(tickets))
+			for i, t := range tickets {
+				links[i] = linkTicket(t)
+			}
+			fmt.Printf("\nTickets: %s\n", strings.Join(links, ", "))
+		}
+		return nil
+	},
+}
+
+// orNone returns s, or "(none)" if s is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releasePromoteCmd)
+	releaseCmd.AddCommand(releaseChangesCmd)
+	releaseCmd.PersistentFlags().String("product", "", "Release only this product of a monorepo, using its product_paths and tags like lego/v1.4.0")
+	for _, c := range []*cobra.Command{releaseCmd, releasePromoteCmd} {
+		c.Flags().Bool("publish", false, "Run the configured build commands, push the tag and upload the assets to a GitHub Release")
+	}
+	releaseCmd.Flags().String("bump", "", "Part of the version to bump: major, minor or patch (default: from commits)")
+	releaseCmd.Flags().String("pre", "", "Pre-release channel, e.g. rc or beta")
+	releaseCmd.Flags().String("build", "", "Build metadata to append, e.g. build.7")
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ReleaseConfig configures what happens after a release tag is created.
+type ReleaseConfig struct {
+	// BuildCommands are shell commands run after tagging; $VERSION holds the tag.
+	BuildCommands []string `json:"build_commands,omitempty"`
+	// Assets are glob patterns of files to upload to the GitHub Release.
+	Assets []string `json:"assets,omitempty"`
+}
+
+// githubRelease is the part of the GitHub release API response we use.
+type githubRelease struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+// runBuildCommands runs the configured build commands with VERSION set to the tag.
+func runBuildCommands(commands []string, tag string) error {
+	for _, command := range commands {
+		if skipReadOnly(command) {
+			continue
+		}
+		fmt.Printf("Executing: %s\n", command)
+		c := exec.CommandContext(baseContext, "sh", "-c", command)
+		c.Env = append(os.Environ(), "VERSION="+tag)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("build command '%s' failed: %w", command, err)
+		}
+	}
+	return nil
+}
+
+// releaseAssets expands the configured asset patterns into file paths.
+func releaseAssets(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern '%s': %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset pattern '%s' matched no files", pattern)
+		}
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				return nil, fmt.Errorf("asset '%s' is not a file", path)
+			}
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// publishGitHubRelease runs the build commands, checks that the assets exist,
+// pushes the tag, creates the GitHub Release for the tag, and uploads the
+// assets to it. A failed build leaves the tag unpushed, so it can be fixed
+// and retried without a published tag that has no release.
+func publishGitHubRelease(cfg Config, tag, notes string, prerelease bool) error {
+	client, err := newGitHubClient(cfg)
+	if err != nil {
+		return err
+	}
+	owner, repo, err := githubRepo()
+	if err != nil {
+		return err
+	}
+
+	if err := runBuildCommands(cfg.Release.BuildCommands, tag); err != nil {
+		return fmt.Errorf("%w; tag '%s' was created locally but not pushed", err, tag)
+	}
+	// With --read-only the build commands did not run, so the assets may not exist yet.
+	assets, err := releaseAssets(cfg.Release.Assets)
+	if err != nil && !readOnly {
+		return fmt.Errorf("%w; tag '%s' was created locally but not pushed", err, tag)
+	}
+	if err := runGit("push", "origin", tag); err != nil {
+		return fmt.Errorf("failed to push tag: %w", err)
+	}
+
+	var release githubRelease
+	if err := client.postJSON(fmt.Sprintf("/repos/%s/%s/releases", owner, repo), map[string]interface{}{
+		"tag_name":   tag,
+		"name":       tag,
+		"body":       notes,
+		"prerelease": prerelease,
+	}, &release); err != nil {
+		return fmt.Errorf("failed to create GitHub release: %w", err)
+	}
+
+	for _, path := range assets {
+		if err := uploadReleaseAsset(client, owner, repo, release.ID, path); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("GitHub release published: %s\n", release.HTMLURL)
+	return nil
+}
+
+// uploadReleaseAsset uploads one file to a GitHub Release.
+func uploadReleaseAsset(client *githubClient, owner, repo string, releaseID int64, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	uploadURL := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets?name=%s",
+		client.uploadURL, owner, repo, releaseID, url.QueryEscape(filepath.Base(path)))
+
+	fmt.Printf("Uploading %s...\n", path)
+	if err := client.do(http.MethodPost, uploadURL, contentType, file, nil); err != nil {
+		return fmt.Errorf("failed to upload '%s': %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	"golang.org/x/term"
+)
+
+// Environment variables naming the file to record a run to, or to replay one
+// from. A recording holds every answer to a prompt, git and other command and
+// API request of a run, so it can be replayed without a terminal, a
+// repository or network access: in integration tests of the interactive
+// flows, or to reproduce a bug report.
+const (
+	recordEnv = "GIT_HELPER_RECORD"
+	replayEnv = "GIT_HELPER_REPLAY"
+)
+
+// Kinds of recorded calls.
+const (
+	replayPrompt   = "prompt"
+	replayTerminal = "terminal"
+	replayGit      = "git"
+	replayCommand  = "command"
+	replayHTTP     = "http"
+)
+
+// replayEvent is one recorded call and its outcome, stored as a JSON line.
+type replayEvent struct {
+	Kind string `json:"kind"`
+	// Call identifies the call: the prompt message, the command line or the
+	// method and URL of a request.
+	Call string `json:"call"`
+	// Answer is the JSON of a prompt's answer or of whether stdin is a terminal.
+	Answer json.RawMessage `json:"answer,omitempty"`
+	// Output is the output of a command or the body of a response.
+	Output string `json:"output,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	used bool
+}
+
+// err returns the recorded error, if any.
+func (e replayEvent) err() error {
+	if e.Error == "" {
+		return nil
+	}
+	return errors.New(e.Error)
+}
+
+var (
+	replayMu sync.Mutex
+	// recordFile is the recording being written, if any.
+	recordFile *os.File
+	// replayPath is the recording being replayed, if any, and replayEvents its calls.
+	replayPath   string
+	replayEvents []replayEvent
+)
+
+// startReplay starts recording or replaying, as set in the environment.
+func startReplay() error {
+	record, replay := os.Getenv(recordEnv), os.Getenv(replayEnv)
+	switch {
+	case record != "" && replay != "":
+		return fmt.Errorf("%s and %s cannot both be set", recordEnv, replayEnv)
+	case record != "":
+		file, err := os.Create(record)
+		if err != nil {
+			return fmt.Errorf("failed to create the recording: %w", err)
+		}
+		recordFile = file
+	case replay != "":
+		events, err := loadRecording(replay)
+		if err != nil {
+			return err
+		}
+		replayPath, replayEvents = replay, events
+	default:
+		return nil
+	}
+	// Every API client uses the default transport.
+	http.DefaultTransport = replayTransport{next: http.DefaultTransport}
+	return nil
+}
+
+// loadRecording reads the calls of a recording.
+func loadRecording(path string) ([]replayEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the recording: %w", err)
+	}
+	defer file.Close()
+	var events []replayEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		var e replayEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// finishReplay closes the recording, or checks that every recorded call was
+// replayed: a flow that makes fewer calls than it used to has changed too.
+func finishReplay() error {
+	if recordFile != nil {
+		return recordFile.Close()
+	}
+	var missed []string
+	for _, e := range replayEvents {
+		if !e.used {
+			missed = append(missed, e.Kind+": "+e.Call)
+		}
+	}
+	if len(missed) > 0 {
+		return fmt.Errorf("replaying %s: recorded calls were not made:\n  %s", replayPath, strings.Join(missed, "\n  "))
+	}
+	return nil
+}
+
+// record appends a call to the recording, if one is being written.
+func record(e replayEvent) {
+	if recordFile == nil {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		fmt.Printf("Warning: failed to record %s: %v\n", e.Call, err)
+		return
+	}
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	if _, err := recordFile.Write(append(data, '\n')); err != nil {
+		fmt.Printf("Warning: failed to write the recording: %v\n", err)
+	}
+}
+
+// replayed returns the recorded outcome of a call; ok is false when not
+// replaying. Calls are matched by kind and call, in recorded order, so
+// concurrent calls replay too. A call that was not recorded means the flow
+// has changed, and ends the run.
+func replayed(kind, call string) (e replayEvent, ok bool) {
+	if replayPath == "" {
+		return e, false
+	}
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	for i := range replayEvents {
+		if e := &replayEvents[i]; !e.used && e.Kind == kind && e.Call == call {
+			e.used = true
+			return *e, true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Error: replaying %s: no recorded %s call left for %q\n", replayPath, kind, call)
+	os.Exit(1)
+	return e, false
+}
+
+// askOne asks a question like survey.AskOne, with the configured prompt
+// backend, recording or replaying the answer.
+func askOne(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+	message := promptMessage(p)
+	if e, ok := replayed(replayPrompt, message); ok {
+		if e.Error != "" {
+			return e.err()
+		}
+		return json.Unmarshal(e.Answer, response)
+	}
+	err := ask(p, response, opts...)
+	if recordFile != nil {
+		e := replayEvent{Kind: replayPrompt, Call: message}
+		if err != nil {
+			e.Error = err.Error()
+		} else if e.Answer, err = json.Marshal(response); err != nil {
+			return err
+		}
+		record(e)
+	}
+	return err
+}
+
+// promptMessage returns the question a prompt asks, which identifies it in recordings.
+func promptMessage(p survey.Prompt) string {
+	switch p := p.(type) {
+	case *survey.Select:
+		return p.Message
+	case *survey.MultiSelect:
+		return p.Message
+	case *survey.Input:
+		return p.Message
+	case *survey.Confirm:
+		return p.Message
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// stdinIsTerminal reports whether the user can be asked questions. A replay
+// answers as the recorded run did.
+func stdinIsTerminal() bool {
+	var answer bool
+	if e, ok := replayed(replayTerminal, "stdin"); ok {
+		json.Unmarshal(e.Answer, &answer)
+		return answer
+	}
+	answer = term.IsTerminal(int(os.Stdin.Fd()))
+	if recordFile != nil {
+		data, _ := json.Marshal(answer)
+		record(replayEvent{Kind: replayTerminal, Call: "stdin", Answer: data})
+	}
+	return answer
+}
+
+// recordCall records the outcome of a git or other command.
+func recordCall(kind, call, output string, err error) {
+	if recordFile == nil {
+		return
+	}
+	e := replayEvent{Kind: kind, Call: call, Output: output}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	record(e)
+}
+
+// recordOutput returns a writer that passes output to w and, while
+// recording, keeps a copy in buf.
+func recordOutput(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if recordFile == nil {
+		return w
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// replayTransport records or replays API requests. Headers are left out, so
+// the credentials sent in them never end up in a recording.
+type replayTransport struct {
+	next http.RoundTripper
+}
+
+func (t replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := req.Method + " " + req.URL.String()
+	if e, ok := replayed(replayHTTP, call); ok {
+		if e.Error != "" {
+			return nil, e.err()
+		}
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+			StatusCode: e.Status,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(e.Output)),
+			Request:    req,
+		}, nil
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		record(replayEvent{Kind: replayHTTP, Call: call, Error: err.Error()})
+		return nil, err
+	}
+	if recordFile != nil {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		record(replayEvent{Kind: replayHTTP, Call: call, Status: resp.StatusCode, Output: string(body)})
+	}
+	return resp, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// replayFlow runs a command against a recording in testdata/replay, fails if
+// it made fewer calls than recorded, and returns what it printed and the git
+// commands it ran.
+func replayFlow(t *testing.T, recording string, args ...string) (string, []string) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(recordEnv, "")
+	t.Setenv(replayEnv, filepath.Join("testdata", "replay", recording))
+	if err := os.Mkdir(filepath.Join(home, ".git-helper-cli"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".git-helper-cli", "config.json"), []byte(`{"abbreviation":"lv"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(transport http.RoundTripper) { http.DefaultTransport = transport }(http.DefaultTransport)
+	defer func() { replayPath, replayEvents = "", nil }()
+	// Each run looks up the repository again, as a new process would.
+	repoRoot.Once, repoRoot.path, repoRoot.err = sync.Once{}, "", nil
+	if err := startReplay(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	defer func(f *os.File) { os.Stdout = f }(os.Stdout)
+	os.Stdout = w
+	rootCmd.SetArgs(args)
+	defer rootCmd.SetArgs(nil)
+	runErr := rootCmd.ExecuteContext(context.Background())
+	w.Close()
+	printed := <-output
+
+	if runErr != nil {
+		t.Fatalf("%s: %v\n%s", strings.Join(args, " "), runErr, printed)
+	}
+	if err := finishReplay(); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	for _, e := range replayEvents {
+		if e.Kind == replayGit {
+			calls = append(calls, e.Call)
+		}
+	}
+	return printed, calls
+}
+
+func TestReplayCreateBranch(t *testing.T) {
+	out, calls := replayFlow(t, "create-branch.jsonl", "create-branch")
+	if want := "git checkout -b lv-fix-user-details-window-width/CPRE-11347"; !slices.Contains(calls, want) {
+		t.Errorf("create-branch did not run %q; ran:\n  %s", want, strings.Join(calls, "\n  "))
+	}
+	for _, want := range []string{
+		"Proposed branch name: lv-fix-user-details-window-width/CPRE-11347",
+		"Branch created and switched successfully!",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReplayCreateCommit(t *testing.T) {
+	out, calls := replayFlow(t, "create-commit.jsonl", "create-commit")
+	want := "git commit -m 'fix(lego): handle empty playlists' -m 'Fixes CPRE-11347' -m 'Ticket: CPRE-11347\nProduct: lego\nHelper-Version: dev'"
+	if !slices.Contains(calls, want) {
+		t.Errorf("create-commit did not run %q; ran:\n  %s", want, strings.Join(calls, "\n  "))
+	}
+	for _, want := range []string{
+		"Message 1: fix(lego): handle empty playlists",
+		"Message 2: Fixes CPRE-11347",
+		"Commit created successfully!",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
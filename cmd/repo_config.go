@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repoConfigFile is the name of the repository configuration at the repo root.
+const repoConfigFile = ".git-helper.json"
+
+// RepoConfig is the part of the configuration a repository can set for everyone
+// working in it, by committing a .git-helper.json file at its root. It only
+// covers the conventions: credentials and service endpoints stay personal, so
+// a cloned repository cannot redirect them.
+type RepoConfig struct {
+	BranchTypes      []string                     `json:"branch_types,omitempty"`
+	CommitTypes      []string                     `json:"commit_types,omitempty"`
+	Products         []string                     `json:"products,omitempty"`
+	TicketProjects   []string                     `json:"ticket_projects,omitempty"`
+	ReservedBranches []string                     `json:"reserved_branches,omitempty"`
+	BaseBranches     []string                     `json:"base_branches,omitempty"`
+	Environments     []string                     `json:"environments,omitempty"`
+	BranchTemplate   string                       `json:"branch_template,omitempty"`
+	CommitTemplate   *CommitTemplateConfig        `json:"commit_template,omitempty"`
+	Limits           LimitsConfig                 `json:"limits,omitzero"`
+	Style            *StyleConfig                 `json:"style,omitempty"`
+	Trunk            *TrunkConfig                 `json:"trunk,omitempty"`
+	Prompts          map[string]FlowPromptsConfig `json:"prompts,omitempty"`
+	CustomFields     []CustomStep                 `json:"custom_fields,omitempty"`
+	// RequireSigning makes create-commit sign every commit, for repositories
+	// whose protected branches only accept signed commits. The key stays personal.
+	RequireSigning bool `json:"require_signing,omitempty"`
+}
+
+// repoConfigPath returns the path of the repository configuration of the
+// current repository, or "" outside a repository.
+func repoConfigPath() string {
+	root, err := repoKey()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(root, repoConfigFile)
+}
+
+// loadRepoConfig reads the repository configuration. ok is false when the
+// repository has none.
+func loadRepoConfig() (repoCfg RepoConfig, ok bool, err error) {
+	path := repoConfigPath()
+	if path == "" {
+		return repoCfg, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// No repository configuration.
+		return repoCfg, false, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	// Reject settings that cannot be set per repository instead of ignoring them.
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&repoCfg); err != nil {
+		return repoCfg, false, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return repoCfg, true, nil
+}
+
+// apply overrides the settings of cfg that the repository configuration sets.
+func (r RepoConfig) apply(cfg Config) Config {
+	if len(r.BranchTypes) > 0 {
+		cfg.BranchTypes = r.BranchTypes
+	}
+	if len(r.CommitTypes) > 0 {
+		cfg.CommitTypes = r.CommitTypes
+	}
+	if len(r.Products) > 0 {
+		cfg.Products = r.Products
+	}
+	if len(r.TicketProjects) > 0 {
+		cfg.TicketProjects = r.TicketProjects
+	}
+	if len(r.ReservedBranches) > 0 {
+		cfg.ReservedBranches = r.ReservedBranches
+	}
+	if len(r.BaseBranches) > 0 {
+		cfg.BaseBranches = r.BaseBranches
+	}
+	if len(r.Environments) > 0 {
+		cfg.Environments = r.Environments
+	}
+	if r.BranchTemplate != "" {
+		cfg.BranchTemplate = r.BranchTemplate
+	}
+	if r.CommitTemplate != nil {
+		cfg.CommitTemplate = *r.CommitTemplate
+	}
+	if r.Limits.BranchDescription > 0 {
+		cfg.Limits.BranchDescription = r.Limits.BranchDescription
+	}
+	if r.Limits.CommitDescription > 0 {
+		cfg.Limits.CommitDescription = r.Limits.CommitDescription
+	}
+	if r.Style != nil {
+		cfg.Style = *r.Style
+	}
+	if r.Trunk != nil {
+		cfg.Trunk = *r.Trunk
+	}
+	if len(r.CustomFields) > 0 {
+		cfg.CustomFields = r.CustomFields
+	}
+	if r.RequireSigning {
+		cfg.Signing.Commits = true
+	}
+	// A repository's flows replace the user's, flow by flow.
+	if len(r.Prompts) > 0 {
+		prompts := maps.Clone(cfg.Prompts)
+		if prompts == nil {
+			prompts = map[string]FlowPromptsConfig{}
+		}
+		maps.Copy(prompts, r.Prompts)
+		cfg.Prompts = prompts
+	}
+	return cfg
+}
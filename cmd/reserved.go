@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// defaultReservedBranches are the branch name patterns reserved for automation
+// when the config file does not list its own.
+var defaultReservedBranches = []string{"main", "master*", "develop", "release/*", "prod*"}
+
+// reservedBranchPatterns returns the configured reserved patterns, or the defaults.
+func (c Config) reservedBranchPatterns() []string {
+	if len(c.ReservedBranches) > 0 {
+		return c.ReservedBranches
+	}
+	return defaultReservedBranches
+}
+
+// validateReservedBranches reports the reserved patterns that are not valid
+// glob patterns, which would otherwise never match anything.
+func validateReservedBranches(patterns []string) error {
+	var bad []string
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			bad = append(bad, "'"+pattern+"'")
+		}
+	}
+	switch len(bad) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("invalid reserved_branches pattern %s", bad[0])
+	}
+	return fmt.Errorf("invalid reserved_branches patterns %s", strings.Join(bad, ", "))
+}
+
+// checkReservedBranch refuses branch names matching a reserved pattern. A
+// pattern matches the whole name or any of its leading directories, so
+// "prod*" reserves "production" as well as "prod-fix/ABC-1".
+func checkReservedBranch(cfg Config, name string) error {
+	segments := strings.Split(name, "/")
+	for _, pattern := range cfg.reservedBranchPatterns() {
+		for i := 1; i <= len(segments); i++ {
+			ok, err := path.Match(pattern, strings.Join(segments[:i], "/"))
+			if err != nil {
+				return fmt.Errorf("invalid reserved_branches pattern '%s': %w", pattern, err)
+			}
+			if ok {
+				return fmt.Errorf("branch name '%s' is reserved (matches '%s')", name, pattern)
+			}
+		}
+	}
+	return nil
+}
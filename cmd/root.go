@@ -4,29 +4,100 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
 
+// version is the release of the tool, set at build time with
+// -ldflags "-X github.com/abhinav-lv-amagi/amagi-git-helper/cmd.version=v1.2.3".
+var version = "dev"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gh",
-	Short: "A simple tool to help you with the branch name and commit message conventions at Amagi.",
+	Use:     "gh",
+	Version: version,
+	Short:   "A simple tool to help you with the branch name and commit message conventions at Amagi.",
 	Long: `
 This tool is for you if you keep forgetting to add the JIRA ticket to your branch name and commit messages. 
 Just answer the prompts and everything else will be taken care of. 
-Try running gh --help to see the list of commands.
+Try running gh --help to see the list of commands, or run gh without arguments to pick one from a searchable list.
 	`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	// Run: func(cmd *cobra.Command, args []string) { },
+	// Read-only mode, the branch name and commit message formats, the audit trail of incident branches, the locale, timeouts and cancellation apply to every subcommand, so it is resolved before any of them runs.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		flag, _ := cmd.Flags().GetBool("read-only")
+		dryRun, _ = cmd.Flags().GetBool("dry-run")
+		readOnly = flag || cfg.ReadOnly || dryRun
+		baseContext = cmd.Context()
+		for _, configure := range []func() error{
+			func() error { return configureTemplates(cfg) },
+			func() error { return validateReservedBranches(cfg.reservedBranchPatterns()) },
+			func() error { return configureLocale(cfg.Locale) },
+			func() error { return configurePrompts(cfg.PromptBackend) },
+			func() error { return configureHyperlinks(cfg) },
+			func() error { return configureStorage(cfg.Storage) },
+			func() error { return configureTimeouts(cfg.Timeouts) },
+		} {
+			if err := configure(); err != nil {
+				// The config commands are how a bad setting gets fixed, so
+				// they run with the defaults instead.
+				if !isConfigCommand(cmd) {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		// Hooks run on every commit and change nothing, so they skip the audit trail.
+		if cmd.Annotations[hookAnnotation] == "" {
+			resumeAudit()
+		}
+		return nil
+	},
+	// Running the bare command opens the interactive command palette.
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPalette(cmd)
+	},
+}
+
+// isConfigCommand reports whether cmd shows or edits the configuration.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configCmd || c == showConfigCmd {
+			return true
+		}
+	}
+	return false
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	// The first Ctrl+C cancels running git commands and API requests; once it
+	// has, the default handling is restored so a second Ctrl+C exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := startReplay(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	err := rootCmd.ExecuteContext(ctx)
+	if replayErr := finishReplay(); replayErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", replayErr)
+		if err == nil {
+			err = replayErr
+		}
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -38,6 +109,8 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.git-helper-cli.yaml)")
+	rootCmd.PersistentFlags().Bool("read-only", false, "Block commits, pushes, checkouts and API writes; only report what would be done")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Print the git commands that would change anything, such as checkouts, commits and pushes, without running them")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverPattern matches versions such as v1.4.0, 1.4.0-rc.1 or v1.4.0+build.7.
+var semverPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Version is a semantic version.
+type Version struct {
+	Prefix string // "v" or ""
+	Major  int
+	Minor  int
+	Patch  int
+	Pre    string // pre-release, e.g. "rc.1"
+	Build  string // build metadata, e.g. "build.7"
+}
+
+// parseVersion parses a version such as v1.4.0 or v1.4.0-rc.1.
+func parseVersion(s string) (Version, error) {
+	m := semverPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("'%s' is not a semantic version like v1.4.0 or v1.4.0-rc.1", s)
+	}
+	v := Version{Prefix: m[1], Pre: m[5], Build: m[6]}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// core returns the version without pre-release and build metadata.
+func (v Version) core() Version {
+	return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// channel returns the pre-release channel and number, e.g. ("rc", 2) for
+// "rc.2". The number is 0 if the pre-release has none.
+func (v Version) channel() (string, int) {
+	name, num, _ := strings.Cut(v.Pre, ".")
+	n, _ := strconv.Atoi(num)
+	return name, n
+}
+
+// bump returns the next version for the given part: "major", "minor" or "patch".
+func (v Version) bump(part string) Version {
+	switch part {
+	case "major":
+		return Version{Prefix: v.Prefix, Major: v.Major + 1}
+	case "minor":
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+// compareVersions orders versions by semantic version precedence, returning
+// -1, 0 or 1. Build metadata is ignored, as the spec requires.
+func compareVersions(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	}
+
+	// Compare dot-separated pre-release identifiers; numeric ones sort numerically
+	// and before alphanumeric ones.
+	ap, bp := strings.Split(a.Pre, "."), strings.Split(b.Pre, ".")
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		an, aErr := strconv.Atoi(ap[i])
+		bn, bErr := strconv.Atoi(bp[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(ap[i], bp[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(ap) - len(bp))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
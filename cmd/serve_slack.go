@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Slack rejects replayed requests older than this.
+const slackMaxRequestAge = 5 * time.Minute
+
+// SlackConfig holds the settings of the Slack slash-command bridge.
+type SlackConfig struct {
+	// SigningSecret verifies that requests come from Slack.
+	SigningSecret string `json:"signing_secret,omitempty"`
+	// Abbreviations maps Slack user IDs or names to branch abbreviations.
+	Abbreviations map[string]string `json:"abbreviations,omitempty"`
+}
+
+// slackUsage is returned when a slash command cannot be parsed.
+const slackUsage = "Usage: /branchname <TICKET> <type> <short description>, e.g. /branchname CPRE-11347 fix user window width"
+
+// verifySlackSignature checks the X-Slack-Signature header of a request body.
+func verifySlackSignature(secret string, header http.Header, body []byte) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid request timestamp")
+	}
+	if time.Since(time.Unix(sec, 0)).Abs() > slackMaxRequestAge {
+		return fmt.Errorf("request timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// slackAbbreviation works out the branch abbreviation of the Slack user who
+// sent the command: a configured mapping, or else the first two letters of
+// their user name.
+func slackAbbreviation(cfg Config, form url.Values) string {
+	for _, key := range []string{form.Get("user_id"), form.Get("user_name")} {
+		if abbrev, ok := cfg.Slack.Abbreviations[key]; ok {
+			return abbrev
+		}
+	}
+	name := form.Get("user_name")
+	if len(name) >= 2 {
+		return name[:2]
+	}
+	return "xx"
+}
+
+// slackBranchName computes the branch name for a slash command such as
+// "CPRE-11347 fix user window width", using the same rules as create-branch.
+func slackBranchName(cfg Config, form url.Values) (string, string, error) {
+	fields := strings.Fields(form.Get("text"))
+	if len(fields) < 3 {
+		return "", "", fmt.Errorf("expected a ticket, a type and a description")
+	}
+	ticketID, branchType := strings.ToUpper(fields[0]), strings.ToLower(fields[1])
+	if err := cfg.validateTicketID(ticketID); err != nil {
+		return "", "", err
+	}
+	if err := convention.ValidateChoice("branch type", branchType, cfg.branchTypes()); err != nil {
+		return "", "", err
+	}
+	description, err := convention.FormatDescription(strings.Join(fields[2:], " "), cfg.Limits.branchDescription())
+	if err != nil {
+		return "", "", err
+	}
+
+	branchName, err := branchTemplate.Render(convention.Branch{
+		Abbreviation: slackAbbreviation(cfg, form),
+		Type:         branchType,
+		Description:  description,
+		Ticket:       ticketID,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := checkReservedBranch(cfg, branchName); err != nil {
+		return "", "", err
+	}
+	return branchName, ticketID, nil
+}
+
+// slackHandler serves the slash-command endpoint.
+func slackHandler(cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+			return
+		}
+		if cfg.Slack.SigningSecret != "" {
+			if err := verifySlackSignature(cfg.Slack.SigningSecret, r.Header, body); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			http.Error(w, "invalid form body", http.StatusBadRequest)
+			return
+		}
+
+		// Errors are shown to the user in Slack, so they are sent as normal replies.
+		var text string
+		if branchName, ticketID, err := slackBranchName(cfg, form); err != nil {
+			text = fmt.Sprintf("%s\n%s", err, slackUsage)
+		} else {
+			text = fmt.Sprintf("`%s`", branchName)
+			if link := ticketURL(cfg, ticketID); link != "" {
+				text += fmt.Sprintf("\nJIRA: <%s|%s>", link, ticketID)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"response_type": "ephemeral",
+			"text":          text,
+		})
+	}
+}
+
+// serveSlackCmd represents the command to run the Slack slash-command bridge.
+var serveSlackCmd = &cobra.Command{
+	Use:   "serve-slack",
+	Short: "Serve a Slack slash command that returns conventional branch names",
+	Long: `Run an HTTP server for a Slack slash command such as:
+
+  /branchname CPRE-11347 fix user window width
+
+which replies with the branch name create-branch would produce, plus a link to
+the JIRA ticket if "jira.base_url" is configured. Point the slash command's
+request URL at http://<host>:<port>/slack/branchname.
+
+Set "slack.signing_secret" in the config file to verify that requests come from
+Slack, and "slack.abbreviations" to map Slack user IDs or names to abbreviations.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		if cfg.Slack.SigningSecret == "" {
+			fmt.Println("Warning: no slack.signing_secret configured; requests will not be verified.")
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/slack/branchname", slackHandler(cfg))
+
+		fmt.Printf("Listening for Slack commands on %s/slack/branchname\n", addr)
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		return serveUntilCancelled(server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveSlackCmd)
+	serveSlackCmd.Flags().String("addr", ":8080", "Address to listen on")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// storeHandler serves the metadata of a team from a SQL store: GET and PUT
+// /metadata, and GET /branches?ticket=<ticket>. tokens maps each bearer token
+// to its owner, whose metadata /metadata serves.
+func storeHandler(s *sqlStore, tokens map[string]string) http.Handler {
+	authorized := func(w http.ResponseWriter, r *http.Request) (string, bool) {
+		given := r.Header.Get("Authorization")
+		for token, owner := range tokens {
+			if subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) == 1 {
+				return owner, true
+			}
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	reply := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			fmt.Printf("Failed to write response: %v\n", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metadata", func(w http.ResponseWriter, r *http.Request) {
+		owner, ok := authorized(w, r)
+		if !ok {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			md, version, err := s.forOwner(owner).loadStored()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			stored := storedMetadata{Version: version}
+			if version != "" {
+				stored.Metadata = &md
+			}
+			reply(w, stored)
+		case http.MethodPut:
+			var stored storedMetadata
+			if err := json.NewDecoder(io.LimitReader(r.Body, 20<<20)).Decode(&stored); err != nil || stored.Metadata == nil {
+				http.Error(w, "invalid metadata", http.StatusBadRequest)
+				return
+			}
+			version, err := s.forOwner(owner).saveIf(*stored.Metadata, stored.Version)
+			if errors.Is(err, errStaleMetadata) {
+				http.Error(w, "the metadata changed since it was read; run the command again", http.StatusConflict)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			reply(w, storedMetadata{Version: version})
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/branches", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorized(w, r); !ok {
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		branches, err := s.ticketBranches(r.URL.Query().Get("ticket"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if branches == nil {
+			branches = []sharedBranch{}
+		}
+		reply(w, branches)
+	})
+	return mux
+}
+
+// loadStoreTokens reads the tokens file of serve-store, a JSON object of
+// owners and their tokens, and returns the owner of each token.
+func loadStoreTokens(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tokens file: %w", err)
+	}
+	var owners map[string]string
+	if err := json.Unmarshal(data, &owners); err != nil {
+		return nil, fmt.Errorf("invalid tokens file %s: %w", path, err)
+	}
+	tokens := map[string]string{}
+	for owner, token := range owners {
+		switch {
+		case owner == "":
+			return nil, fmt.Errorf("invalid tokens file %s: owners cannot be empty", path)
+		case len(token) < 16:
+			return nil, fmt.Errorf("invalid tokens file %s: the token of %s must be at least 16 characters", path, owner)
+		case tokens[token] != "":
+			return nil, fmt.Errorf("invalid tokens file %s: %s and %s have the same token", path, tokens[token], owner)
+		}
+		tokens[token] = owner
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("the tokens file %s lists no one", path)
+	}
+	return tokens, nil
+}
+
+// serveStoreCmd represents the command to serve a shared metadata store over HTTP.
+var serveStoreCmd = &cobra.Command{
+	Use:   "serve-store",
+	Short: "Serve a metadata store shared by a team over HTTP",
+	Long: `Run an HTTP service that keeps the metadata of everyone on a team, so their
+ticket branches can be looked up across developers. Team members point
+"storage": {"backend": "http", "url": "http://<host>:8084", "token": "..."}
+at it, and nobody needs database credentials.
+
+The service itself keeps the metadata in the SQLite or Postgres store set by
+storage.backend in its own config file. Everyone gets their own token, listed
+in the file given with --tokens or GIT_HELPER_STORE_TOKENS as a JSON object of
+owners and tokens, e.g. {"alice@example.com": "<token>"}. Requests must carry
+one as a bearer token, and only read and change the metadata of its owner.
+A change to metadata that someone else changed since it was read is rejected,
+so concurrent commands never overwrite each other.`,
+	Example: `  GIT_HELPER_STORE_TOKENS=/etc/git-helper/tokens.json gh serve-store --addr :8084`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := store.(*sqlStore); !ok {
+			return fmt.Errorf("serve-store needs storage.backend sqlite or postgres to keep the metadata in")
+		}
+		path, _ := cmd.Flags().GetString("tokens")
+		if path == "" {
+			path = os.Getenv("GIT_HELPER_STORE_TOKENS")
+		}
+		if path == "" {
+			return fmt.Errorf("serve-store needs --tokens or GIT_HELPER_STORE_TOKENS, a file listing everyone's token")
+		}
+		tokens, err := loadStoreTokens(path)
+		if err != nil {
+			return err
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		fmt.Printf("Serving the metadata store of %d people on %s\n", len(tokens), addr)
+		server := &http.Server{Addr: addr, Handler: storeHandler(store.(*sqlStore), tokens), ReadHeaderTimeout: 10 * time.Second}
+		return serveUntilCancelled(server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveStoreCmd)
+	serveStoreCmd.Flags().String("addr", ":8084", "Address to listen on")
+	serveStoreCmd.Flags().String("tokens", "", "JSON file of owners and their tokens (default: $GIT_HELPER_STORE_TOKENS)")
+}
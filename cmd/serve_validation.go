@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// validationRequest is the body of a request to the validation endpoint.
+type validationRequest struct {
+	Branch  string   `json:"branch,omitempty"`
+	Commits []string `json:"commits,omitempty"`
+}
+
+// validationResult is the outcome of validating one branch name or commit message.
+type validationResult struct {
+	Subject    string                 `json:"subject"`
+	Valid      bool                   `json:"valid"`
+	Violations []convention.Violation `json:"violations,omitempty"`
+}
+
+// validationResponse is the body of a response from the validation endpoint.
+type validationResponse struct {
+	Valid   bool               `json:"valid"`
+	Branch  *validationResult  `json:"branch,omitempty"`
+	Commits []validationResult `json:"commits,omitempty"`
+}
+
+// validate checks the branch and commit messages of a request against the rules.
+// Merge commits are accepted as-is, like lint-commits skips them.
+func validate(rules convention.Rules, req validationRequest) validationResponse {
+	resp := validationResponse{Valid: true}
+	if req.Branch != "" {
+		result := validationResult{Subject: req.Branch, Valid: true}
+		if err := rules.ValidateBranch(req.Branch); err != nil {
+			result.Valid = false
+			result.Violations = []convention.Violation{{Rule: convention.RuleBranchName, Message: err.Error()}}
+		}
+		resp.Branch = &result
+		resp.Valid = resp.Valid && result.Valid
+	}
+	for _, message := range req.Commits {
+		header := firstLine(message)
+		result := validationResult{Subject: header, Valid: true}
+		if !strings.HasPrefix(header, "Merge ") {
+			result.Violations = rules.ValidateCommitHeader(header)
+			result.Valid = len(result.Violations) == 0
+		}
+		resp.Commits = append(resp.Commits, result)
+		resp.Valid = resp.Valid && result.Valid
+	}
+	return resp
+}
+
+// validationHandler serves the validation endpoint.
+func validationHandler(rules convention.Rules) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 5<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		var req validationRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(validate(rules, req)); err != nil {
+			fmt.Printf("Failed to write response: %v\n", err)
+		}
+	}
+}
+
+// serveValidationCmd represents the command to serve the convention rules over HTTP.
+var serveValidationCmd = &cobra.Command{
+	Use:   "serve-validation",
+	Short: "Serve branch name and commit message validation over HTTP",
+	Long: `Run an HTTP server that validates branch names and commit messages with
+exactly the same rules as the CLI, for server-side hooks (e.g. pre-receive on
+the git server) and bots.
+
+POST a JSON body to http://<host>:<port>/validate:
+
+  {"branch": "lv-fix-window-width/CPRE-11347", "commits": ["fix(lego): handle empty playlists"]}
+
+The response reports each branch and commit with its violations, and "valid"
+is true only when everything passes:
+
+  {"valid": false, "commits": [{"subject": "...", "valid": false, "violations": [{"rule": "...", "message": "..."}]}]}
+
+Only the first line of each commit message is checked, and merge commits are
+accepted. The rules, limits and style settings come from the config file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		addr, _ := cmd.Flags().GetString("addr")
+		mux := http.NewServeMux()
+		mux.HandleFunc("/validate", validationHandler(cfg.conventionRules()))
+
+		fmt.Printf("Serving validation on %s/validate\n", addr)
+		server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		return serveUntilCancelled(server)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveValidationCmd)
+	serveValidationCmd.Flags().String("addr", ":8083", "Address to listen on")
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Sessions older than this are not offered for resuming.
+const sessionMaxAge = 24 * time.Hour
+
+// savedSession is the on-disk form of an in-progress flow.
+type savedSession struct {
+	Repo    string            `json:"repo"`
+	SavedAt time.Time         `json:"saved_at"`
+	Answers map[string]string `json:"answers"`
+}
+
+// sessionAnswer binds an answer key to the variable holding it.
+type sessionAnswer struct {
+	key   string
+	value *string
+}
+
+// flowSession saves the answers of an interactive flow after every question,
+// so an interrupted flow (crash or Ctrl+C) can be resumed on the next run.
+type flowSession struct {
+	command string
+	answers []sessionAnswer
+	resumed bool
+}
+
+// newFlowSession creates a session for the given command.
+func newFlowSession(command string) *flowSession {
+	return &flowSession{command: command}
+}
+
+// sessionDir returns the directory holding the state files of interrupted flows.
+func sessionDir() (string, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(configPath), "sessions")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// sessionFilePath returns the path of the state file for a command in the
+// current repository, so unfinished sessions in different repositories are
+// kept apart.
+func sessionFilePath(command string) (string, error) {
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+	name := command
+	if repo, err := repoKey(); err == nil {
+		sum := sha256.Sum256([]byte(repo))
+		name += "-" + hex.EncodeToString(sum[:6])
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// step registers the answer stored in value under key and wraps ask so the
+// answer is saved once given. If the answer was restored from a previous
+// session, the question is skipped the first time round.
+func (s *flowSession) step(key string, value *string, ask promptStep) promptStep {
+	s.answers = append(s.answers, sessionAnswer{key: key, value: value})
+	skip := true
+	return func(back bool) error {
+		if skip && s.resumed && *value != "" {
+			skip = false
+			return nil
+		}
+		skip = false
+		if err := ask(back); err != nil {
+			return err
+		}
+		return s.save()
+	}
+}
+
+// save writes the current answers to the state file.
+func (s *flowSession) save() error {
+	path, err := sessionFilePath(s.command)
+	if err != nil {
+		return err
+	}
+	repo, _ := repoKey()
+	state := savedSession{Repo: repo, SavedAt: time.Now(), Answers: map[string]string{}}
+	for _, a := range s.answers {
+		state.Answers[a.key] = *a.value
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(state)
+}
+
+// resume offers to restore the answers of an interrupted session of the same
+// command in the same repository.
+func (s *flowSession) resume() error {
+	path, err := sessionFilePath(s.command)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		// No interrupted session.
+		return nil
+	}
+	var state savedSession
+	err = json.NewDecoder(file).Decode(&state)
+	file.Close()
+
+	// Another repository's session is left for it to resume.
+	if repo, _ := repoKey(); err == nil && state.Repo != repo {
+		return nil
+	}
+	if err != nil || time.Since(state.SavedAt) > sessionMaxAge {
+		s.clear()
+		return nil
+	}
+
+	var summary []string
+	for _, a := range s.answers {
+		if v := state.Answers[a.key]; v != "" {
+			summary = append(summary, fmt.Sprintf("%s: %s", a.key, v))
+		}
+	}
+	if len(summary) == 0 {
+		s.clear()
+		return nil
+	}
+
+	fmt.Printf("Found an unfinished %s session from %s:\n  %s\n", s.command, state.SavedAt.Format(time.Kitchen), strings.Join(summary, "\n  "))
+	resume := true
+	if err := askOne(&survey.Confirm{
+		Message: "Resume where you left off?",
+		Default: true,
+	}, &resume); err != nil {
+		return err
+	}
+	if !resume {
+		s.clear()
+		return nil
+	}
+
+	for _, a := range s.answers {
+		*a.value = state.Answers[a.key]
+	}
+	s.resumed = true
+	return nil
+}
+
+// clear removes the saved state once the flow has finished or been cancelled.
+func (s *flowSession) clear() {
+	if path, err := sessionFilePath(s.command); err == nil {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Signature formats, as git's gpg.format.
+const (
+	signingOpenPGP = "openpgp"
+	signingSSH     = "ssh"
+	signingX509    = "x509"
+)
+
+// signingFormats lists the signature formats git supports.
+var signingFormats = []string{signingOpenPGP, signingSSH, signingX509}
+
+// SigningConfig makes create-commit sign the commits it creates.
+type SigningConfig struct {
+	// Commits signs every commit, as --sign does for one.
+	Commits bool `json:"commits,omitempty"`
+	// Format is openpgp, ssh or x509; by default git's gpg.format.
+	Format string `json:"format,omitempty"`
+	// Key is the key to sign with, e.g. a GPG key ID or the path of an SSH
+	// public key; by default git's user.signingkey.
+	Key string `json:"key,omitempty"`
+}
+
+// commitArgs returns the arguments of a git commit, signed when sign is set.
+// The format and key are passed for this commit only, leaving git's own
+// configuration as it is.
+func (s SigningConfig) commitArgs(sign bool, args ...string) []string {
+	if !sign {
+		return append([]string{"commit"}, args...)
+	}
+	var gitArgs []string
+	if s.Format != "" {
+		gitArgs = append(gitArgs, "-c", "gpg.format="+s.Format)
+	}
+	if s.Key != "" {
+		gitArgs = append(gitArgs, "-c", "user.signingkey="+s.Key)
+	}
+	return append(append(gitArgs, "commit", "-S"), args...)
+}
+
+// gitConfigValue returns a git configuration value, or "" if it is unset.
+func gitConfigValue(key string) string {
+	value, _ := gitOutput("config", "--get", key)
+	return value
+}
+
+// checkSigningKey checks before committing that git will find the program
+// and the key to sign with, so a missing key is explained up front rather
+// than by a failed commit after all the prompts.
+func checkSigningKey(s SigningConfig) error {
+	format := cmp.Or(s.Format, gitConfigValue("gpg.format"), signingOpenPGP)
+	key := cmp.Or(s.Key, gitConfigValue("user.signingkey"))
+	switch format {
+	case signingOpenPGP:
+		program := cmp.Or(gitConfigValue("gpg.openpgp.program"), gitConfigValue("gpg.program"), "gpg")
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("cannot sign commits: %s is not installed; install GnuPG or set gpg.program", program)
+		}
+		// Without a key, gpg picks one by the committer's e-mail, as git does.
+		id := key
+		if id == "" {
+			id = committerEmail()
+		}
+		if err := exec.Command(program, "--list-secret-keys", id).Run(); err != nil {
+			return fmt.Errorf("cannot sign commits: no GPG secret key for '%s'; create one with 'gpg --full-generate-key' and set it with 'git config --global user.signingkey <key id>' or \"signing\": {\"key\": ...} in the config", id)
+		}
+	case signingSSH:
+		program := cmp.Or(gitConfigValue("gpg.ssh.program"), "ssh-keygen")
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("cannot sign commits: %s is not installed; install OpenSSH or set gpg.ssh.program", program)
+		}
+		if key == "" {
+			if gitConfigValue("gpg.ssh.defaultKeyCommand") != "" {
+				return nil
+			}
+			return fmt.Errorf("cannot sign commits: no SSH signing key; set it with 'git config --global user.signingkey ~/.ssh/id_ed25519.pub' or \"signing\": {\"key\": ...} in the config")
+		}
+		// A key given literally needs no file.
+		if strings.HasPrefix(key, "key::") || strings.HasPrefix(key, "ssh-") {
+			return nil
+		}
+		path := key
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, rest)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot sign commits: SSH signing key %s not found", key)
+		}
+	case signingX509:
+		program := cmp.Or(gitConfigValue("gpg.x509.program"), "gpgsm")
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("cannot sign commits: %s is not installed; install it or set gpg.x509.program", program)
+		}
+	default:
+		return fmt.Errorf("unknown signing format '%s'; use %s", format, strings.Join(signingFormats, ", "))
+	}
+	return nil
+}
+
+// committerEmail returns the e-mail address git commits with.
+func committerEmail() string {
+	ident, _ := gitOutput("var", "GIT_COMMITTER_IDENT")
+	if start, end := strings.Index(ident, "<"), strings.Index(ident, ">"); start >= 0 && end > start {
+		return ident[start+1 : end]
+	}
+	return gitConfigValue("user.email")
+}
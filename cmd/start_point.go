@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Number of recent tags offered as start points.
+const recentTagCount = 10
+
+// Start point picker options that are not refs.
+const (
+	startPointHead  = "Current HEAD"
+	startPointOther = "Enter a commit SHA or ref..."
+)
+
+// recentTags returns the most recently created tags, newest first.
+func recentTags(n int) ([]string, error) {
+	out, err := gitOutput("for-each-ref", "--sort=-creatordate", fmt.Sprintf("--count=%d", n), "--format=%(refname:short)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// verifyStartPoint checks that ref names a commit.
+func verifyStartPoint(ref string) error {
+	if _, err := gitOutput("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return fmt.Errorf("'%s' is not a commit, tag or branch", ref)
+	}
+	return nil
+}
+
+// chooseStartPoint lets the user pick where a new branch starts: the current
+// HEAD, a recent tag, a remote branch, or any commit. "" means HEAD.
+func chooseStartPoint() (string, error) {
+	options := []string{startPointHead}
+	tags, err := recentTags(recentTagCount)
+	if err != nil {
+		return "", err
+	}
+	options = append(options, tags...)
+	remotes, err := listRefs("refs/remotes")
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	options = append(options, remotes...)
+	options = append(options, startPointOther)
+
+	var choice string
+	if err := askOne(&survey.Select{
+		Message:  "Start the branch from:",
+		Options:  options,
+		PageSize: 15,
+		Description: func(value string, index int) string {
+			switch {
+			case index > 0 && index <= len(tags):
+				return "tag"
+			case index > len(tags) && index < len(options)-1:
+				return "remote branch"
+			}
+			return ""
+		},
+	}, &choice); err != nil {
+		return "", err
+	}
+
+	switch choice {
+	case startPointHead:
+		return "", nil
+	case startPointOther:
+		var ref string
+		validator := func(val interface{}) error {
+			str, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("invalid input")
+			}
+			return verifyStartPoint(strings.TrimSpace(str))
+		}
+		if err := askOne(&survey.Input{Message: "Commit SHA or ref:"}, &ref, survey.WithValidator(validator)); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(ref), nil
+	}
+	return choice, nil
+}
+
+// baseBranchOptions returns the branches of origin that base_branches names.
+// Patterns such as "release/*" expand to the matching remote branches, most
+// recently updated first; plain names are offered even before they are fetched.
+func baseBranchOptions(cfg Config) ([]string, error) {
+	out, err := gitOutput("for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/remotes/origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	var remote []string
+	for _, ref := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(ref, "origin/"); ok && name != "HEAD" {
+			remote = append(remote, name)
+		}
+	}
+	var options []string
+	for _, pattern := range cfg.BaseBranches {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if !slices.Contains(options, pattern) {
+				options = append(options, pattern)
+			}
+			continue
+		}
+		for _, name := range remote {
+			if ok, _ := path.Match(pattern, name); ok && !slices.Contains(options, name) {
+				options = append(options, name)
+			}
+		}
+	}
+	return options, nil
+}
+
+// askBaseBranch prompts for the branch of origin to start from, or the current HEAD.
+func askBaseBranch(options []string, base *string, back bool) error {
+	return askSelect("Start the branch from:", append([]string{startPointHead}, options...), base, back)
+}
+
+// fetchBaseBranch fetches base from origin, unless fetch is false, and
+// returns the start point origin/<base>.
+func fetchBaseBranch(base string, fetch bool) (string, error) {
+	if fetch {
+		fmt.Printf("Fetching %s from origin...\n", base)
+		if err := runGit("fetch", "origin", base); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", base, err)
+		}
+	}
+	startPoint := "origin/" + base
+	if err := verifyStartPoint(startPoint); err != nil {
+		return "", fmt.Errorf("origin has no branch '%s'", base)
+	}
+	return startPoint, nil
+}
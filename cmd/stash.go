@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// What create-branch does with uncommitted changes, as chosen with --dirty.
+const (
+	dirtyCarry = "carry"
+	dirtyStash = "stash"
+	dirtyAbort = "abort"
+)
+
+// dirtyActions are the valid values of --dirty.
+var dirtyActions = []string{dirtyCarry, dirtyStash, dirtyAbort}
+
+// listStashes returns the entries of `git stash list`, most recent first.
+func listStashes() ([]string, error) {
+	out, err := gitOutput("stash", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// chooseStashSource decides which changes --from-stash should move onto the new
+// branch. A dirty working tree always wins; otherwise the user picks one of the
+// existing stashes. An empty ref means the working tree should be stashed first.
+func chooseStashSource() (string, error) {
+	dirty, err := workingTreeDirty()
+	if err != nil {
+		return "", err
+	}
+	if dirty {
+		fmt.Println("Uncommitted changes will be moved to the new branch.")
+		return "", nil
+	}
+
+	stashes, err := listStashes()
+	if err != nil {
+		return "", err
+	}
+	if len(stashes) == 0 {
+		return "", fmt.Errorf("no uncommitted changes or stashes found to move to a new branch")
+	}
+
+	var index int
+	if err := askOne(&survey.Select{
+		Message: "Choose the stash to move to the new branch:",
+		Options: stashes,
+	}, &index); err != nil {
+		return "", err
+	}
+	// Entries look like "stash@{0}: WIP on main: ...".
+	ref, _, _ := strings.Cut(stashes[index], ":")
+	return ref, nil
+}
+
+// createBranchFromStash creates branchName from startPoint, or the current HEAD
+// if it is empty, and moves the given stash onto it, leaving the original
+// branch clean. When stashRef is empty the uncommitted changes are stashed first.
+func createBranchFromStash(branchName, startPoint, stashRef string) error {
+	stashed := false
+	if stashRef == "" {
+		before := stashTop()
+		if err := runGit("stash", "push", "--include-untracked", "-m", "git-helper: moving changes to "+branchName); err != nil {
+			return fmt.Errorf("failed to stash changes: %w", err)
+		}
+		// stash push exits cleanly when there is nothing to save, so only
+		// a new entry on refs/stash says there is something to pop.
+		if !readOnly && stashTop() == before {
+			return createBranchAt(branchName, startPoint)
+		}
+		stashRef = "stash@{0}"
+		stashed = true
+	}
+
+	if err := createBranchAt(branchName, startPoint); err != nil {
+		if !stashed {
+			return err
+		}
+		if popErr := runGit("stash", "pop", "--index", stashRef); popErr != nil {
+			return fmt.Errorf("%w; your changes are still in %s, run 'git stash pop --index' to get them back", err, stashRef)
+		}
+		return fmt.Errorf("%w; your changes were restored to the current branch", err)
+	}
+
+	// --index keeps staged changes staged. git keeps the stash entry if
+	// popping it results in conflicts.
+	if err := runGit("stash", "pop", "--index", stashRef); err != nil {
+		return fmt.Errorf("branch created, but applying %s failed; resolve the conflicts and run 'git stash drop %s' when done: %w", stashRef, stashRef, err)
+	}
+	return nil
+}
+
+// createBranchAt runs git checkout -b <branchName> [<startPoint>].
+func createBranchAt(branchName, startPoint string) error {
+	checkoutArgs := []string{"checkout", "-b", branchName}
+	if startPoint != "" {
+		// A branch started from origin/main must not push to or pull from it.
+		checkoutArgs = append(checkoutArgs, "--no-track", startPoint)
+	}
+	if err := runGit(checkoutArgs...); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	return nil
+}
+
+// stashTop returns the commit refs/stash points at, or "" when there are no stashes.
+func stashTop() string {
+	out, _ := gitOutput("rev-parse", "-q", "--verify", "refs/stash")
+	return out
+}
+
+// dirtyTreeAction works out what to do with uncommitted changes before
+// switching to a new branch: the given action, or the user's choice. It
+// returns "" when the working tree is clean. Without a terminal to ask on,
+// the changes are carried over, as git would, with a note saying so.
+func dirtyTreeAction(action string) (string, error) {
+	dirty, err := workingTreeDirty()
+	if err != nil || !dirty {
+		return "", err
+	}
+	if action != "" {
+		return action, nil
+	}
+	if !stdinIsTerminal() {
+		fmt.Println("Uncommitted changes will be carried to the new branch; use --dirty stash or --dirty abort to leave them behind.")
+		return dirtyCarry, nil
+	}
+
+	var choice int
+	if err := askOne(&survey.Select{
+		Message: "You have uncommitted changes. What should happen to them?",
+		Options: []string{
+			"Carry them to the new branch",
+			"Stash them for the current branch",
+			"Abort",
+		},
+	}, &choice); err != nil {
+		return "", err
+	}
+	return dirtyActions[choice], nil
+}
+
+// stashWorkInProgress stashes the uncommitted changes, untracked files
+// included, under a name that says which branch and ticket they belong to.
+func stashWorkInProgress() error {
+	branch, err := getCurrentBranch()
+	if err != nil {
+		branch = "HEAD"
+	}
+	message := "git-helper: work in progress on " + branch
+	if ticketID, err := extractTicketFromBranch(branch); err == nil {
+		message = "git-helper: " + ticketID + " work in progress on " + branch
+	}
+	if err := runGit("stash", "push", "--include-untracked", "-m", message); err != nil {
+		return fmt.Errorf("failed to stash changes: %w", err)
+	}
+	fmt.Printf("Changes stashed as \"%s\"; run 'git stash pop' on %s to get them back.\n", message, branch)
+	return nil
+}
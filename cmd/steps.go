@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// errGoBack is returned by a prompt when the user asks to return to the previous question.
+var errGoBack = errors.New("go back")
+
+// backOption is appended to select prompts that can go back.
+const backOption = "← back"
+
+// backInput is the answer that returns to the previous question from a text prompt.
+const backInput = "<"
+
+// promptStep asks one question. back reports whether there is a previous
+// question the user can return to.
+type promptStep func(back bool) error
+
+// runSteps asks the given questions in order. When a step returns errGoBack,
+// the previous step is asked again; answers are kept in the step's variables,
+// so the previous answer is offered as the default.
+func runSteps(steps ...promptStep) error {
+	for i := 0; i < len(steps); {
+		err := steps[i](i > 0)
+		switch {
+		case errors.Is(err, errGoBack):
+			if i > 0 {
+				i--
+			}
+		case err != nil:
+			return err
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// askSelect asks the user to pick one of the options, offering the previous
+// answer as the default and a "← back" entry when back is true.
+func askSelect(message string, options []string, answer *string, back bool) error {
+	prompt := &survey.Select{
+		Message: message,
+		Options: options,
+	}
+	if back {
+		prompt.Options = append(append([]string{}, options...), backOption)
+	}
+	for _, opt := range options {
+		if opt == *answer {
+			prompt.Default = opt
+		}
+	}
+
+	var choice string
+	if err := askOne(prompt, &choice); err != nil {
+		return err
+	}
+	if choice == backOption {
+		return errGoBack
+	}
+	*answer = choice
+	return nil
+}
+
+// askInput asks for free text, offering the previous answer as the default.
+// When back is true, entering "<" returns errGoBack instead of an answer.
+func askInput(message string, answer *string, back bool, validator survey.Validator) error {
+	return askInputSuggest(message, answer, back, validator, nil)
+}
+
+// askInputSuggest is askInput with Tab completion from suggest, if not nil.
+func askInputSuggest(message string, answer *string, back bool, validator survey.Validator, suggest func(toComplete string) []string) error {
+	prompt := &survey.Input{
+		Message: message,
+		Default: *answer,
+		Suggest: suggest,
+	}
+	if back {
+		prompt.Help = fmt.Sprintf("Enter %s to go back to the previous question.", backInput)
+	}
+
+	var opts []survey.AskOpt
+	if validator != nil {
+		opts = append(opts, survey.WithValidator(func(val interface{}) error {
+			if str, ok := val.(string); ok && back && str == backInput {
+				return nil
+			}
+			return validator(val)
+		}))
+	}
+
+	var value string
+	if err := askOne(prompt, &value, opts...); err != nil {
+		return err
+	}
+	if back && value == backInput {
+		return errGoBack
+	}
+	*answer = value
+	return nil
+}
@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Supported values of storage.backend.
+const (
+	storageFile     = "file"
+	storageSQLite   = "sqlite"
+	storagePostgres = "postgres"
+	storageHTTP     = "http"
+)
+
+// storageBackends lists the storage backends.
+var storageBackends = []string{storageFile, storageSQLite, storagePostgres, storageHTTP}
+
+// StorageConfig selects where the metadata of branches, pairing sessions,
+// TODOs and the like is kept.
+type StorageConfig struct {
+	// Backend is file (metadata.json, the default), sqlite, postgres or http.
+	// A Postgres database or a `serve-store` service is shared by a team.
+	Backend string `json:"backend,omitempty"`
+	// Path is the SQLite database; by default metadata.db next to the config file.
+	Path string `json:"path,omitempty"`
+	// URL is the Postgres connection URL or the address of a `serve-store` service.
+	URL string `json:"url,omitempty" env:"GIT_HELPER_STORE_URL"`
+	// Token authenticates against a `serve-store` service.
+	Token string `json:"token,omitempty" env:"GIT_HELPER_STORE_TOKEN"`
+	// Owner names you in a shared database; by default your git user.email.
+	// A `serve-store` service names you after your token instead.
+	Owner string `json:"owner,omitempty"`
+}
+
+// sharedBranch is a branch someone recorded in the store, for reports across
+// developers such as who is working on a ticket.
+type sharedBranch struct {
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	Ticket    string    `json:"ticket"`
+	CreatedAt time.Time `json:"created_at"`
+	PR        string    `json:"pr,omitempty"`
+	// State is open, merged or closed, as last reported by the provider.
+	State string `json:"state"`
+}
+
+// Branch states in a shared store.
+const (
+	branchOpen   = "open"
+	branchMerged = "merged"
+	branchClosed = "closed"
+)
+
+// metadataStore keeps the metadata of one owner. Stores other than the file
+// also record everyone's ticket branches, so they can be looked up across a
+// team.
+type metadataStore interface {
+	load() (Metadata, error)
+	save(md Metadata) error
+	// ticketBranches returns the branches of a ticket recorded by anyone
+	// sharing the store.
+	ticketBranches(ticket string) ([]sharedBranch, error)
+}
+
+// store is the backend chosen with storage.backend.
+var store metadataStore = fileStore{}
+
+// configureStorage selects the storage backend. Connections are only made
+// once the metadata is used.
+func configureStorage(cfg StorageConfig) error {
+	storeURL := cmp.Or(os.Getenv("GIT_HELPER_STORE_URL"), cfg.URL)
+	switch cfg.Backend {
+	case "", storageFile:
+		store = fileStore{}
+	case storageSQLite:
+		path := cfg.Path
+		if path == "" {
+			configPath, err := configFilePath()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(filepath.Dir(configPath), "metadata.db")
+		}
+		store = newSQLStore("sqlite", path, cfg.Owner)
+	case storagePostgres:
+		if storeURL == "" {
+			return fmt.Errorf("storage.backend postgres needs storage.url, e.g. postgres://user@host/githelper")
+		}
+		store = newSQLStore("pgx", storeURL, cfg.Owner)
+	case storageHTTP:
+		if storeURL == "" {
+			return fmt.Errorf("storage.backend http needs storage.url, the address of a 'gh serve-store' service")
+		}
+		token := cmp.Or(os.Getenv("GIT_HELPER_STORE_TOKEN"), cfg.Token)
+		store = &httpStore{
+			client: &restClient{
+				name:    "Store",
+				baseURL: strings.TrimRight(storeURL, "/"),
+				auth:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+				http:    &http.Client{},
+			},
+		}
+	default:
+		return fmt.Errorf("unknown storage.backend '%s'; use %s", cfg.Backend, strings.Join(storageBackends, ", "))
+	}
+	return nil
+}
+
+// storeOwner returns who the metadata belongs to in a shared store.
+func storeOwner(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	return cmp.Or(gitConfigValue("user.email"), os.Getenv("USER"), "unknown")
+}
+
+// ownBranches lists the ticket branches of md that identify their repository.
+func ownBranches(owner string, md Metadata) []sharedBranch {
+	var branches []sharedBranch
+	for _, repo := range md.Repos {
+		for name, meta := range repo {
+			if meta.Ticket == "" || meta.Repo == "" {
+				continue
+			}
+			b := sharedBranch{Owner: owner, Repo: meta.Repo, Branch: name, Ticket: strings.ToUpper(meta.Ticket), CreatedAt: meta.CreatedAt, State: branchOpen}
+			if meta.PR != nil {
+				b.PR = meta.PR.URL
+			}
+			switch {
+			case !meta.MergedAt.IsZero():
+				b.State = branchMerged
+			case !meta.ClosedAt.IsZero():
+				b.State = branchClosed
+			}
+			branches = append(branches, b)
+		}
+	}
+	return branches
+}
+
+// fileStore keeps the metadata in metadata.json next to the config file.
+type fileStore struct{}
+
+func (fileStore) load() (Metadata, error) {
+	md := Metadata{Repos: map[string]map[string]BranchMetadata{}}
+	path, err := metadataFilePath()
+	if err != nil {
+		return md, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		// If the file doesn't exist, return an empty store.
+		return md, nil
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&md); err != nil {
+		return md, err
+	}
+	return md, nil
+}
+
+// save replaces metadata.json. Saves are serialized with a lock file and the
+// file is replaced in one rename, so concurrent commands and the ingest
+// service never interleave writes or read a half-written file.
+func (fileStore) save(md Metadata) error {
+	path, err := metadataFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// staleLockAge is how old a lock file must be before it is taken to have been
+// left behind by a process that died while holding it.
+const staleLockAge = 30 * time.Second
+
+// lockFile takes the lock of path, a path.lock file next to it, waiting up to
+// a few seconds for another process to release it. The returned function
+// releases the lock.
+func lockFile(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another git-helper process; remove %s if none is running", path, lock)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// writeFileAtomic writes a file through a temporary file in the same
+// directory, so readers see either the old or the new content.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s fileStore) ticketBranches(ticket string) ([]sharedBranch, error) {
+	md, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var branches []sharedBranch
+	for _, b := range ownBranches(storeOwner(""), md) {
+		if strings.EqualFold(b.Ticket, ticket) {
+			branches = append(branches, b)
+		}
+	}
+	return branches, nil
+}
+
+// sqlSchema creates the tables of a SQL store. The metadata of each owner is
+// kept as one JSON document, and their ticket branches as rows to query.
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS metadata (
+		owner TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS branches (
+		owner TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		branch TEXT NOT NULL,
+		ticket TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		pr TEXT NOT NULL,
+		state TEXT NOT NULL,
+		PRIMARY KEY (owner, repo, branch)
+	)`,
+	`CREATE INDEX IF NOT EXISTS branches_ticket ON branches (ticket)`,
+}
+
+// sqlStore keeps the metadata in SQLite or Postgres.
+type sqlStore struct {
+	driver, source string
+	// owner is resolved on first use, as it may need git.
+	owner     func() string
+	connected func() (*sql.DB, error)
+}
+
+// newSQLStore returns a store in the database of a database/sql driver.
+func newSQLStore(driver, source, owner string) *sqlStore {
+	s := &sqlStore{driver: driver, source: source}
+	s.owner = sync.OnceValue(func() string { return storeOwner(owner) })
+	s.connected = sync.OnceValues(s.connect)
+	return s
+}
+
+// connect opens the database and creates the tables it lacks.
+func (s *sqlStore) connect() (*sql.DB, error) {
+	db, err := sql.Open(s.driver, s.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s store: %w", s.name(), err)
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	for _, stmt := range sqlSchema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set up the %s store: %w", s.name(), err)
+		}
+	}
+	return db, nil
+}
+
+// name names the database in errors.
+func (s *sqlStore) name() string {
+	if s.driver == "pgx" {
+		return "Postgres"
+	}
+	return "SQLite"
+}
+
+// query rewrites the ? placeholders of a statement to $1, $2... for Postgres.
+func (s *sqlStore) query(stmt string) string {
+	if s.driver != "pgx" {
+		return stmt
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range stmt {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// forOwner returns the store of another owner in the same database, for
+// serve-store.
+func (s *sqlStore) forOwner(owner string) *sqlStore {
+	other := *s
+	other.owner = func() string { return owner }
+	return &other
+}
+
+// load reads the owner's metadata. Until the first save, it starts from
+// metadata.json, so switching backends keeps what is known.
+func (s *sqlStore) load() (Metadata, error) {
+	md, version, err := s.loadStored()
+	if err == nil && version == "" {
+		return fileStore{}.load()
+	}
+	return md, err
+}
+
+// errStaleMetadata reports a conditional save of metadata that changed since
+// it was read.
+var errStaleMetadata = errors.New("the metadata changed since it was read")
+
+// metadataVersion identifies a stored metadata document, so writes can check
+// that nobody changed it since it was read.
+func metadataVersion(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadStored reads the owner's metadata and its version, which is empty if
+// there is none yet.
+func (s *sqlStore) loadStored() (Metadata, string, error) {
+	var md Metadata
+	db, err := s.connected()
+	if err != nil {
+		return md, "", err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	var data string
+	err = db.QueryRowContext(ctx, s.query(`SELECT data FROM metadata WHERE owner = ?`), s.owner()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return md, "", nil
+	}
+	if err != nil {
+		return md, "", explainCancel(err, "reading the "+s.name()+" store", apiTimeout)
+	}
+	if err := json.Unmarshal([]byte(data), &md); err != nil {
+		return md, "", fmt.Errorf("invalid metadata in the %s store: %w", s.name(), err)
+	}
+	return md, metadataVersion(data), nil
+}
+
+// save writes the owner's metadata and replaces their ticket branches.
+func (s *sqlStore) save(md Metadata) error {
+	_, err := s.write(md, nil)
+	return err
+}
+
+// saveIf is save for metadata read at version, empty if there was none. It
+// fails with errStaleMetadata if the stored metadata has changed since, and
+// returns the new version.
+func (s *sqlStore) saveIf(md Metadata, version string) (string, error) {
+	return s.write(md, &version)
+}
+
+// write saves the owner's metadata, only if it is still at version when that
+// is given, and returns the new version.
+func (s *sqlStore) write(md Metadata, version *string) (string, error) {
+	db, err := s.connected()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", explainCancel(err, "writing the "+s.name()+" store", apiTimeout)
+	}
+	defer tx.Rollback()
+	owner := s.owner()
+	updated := time.Now().UTC().Format(time.RFC3339)
+	if version == nil {
+		if _, err := tx.ExecContext(ctx, s.query(`INSERT INTO metadata (owner, data, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (owner) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`),
+			owner, string(data), updated); err != nil {
+			return "", fmt.Errorf("failed to write the %s store: %w", s.name(), err)
+		}
+	} else if err := s.replaceIf(ctx, tx, owner, string(data), updated, *version); err != nil {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM branches WHERE owner = ?`), owner); err != nil {
+		return "", fmt.Errorf("failed to write the %s store: %w", s.name(), err)
+	}
+	for _, b := range ownBranches(owner, md) {
+		if _, err := tx.ExecContext(ctx, s.query(`INSERT INTO branches (owner, repo, branch, ticket, created_at, pr, state) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (owner, repo, branch) DO NOTHING`),
+			b.Owner, b.Repo, b.Branch, b.Ticket, b.CreatedAt.UTC().Format(time.RFC3339), b.PR, b.State); err != nil {
+			return "", fmt.Errorf("failed to write the %s store: %w", s.name(), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to write the %s store: %w", s.name(), err)
+	}
+	return metadataVersion(string(data)), nil
+}
+
+// replaceIf replaces the owner's metadata document if it is still at version.
+// The statements only match the document that was read, so a concurrent
+// write in between is caught even without serializable transactions.
+func (s *sqlStore) replaceIf(ctx context.Context, tx *sql.Tx, owner, data, updated, version string) error {
+	var current string
+	err := tx.QueryRowContext(ctx, s.query(`SELECT data FROM metadata WHERE owner = ?`), owner).Scan(&current)
+	var result sql.Result
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if version != "" {
+			return errStaleMetadata
+		}
+		result, err = tx.ExecContext(ctx, s.query(`INSERT INTO metadata (owner, data, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (owner) DO NOTHING`), owner, data, updated)
+	case err != nil:
+		return explainCancel(err, "reading the "+s.name()+" store", apiTimeout)
+	case metadataVersion(current) != version:
+		return errStaleMetadata
+	default:
+		result, err = tx.ExecContext(ctx, s.query(`UPDATE metadata SET data = ?, updated_at = ? WHERE owner = ? AND data = ?`),
+			data, updated, owner, current)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the %s store: %w", s.name(), err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n != 1 {
+		return errStaleMetadata
+	}
+	return nil
+}
+
+func (s *sqlStore) ticketBranches(ticket string) ([]sharedBranch, error) {
+	db, err := s.connected()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	rows, err := db.QueryContext(ctx, s.query(`SELECT owner, repo, branch, ticket, created_at, pr, state FROM branches
+		WHERE ticket = ? ORDER BY created_at`), strings.ToUpper(ticket))
+	if err != nil {
+		return nil, explainCancel(err, "reading the "+s.name()+" store", apiTimeout)
+	}
+	defer rows.Close()
+	var branches []sharedBranch
+	for rows.Next() {
+		var b sharedBranch
+		var created string
+		if err := rows.Scan(&b.Owner, &b.Repo, &b.Branch, &b.Ticket, &created, &b.PR, &b.State); err != nil {
+			return nil, err
+		}
+		b.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		branches = append(branches, b)
+	}
+	return branches, rows.Err()
+}
+
+// httpStore keeps the metadata in a `serve-store` service, which names the
+// owner after the token.
+type httpStore struct {
+	client *restClient
+	// version is that of the metadata last read or written, so a save fails
+	// rather than overwrite what another process saved in between.
+	version string
+}
+
+// storedMetadata is the body of the metadata endpoint; Metadata is nil for
+// owners that have not saved any yet. Version is that of the metadata read,
+// and must be sent back with the changed metadata.
+type storedMetadata struct {
+	Metadata *Metadata `json:"metadata,omitempty"`
+	Version  string    `json:"version,omitempty"`
+}
+
+// load reads the owner's metadata, starting from metadata.json like sqlStore.
+func (s *httpStore) load() (Metadata, error) {
+	var stored storedMetadata
+	if err := s.client.do(http.MethodGet, "/metadata", nil, &stored); err != nil {
+		return Metadata{}, err
+	}
+	s.version = stored.Version
+	if stored.Metadata == nil {
+		return fileStore{}.load()
+	}
+	return *stored.Metadata, nil
+}
+
+func (s *httpStore) save(md Metadata) error {
+	var saved storedMetadata
+	if err := s.client.do(http.MethodPut, "/metadata", storedMetadata{Metadata: &md, Version: s.version}, &saved); err != nil {
+		return err
+	}
+	s.version = saved.Version
+	return nil
+}
+
+func (s *httpStore) ticketBranches(ticket string) ([]sharedBranch, error) {
+	var branches []sharedBranch
+	err := s.client.do(http.MethodGet, "/branches?ticket="+url.QueryEscape(ticket), nil, &branches)
+	return branches, err
+}
@@ -0,0 +1,6 @@
+package cmd
+
+import "github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+
+// StyleConfig configures the commit description style rules; see convention.Style.
+type StyleConfig = convention.Style
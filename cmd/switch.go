@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// switchCmd represents the command to check out a branch by its ticket.
+var switchCmd = &cobra.Command{
+	Use:   "switch <TICKET>",
+	Short: "Check out the branch of a JIRA ticket",
+	Long: `Check out the branch of a ticket without remembering its full name, e.g.
+'gh switch CPRE-11347'.
+
+Local branches are preferred. Without one, branches are fetched from origin and
+a remote branch for the ticket is checked out as a local branch tracking it.
+When several branches belong to the ticket, pick one from a list that can be
+filtered by typing.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		ticketID := strings.ToUpper(args[0])
+		if err := convention.ValidateTicketID(ticketID); err != nil {
+			return err
+		}
+
+		branches, err := ticketBranches(ticketID)
+		if err != nil {
+			return err
+		}
+		// A branch pushed from elsewhere may not have been fetched yet.
+		if !slices.ContainsFunc(branches, func(b ticketBranch) bool { return b.Remote == "" }) {
+			if err := runGit("fetch", "--prune", "origin"); err != nil {
+				fmt.Printf("Warning: failed to fetch from origin, using the branches fetched before: %v\n", err)
+			}
+			if branches, err = ticketBranches(ticketID); err != nil {
+				return err
+			}
+		}
+
+		var b ticketBranch
+		switch len(branches) {
+		case 0:
+			return fmt.Errorf("no branch found for %s; create one with 'gh create-branch --ticket %s'", ticketID, ticketID)
+		case 1:
+			b = branches[0]
+		default:
+			picked, err := askTicketBranch(fmt.Sprintf("%s has %d branches. Which one?", ticketID, len(branches)), branches)
+			if err != nil {
+				return err
+			}
+			b = *picked
+		}
+
+		if current, err := getCurrentBranch(); err == nil && b.Remote == "" && current == b.Name {
+			fmt.Printf("Already on '%s'.\n", b.Name)
+			return nil
+		}
+		return checkoutTicketBranch(cfg, b)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// repoSyncResult is the outcome of syncing one repository.
+type repoSyncResult struct {
+	Repo      string
+	Status    string
+	Attention string // why the repository needs a look, or "" if it does not
+}
+
+// syncRepo fetches and prunes a repository and fast-forwards its base branch.
+func syncRepo(repo string) repoSyncResult {
+	r := repoSyncResult{Repo: repo}
+	if _, err := gitOutputIn(repo, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		r.Attention = fmt.Sprintf("fetch failed: %v", err)
+		return r
+	}
+
+	remoteBase, err := gitOutputIn(repo, "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		r.Attention = "origin/HEAD is not set; run 'git remote set-head origin --auto'"
+		return r
+	}
+	base := strings.TrimPrefix(remoteBase, "origin/")
+	if _, err := gitOutputIn(repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+base); err != nil {
+		r.Status = "fetched (no local " + base + ")"
+		return r
+	}
+	if isAncestor(repo, remoteBase, base) {
+		r.Status = base + " up to date"
+		return r
+	}
+	if !isAncestor(repo, base, remoteBase) {
+		r.Attention = fmt.Sprintf("%s has diverged from %s", base, remoteBase)
+		return r
+	}
+
+	current, _ := gitOutputIn(repo, "symbolic-ref", "--short", "HEAD")
+	// The checked out branch can only move together with the working tree.
+	if current == base {
+		if status, _ := gitOutputIn(repo, "status", "--porcelain"); status != "" {
+			r.Attention = fmt.Sprintf("%s is behind %s but has uncommitted changes", base, remoteBase)
+			return r
+		}
+	}
+	if readOnly {
+		r.Status = base + " would be fast-forwarded"
+		return r
+	}
+	if current == base {
+		if _, err := gitOutputIn(repo, "merge", "--ff-only", "--quiet", remoteBase); err != nil {
+			r.Attention = fmt.Sprintf("failed to fast-forward %s: %v", base, err)
+			return r
+		}
+	} else if _, err := gitOutputIn(repo, "fetch", "--quiet", ".", remoteBase+":"+base); err != nil {
+		r.Attention = fmt.Sprintf("failed to fast-forward %s: %v", base, err)
+		return r
+	}
+	r.Status = base + " fast-forwarded"
+	return r
+}
+
+// syncAllCmd represents the command to sync every repository of the workspace.
+var syncAllCmd = &cobra.Command{
+	Use:   "sync-all",
+	Short: "Fetch, prune and fast-forward the base branch of every workspace repository",
+	Long: `Sync every repository of the workspace in parallel: fetch and prune origin, and
+fast-forward the local base branch (the branch origin/HEAD points to) when that
+is safe. Repositories that need attention, such as a diverged base branch or
+uncommitted changes on it, are listed at the end.
+
+Configure the workspace in the config file, e.g. "workspace": ["~/code"].`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		repos := workspaceRepos(cfg)
+		if len(repos) == 0 {
+			return fmt.Errorf("no workspace repositories configured; add \"workspace\" to the config file")
+		}
+
+		results := make(chan repoSyncResult)
+		slots := make(chan struct{}, max(jobs, 1))
+		var wg sync.WaitGroup
+		for _, repo := range repos {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				results <- syncRepo(repo)
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		// Report each repository as it finishes.
+		var attention []repoSyncResult
+		done := 0
+		for r := range results {
+			done++
+			status := r.Status
+			if r.Attention != "" {
+				status = "needs attention"
+				attention = append(attention, r)
+			}
+			fmt.Printf("[%d/%d] %s: %s\n", done, len(repos), filepath.Base(r.Repo), status)
+		}
+
+		if len(attention) == 0 {
+			fmt.Printf("\nAll %d repositories are in sync.\n", len(repos))
+			return nil
+		}
+		fmt.Printf("\n%d of %d repositories need attention:\n", len(attention), len(repos))
+		for _, r := range attention {
+			fmt.Printf("  %s: %s\n", r.Repo, r.Attention)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncAllCmd)
+	syncAllCmd.Flags().Int("jobs", 8, "Number of repositories to sync at the same time")
+}
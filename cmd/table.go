@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Kinds of table columns, which decide how cells are formatted and sorted.
+const (
+	columnText = iota
+	columnNumber
+	columnDate
+	columnDateTime
+)
+
+// minTruncatedWidth is the narrowest a truncated column is made to fit the terminal.
+const minTruncatedWidth = 12
+
+// tableColumn describes one column of a list command's output.
+type tableColumn struct {
+	name string
+	kind int
+	// truncate marks the free-text column shortened to fit the terminal width.
+	truncate bool
+	// link, when set, turns the cells of the table format into hyperlinks.
+	link func(text string) string
+}
+
+// table collects the rows of a list command and renders them as an aligned
+// table, JSON, CSV or Markdown, honouring the --sort, --columns and --format flags
+// added by addTableFlags. Cells are strings, ints or time.Times matching the
+// kind of their column.
+type table struct {
+	columns []tableColumn
+	rows    [][]any
+}
+
+// newTable creates a table with the given columns.
+func newTable(columns ...tableColumn) *table {
+	return &table{columns: columns}
+}
+
+// add appends a row, one cell per column.
+func (t *table) add(cells ...any) {
+	t.rows = append(t.rows, cells)
+}
+
+// addTableFlags registers the output flags of a list command.
+func addTableFlags(cmd *cobra.Command, columns []tableColumn, defaultSort string) {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	cmd.Flags().String("sort", defaultSort, "Sort by this column, one of "+strings.Join(names, ", ")+"; append :desc to reverse")
+	cmd.Flags().String("columns", "", "Comma-separated columns to show, in order (default all)")
+	cmd.Flags().String("format", "table", "Output format: table, json, csv or markdown")
+}
+
+// column returns the index of the named column.
+func (t *table) column(name string) (int, error) {
+	for i, c := range t.columns {
+		if c.name == name {
+			return i, nil
+		}
+	}
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	return 0, fmt.Errorf("unknown column '%s'; use one of %s", name, strings.Join(names, ", "))
+}
+
+// render writes the table in the format chosen with the command's flags,
+// printing empty instead of an empty table.
+func (t *table) render(cmd *cobra.Command, empty string) error {
+	sortBy, _ := cmd.Flags().GetString("sort")
+	selection, _ := cmd.Flags().GetString("columns")
+	format, _ := cmd.Flags().GetString("format")
+
+	if sortBy != "" {
+		name, order, _ := strings.Cut(sortBy, ":")
+		if order != "" && order != "asc" && order != "desc" {
+			return fmt.Errorf("invalid sort order '%s'; use asc or desc", order)
+		}
+		index, err := t.column(name)
+		if err != nil {
+			return err
+		}
+		slices.SortStableFunc(t.rows, func(a, b []any) int {
+			c := compareCells(a[index], b[index])
+			if order == "desc" {
+				return -c
+			}
+			return c
+		})
+	}
+
+	shown := make([]int, len(t.columns))
+	for i := range shown {
+		shown[i] = i
+	}
+	if selection != "" {
+		shown = nil
+		for _, name := range strings.Split(selection, ",") {
+			index, err := t.column(strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			shown = append(shown, index)
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	switch format {
+	case "table":
+		if len(t.rows) == 0 {
+			fmt.Fprintln(out, empty)
+			return nil
+		}
+		t.writeTable(out, shown)
+		return nil
+	case "json":
+		records := make([]map[string]any, 0, len(t.rows))
+		for _, row := range t.rows {
+			record := make(map[string]any, len(shown))
+			for _, i := range shown {
+				record[t.columns[i].name] = row[i]
+			}
+			records = append(records, record)
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case "csv":
+		w := csv.NewWriter(out)
+		header := make([]string, len(shown))
+		for j, i := range shown {
+			header[j] = t.columns[i].name
+		}
+		w.Write(header)
+		for _, row := range t.rows {
+			record := make([]string, len(shown))
+			for j, i := range shown {
+				record[j] = rawCell(row[i])
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	case "markdown":
+		t.writeMarkdown(out, shown)
+		return nil
+	default:
+		return fmt.Errorf("unknown format '%s'; use table, json, csv or markdown", format)
+	}
+}
+
+// writeTable writes the shown columns aligned under upper-case headers,
+// shortening the truncated column when the terminal is too narrow.
+func (t *table) writeTable(out io.Writer, shown []int) {
+	cells := make([][]string, len(t.rows)+1)
+	cells[0] = make([]string, len(shown))
+	for j, i := range shown {
+		cells[0][j] = strings.ToUpper(t.columns[i].name)
+	}
+	for r, row := range t.rows {
+		cells[r+1] = make([]string, len(shown))
+		for j, i := range shown {
+			cells[r+1][j] = formatCell(t.columns[i], row[i])
+		}
+	}
+
+	widths := make([]int, len(shown))
+	for _, row := range cells {
+		for j, cell := range row {
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+	if termWidth, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		total := 2 * (len(widths) - 1)
+		for _, w := range widths {
+			total += w
+		}
+		for j, i := range shown {
+			if t.columns[i].truncate && total > termWidth {
+				widths[j] = max(minTruncatedWidth, widths[j]-(total-termWidth))
+			}
+		}
+	}
+
+	for r, row := range cells {
+		var line strings.Builder
+		for j, cell := range row {
+			if utf8.RuneCountInString(cell) > widths[j] {
+				cell = string([]rune(cell)[:widths[j]-1]) + "…"
+			}
+			// Padding goes outside the link, whose escape sequences take no room.
+			padding := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell))
+			if link := t.columns[shown[j]].link; link != nil && r > 0 {
+				cell = link(cell)
+			}
+			if j == len(row)-1 {
+				line.WriteString(cell)
+			} else {
+				line.WriteString(cell + padding + "  ")
+			}
+		}
+		fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// markdownEscaper keeps cells from ending a Markdown table cell or being
+// taken for HTML tags.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "<", `\<`)
+
+// writeMarkdown writes the shown columns as a Markdown table, e.g. for docs
+// and pull request descriptions.
+func (t *table) writeMarkdown(out io.Writer, shown []int) {
+	header := make([]string, len(shown))
+	rule := make([]string, len(shown))
+	for j, i := range shown {
+		header[j] = t.columns[i].name
+		rule[j] = "---"
+	}
+	fmt.Fprintf(out, "| %s |\n| %s |\n", strings.Join(header, " | "), strings.Join(rule, " | "))
+	for _, row := range t.rows {
+		cells := make([]string, len(shown))
+		for j, i := range shown {
+			cells[j] = markdownEscaper.Replace(formatCell(t.columns[i], row[i]))
+		}
+		fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// formatCell formats a cell for the table format, following the locale.
+func formatCell(c tableColumn, cell any) string {
+	switch v := cell.(type) {
+	case int:
+		return formatNumber(v)
+	case time.Time:
+		if v.IsZero() {
+			return "-"
+		}
+		if c.kind == columnDateTime {
+			return formatDateTime(v)
+		}
+		return formatDate(v)
+	default:
+		return orDash(fmt.Sprint(v))
+	}
+}
+
+// rawCell formats a cell for CSV, independent of the locale.
+func rawCell(cell any) string {
+	switch v := cell.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareCells orders two cells of the same column.
+func compareCells(a, b any) int {
+	switch x := a.(type) {
+	case int:
+		y, _ := b.(int)
+		return cmp.Compare(x, y)
+	case time.Time:
+		y, _ := b.(time.Time)
+		return x.Compare(y)
+	default:
+		return cmp.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// tagMessageTemplate is the message of annotated tags created by `tag create`.
+var tagMessageTemplate = template.Must(template.New("tag").Parse(`Release {{.Version}}
+
+Date: {{.Date}}
+{{- if .Previous}}
+Previous: {{.Previous}}
+{{- end}}
+{{if .Tickets}}
+Tickets:
+{{- range .Tickets}}
+- {{.}}
+{{- end}}
+{{else}}
+No tickets referenced since the previous release.
+{{end -}}
+`))
+
+// tagMessage holds the values rendered into tagMessageTemplate.
+type tagMessage struct {
+	Version  string
+	Date     string
+	Previous string
+	Tickets  []string
+}
+
+// latestTag returns the most recent tag reachable from HEAD, or "" if there is none.
+func latestTag() string {
+	tag, err := gitOutput("describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return ""
+	}
+	return tag
+}
+
+// ticketsBetween returns the sorted, unique JIRA tickets referenced by commit
+// messages after since up to until (or in all of until's history if since is
+// empty). If paths are given, only commits touching them are considered.
+func ticketsBetween(since, until string, paths ...string) ([]string, error) {
+	revRange := until
+	if since != "" {
+		revRange = since + ".." + until
+	}
+	out, err := gitOutput(append([]string{"log", "--format=%B", revRange}, pathArgs(paths)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits in '%s': %w", revRange, err)
+	}
+	tickets := convention.TicketRefPattern.FindAllString(out, -1)
+	slices.Sort(tickets)
+	return slices.Compact(tickets), nil
+}
+
+// renderTagMessage builds the annotation for a new tag on target, listing the
+// tickets of the commits since previous that touch the given paths.
+func renderTagMessage(version, previous, target string, paths ...string) (string, error) {
+	tickets, err := ticketsBetween(previous, target, paths...)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = tagMessageTemplate.Execute(&b, tagMessage{
+		Version:  version,
+		Date:     time.Now().Format(time.DateOnly),
+		Previous: previous,
+		Tickets:  tickets,
+	})
+	return b.String(), err
+}
+
+// tagCmd groups the tag management commands.
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Create and list release tags",
+}
+
+// tagCreateCmd represents the command to create an annotated release tag.
+var tagCreateCmd = &cobra.Command{
+	Use:   "create [version]",
+	Short: "Create an annotated tag listing the tickets since the previous tag",
+	Long: `Create an annotated tag on HEAD. The tag message contains the version, the
+date, the previous tag, and the JIRA tickets referenced by commits since then.
+If no version is given, you are asked for one, with the next patch version as
+the default.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		previous := latestTag()
+
+		// 1. Decide the version.
+		var version string
+		if len(args) == 1 {
+			version = args[0]
+		} else {
+			prompt := &survey.Input{Message: "Enter the version to tag (e.g., v1.4.0 or v1.4.0-rc.1):"}
+			if v, err := parseVersion(previous); err == nil {
+				prompt.Default = v.bump("patch").String()
+			}
+			if err := askOne(prompt, &version, survey.WithValidator(func(val interface{}) error {
+				str, _ := val.(string)
+				_, err := parseVersion(str)
+				return err
+			})); err != nil {
+				return err
+			}
+		}
+		if _, err := parseVersion(version); err != nil {
+			return err
+		}
+
+		// 2. Render the annotation.
+		message, err := renderTagMessage(version, previous, "HEAD")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nThe following tag will be created:\n\n%s\n", message)
+
+		confirm, err := confirmAction(cfg, false, fmt.Sprintf("Create tag '%s'?", version))
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Tag creation aborted.")
+			return nil
+		}
+
+		// 3. Create the tag.
+		if err := runGit("tag", "-a", version, "-m", message); err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		fmt.Printf("Tag '%s' created. Push it with: git push origin %s\n", version, version)
+		return nil
+	},
+}
+
+// tagListCmd represents the command to list tags, newest version first.
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tags sorted by version, newest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, err := gitOutput("for-each-ref", "--sort=-v:refname", "--format=%(refname:short)%09%(creatordate:short)", "refs/tags")
+		if err != nil {
+			return fmt.Errorf("failed to list tags: %w", err)
+		}
+		if out == "" {
+			fmt.Println("No tags found.")
+			return nil
+		}
+		for _, line := range strings.Split(out, "\n") {
+			name, date, _ := strings.Cut(line, "\t")
+			fmt.Printf("%-20s %s\n", name, date)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagCreateCmd)
+	tagCmd.AddCommand(tagListCmd)
+}
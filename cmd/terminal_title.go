@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// branchTitle returns the window title for a branch, "TICKET: short-desc" for
+// convention branches and "" for anything else.
+func branchTitle(branch string) string {
+	b, ok := branchTemplate.Parse(branch)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", strings.ToUpper(b.Ticket), b.Description)
+}
+
+// setTerminalTitle names the tmux window, or the terminal window outside tmux,
+// after the ticket of a branch when the terminal_title option is enabled.
+func setTerminalTitle(cfg Config, branch string) {
+	title := branchTitle(branch)
+	if !cfg.TerminalTitle || title == "" {
+		return
+	}
+	if os.Getenv("TMUX") != "" {
+		if err := exec.Command("tmux", "rename-window", title).Run(); err != nil {
+			fmt.Printf("Warning: failed to rename the tmux window: %v\n", err)
+		}
+		return
+	}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		// OSC 0 sets the icon name and window title in xterm-compatible terminals.
+		fmt.Printf("\033]0;%s\007", title)
+	}
+}
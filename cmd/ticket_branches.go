@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// ticketBranch is an existing local or remote branch for a ticket.
+type ticketBranch struct {
+	// Name is the branch name without the remote, e.g. lv-fix-x/CPRE-1.
+	Name string
+	// Remote is the remote of a remote branch, or "" for a local one.
+	Remote string
+}
+
+// ref returns the short ref of the branch, e.g. origin/lv-fix-x/CPRE-1.
+func (b ticketBranch) ref() string {
+	if b.Remote == "" {
+		return b.Name
+	}
+	return b.Remote + "/" + b.Name
+}
+
+// ticketBranches returns the branches whose name refers to ticketID, most
+// recently updated first. Remote branches that also exist locally are left
+// out, as checking out the local one is what is wanted.
+func ticketBranches(ticketID string) ([]ticketBranch, error) {
+	out, err := gitOutput("for-each-ref", "--sort=-committerdate", "--format=%(refname)%09%(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	var branches []ticketBranch
+	local := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		ref, symref, _ := strings.Cut(line, "\t")
+		if ref == "" || symref != "" {
+			continue
+		}
+		var b ticketBranch
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			b.Name = name
+			local[name] = true
+		} else {
+			b.Remote, b.Name, _ = strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+		}
+		if t, err := extractTicketFromBranch(b.Name); err == nil && strings.EqualFold(t, ticketID) {
+			branches = append(branches, b)
+		}
+	}
+	var unique []ticketBranch
+	for _, b := range branches {
+		if b.Remote == "" || !local[b.Name] {
+			unique = append(unique, b)
+		}
+	}
+	return unique, nil
+}
+
+// checkoutTicketBranch switches to an existing ticket branch. A remote branch
+// gets a local branch tracking it, and a local branch without an upstream is
+// set to track its namesake on origin, if there is one.
+func checkoutTicketBranch(cfg Config, b ticketBranch) error {
+	if b.Remote != "" {
+		if err := runGit("checkout", "--track", b.ref()); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", b.ref(), err)
+		}
+	} else {
+		if err := runGit("checkout", b.Name); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", b.Name, err)
+		}
+		if _, err := gitOutput("rev-parse", "--abbrev-ref", b.Name+"@{upstream}"); err != nil {
+			if _, err := gitOutput("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+b.Name); err == nil {
+				if err := runGit("branch", "--set-upstream-to=origin/"+b.Name, b.Name); err != nil {
+					fmt.Printf("Warning: failed to set the upstream of %s: %v\n", b.Name, err)
+				}
+			}
+		}
+	}
+	enteredBranch(cfg, b.Name)
+	return nil
+}
+
+// createAnyway is the choice that goes on to create a new branch for a ticket
+// that already has one.
+const createAnyway = "Create a new branch anyway"
+
+// offerTicketBranch looks for branches already made for ticketID and offers
+// to check one out instead of creating a duplicate. It reports whether one
+// was checked out. Without a terminal to ask on, it only warns.
+func offerTicketBranch(cfg Config, ticketID string) (bool, error) {
+	branches, err := ticketBranches(ticketID)
+	if err != nil || len(branches) == 0 {
+		// The check is a convenience, so a failure to list branches does not stop creation.
+		return false, nil
+	}
+	if !stdinIsTerminal() {
+		refs := make([]string, len(branches))
+		for i, b := range branches {
+			refs[i] = b.ref()
+		}
+		fmt.Printf("Warning: %s already has a branch: %s\n", linkTicket(ticketID), strings.Join(refs, ", "))
+		return false, nil
+	}
+
+	fmt.Printf("\n%s already has a branch.\n", linkTicket(ticketID))
+	b, err := askTicketBranch("Check out an existing branch?", branches, createAnyway)
+	if err != nil || b == nil {
+		return false, err
+	}
+	return true, checkoutTicketBranch(cfg, *b)
+}
+
+// askTicketBranch lets the user pick one of branches, filtered fuzzily as
+// they type, or one of the other options, for which it returns nil.
+func askTicketBranch(message string, branches []ticketBranch, other ...string) (*ticketBranch, error) {
+	options := make([]string, len(branches))
+	for i, b := range branches {
+		options[i] = b.ref()
+	}
+	var choice int
+	if err := askOne(&survey.Select{
+		Message: message,
+		Options: append(options, other...),
+		Description: func(value string, index int) string {
+			switch {
+			case index >= len(branches):
+				return ""
+			case branches[index].Remote != "":
+				return "remote"
+			}
+			return "local"
+		},
+		Filter: func(filter string, value string, _ int) bool {
+			return fuzzyMatch(filter, value)
+		},
+	}, &choice); err != nil {
+		return nil, err
+	}
+	if choice >= len(branches) {
+		return nil, nil
+	}
+	return &branches[choice], nil
+}
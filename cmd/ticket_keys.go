@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// Number of recent commits scanned for ticket keys to complete.
+const ticketKeyHistoryDepth = 500
+
+// recentTicketKeys returns the ticket keys seen locally, most recent first:
+// those of branches created with the helper in any repository, those in the
+// names of local branches and those referenced in the recent history. It needs
+// no JIRA access, so completion also works offline.
+func recentTicketKeys() []string {
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		key = strings.ToUpper(key)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	// Failing sources only mean fewer suggestions.
+	if md, err := loadMetadata(); err == nil {
+		var branches []BranchMetadata
+		for _, repo := range md.Repos {
+			for _, meta := range repo {
+				branches = append(branches, meta)
+			}
+		}
+		slices.SortFunc(branches, func(a, b BranchMetadata) int { return b.CreatedAt.Compare(a.CreatedAt) })
+		for _, meta := range branches {
+			add(meta.Ticket)
+		}
+	}
+	if out, err := gitOutput("for-each-ref", "--sort=-committerdate", "--format=%(refname:short)", "refs/heads"); err == nil {
+		for _, branch := range strings.Split(out, "\n") {
+			if b, ok := branchTemplate.Parse(branch); ok {
+				add(b.Ticket)
+			}
+		}
+	}
+	if out, err := gitOutput("log", "--format=%B", "-n", strconv.Itoa(ticketKeyHistoryDepth)); err == nil {
+		for _, key := range convention.TicketRefPattern.FindAllString(out, -1) {
+			add(key)
+		}
+	}
+	return keys
+}
+
+// completeTicketKey returns the known ticket keys starting with toComplete,
+// ignoring case, keeping the most recent first.
+func completeTicketKey(keys []string, toComplete string) []string {
+	prefix := strings.ToUpper(toComplete)
+	var matches []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			matches = append(matches, key)
+		}
+	}
+	return matches
+}
+
+// completeTicketFlag completes ticket flags and arguments in the shell.
+func completeTicketFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeTicketKey(recentTicketKeys(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTicketArg completes the single ticket argument of a command.
+func completeTicketArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTicketFlag(cmd, args, toComplete)
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// Assigned tickets fetched within this long are served from the cache.
+const assignedTicketsMaxAge = 5 * time.Minute
+
+// assignedTicketsLimit caps how many issues are fetched.
+const assignedTicketsLimit = 50
+
+// AssignedTicket is an open JIRA issue assigned to the user.
+type AssignedTicket struct {
+	Ticket    string `json:"ticket"`
+	Summary   string `json:"summary"`
+	IssueType string `json:"issue_type,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// assignedTicketsCache holds the tickets of one query and when they were fetched.
+type assignedTicketsCache struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Tickets   []AssignedTicket `json:"tickets"`
+}
+
+// assignedTicketsCachePath returns the path of the assigned tickets cache.
+func assignedTicketsCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "assigned_tickets.json"), nil
+}
+
+// assignedJQL returns the query for the user's open issues, limited to the
+// configured ticket projects and, when sprint is set, to a sprint: "current"
+// means the open sprints, anything else is a sprint name.
+func assignedJQL(cfg Config, sprint string) string {
+	clauses := []string{"assignee = currentUser()", "statusCategory != Done"}
+	if len(cfg.TicketProjects) > 0 {
+		clauses = append(clauses, fmt.Sprintf("project in (%s)", strings.Join(cfg.TicketProjects, ", ")))
+	}
+	switch sprint {
+	case "":
+	case "current":
+		clauses = append(clauses, "sprint in openSprints()")
+	default:
+		clauses = append(clauses, fmt.Sprintf("sprint = %q", sprint))
+	}
+	return strings.Join(clauses, " AND ") + " ORDER BY updated DESC"
+}
+
+// fetchAssignedTickets returns the user's open issues, from the cache when it
+// was filled recently for the same query unless refresh is set.
+func fetchAssignedTickets(cfg Config, sprint string, refresh bool) ([]AssignedTicket, error) {
+	jql := assignedJQL(cfg, sprint)
+	cache := map[string]assignedTicketsCache{}
+	path, err := assignedTicketsCachePath()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		// A corrupt cache is simply refilled.
+		_ = json.Unmarshal(data, &cache)
+	}
+	if entry, ok := cache[jql]; ok && !refresh && time.Since(entry.FetchedAt) < assignedTicketsMaxAge {
+		return entry.Tickets, nil
+	}
+
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := client.search(jql, assignedTicketsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch assigned tickets: %w", err)
+	}
+	tickets := make([]AssignedTicket, 0, len(issues))
+	for _, issue := range issues {
+		tickets = append(tickets, AssignedTicket{
+			Ticket:    issue.Key,
+			Summary:   issue.Fields.Summary,
+			IssueType: issue.Fields.IssueType.Name,
+			Status:    issue.Fields.Status.Name,
+		})
+	}
+
+	cache[jql] = assignedTicketsCache{FetchedAt: time.Now(), Tickets: tickets}
+	if data, err := json.Marshal(cache); err == nil {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			fmt.Printf("Warning: failed to cache assigned tickets: %v\n", err)
+		}
+	}
+	return tickets, nil
+}
+
+// pickAssignedTicket lets the user choose one of their open issues. It
+// returns nil when they have none or choose to type the ticket ID instead.
+func pickAssignedTicket(cfg Config, sprint string) (*AssignedTicket, error) {
+	tickets, err := fetchAssignedTickets(cfg, sprint, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		fmt.Println("No open tickets are assigned to you.")
+		return nil, nil
+	}
+
+	options := make([]string, 0, len(tickets)+1)
+	for _, t := range tickets {
+		options = append(options, fmt.Sprintf("%s [%s] %s", t.Ticket, t.Status, t.Summary))
+	}
+	options = append(options, "None, enter the ticket ID")
+
+	var index int
+	if err := askOne(&survey.Select{
+		Message:  "Pick one of your tickets:",
+		Options:  options,
+		PageSize: 15,
+	}, &index); err != nil {
+		return nil, err
+	}
+	if index == len(tickets) {
+		return nil, nil
+	}
+	return &tickets[index], nil
+}
+
+// ticketColumns are the columns of the tickets command.
+var ticketColumns = []tableColumn{
+	{name: "ticket", link: linkTicket},
+	{name: "type"},
+	{name: "status"},
+	{name: "summary", truncate: true},
+}
+
+// ticketsCmd represents the command to list the user's open JIRA issues.
+var ticketsCmd = &cobra.Command{
+	Use:   "tickets",
+	Short: "List the open JIRA tickets assigned to you",
+	Long: `List the open JIRA tickets assigned to you, most recently updated first.
+
+Use --sprint current to only list tickets in the open sprints, or --sprint
+"<name>" for a particular sprint. Results are cached for five minutes to keep
+prompts fast; --refresh fetches them again.
+
+Use --pick to choose one and start a branch for it, as 'gh create-branch
+--pick-ticket' does.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		sprint, _ := cmd.Flags().GetString("sprint")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		pick, _ := cmd.Flags().GetBool("pick")
+
+		if pick {
+			if err := createBranchCmd.Flags().Set("pick-ticket", "true"); err != nil {
+				return err
+			}
+			if err := createBranchCmd.Flags().Set("sprint", sprint); err != nil {
+				return err
+			}
+			if refresh {
+				if _, err := fetchAssignedTickets(cfg, sprint, true); err != nil {
+					return err
+				}
+			}
+			return createBranchCmd.RunE(createBranchCmd, nil)
+		}
+
+		tickets, err := fetchAssignedTickets(cfg, sprint, refresh)
+		if err != nil {
+			return err
+		}
+		t := newTable(ticketColumns...)
+		for _, ticket := range tickets {
+			t.add(ticket.Ticket, ticket.IssueType, ticket.Status, ticket.Summary)
+		}
+		return t.render(cmd, "No open tickets are assigned to you.")
+	},
+}
+
+func init() {
+	ticketsCmd.Flags().String("sprint", "", "Only list tickets in this sprint; \"current\" for the open sprints")
+	ticketsCmd.Flags().Bool("refresh", false, "Fetch the tickets again instead of using the cache")
+	ticketsCmd.Flags().Bool("pick", false, "Pick a ticket and create a branch for it")
+	addTableFlags(ticketsCmd, ticketColumns, "")
+	rootCmd.AddCommand(ticketsCmd)
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// TodoItem is a TODO note attached to a ticket.
+type TodoItem struct {
+	Text      string    `json:"text"`
+	Done      bool      `json:"done,omitempty"`
+	JiraKey   string    `json:"jira_key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// openTodos returns the unfinished TODO items of a ticket.
+func openTodos(ticket string) ([]TodoItem, error) {
+	md, err := loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var open []TodoItem
+	for _, item := range md.Todos[strings.ToUpper(ticket)] {
+		if !item.Done {
+			open = append(open, item)
+		}
+	}
+	return open, nil
+}
+
+// printTodos prints TODO items numbered from 1, as expected by `todo done`.
+func printTodos(items []TodoItem, showDone bool) {
+	for i, item := range items {
+		if item.Done && !showDone {
+			continue
+		}
+		mark := " "
+		if item.Done {
+			mark = "x"
+		}
+		line := fmt.Sprintf("%2d. [%s] %s", i+1, mark, item.Text)
+		if item.JiraKey != "" {
+			line += fmt.Sprintf(" (%s)", item.JiraKey)
+		}
+		fmt.Println(line)
+	}
+}
+
+// showOpenTodos reminds the user of the open TODO items of the ticket of a branch.
+func showOpenTodos(branch string) {
+	ticket, err := extractTicketFromBranch(branch)
+	if err != nil {
+		return
+	}
+	items, err := openTodos(ticket)
+	if err != nil || len(items) == 0 {
+		return
+	}
+	fmt.Printf("\nOpen TODOs for %s:\n", linkTicket(ticket))
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item.Text)
+	}
+}
+
+// enteredBranch runs the niceties for a ticket branch that was just created or checked out.
+func enteredBranch(cfg Config, branch string) {
+	setTerminalTitle(cfg, branch)
+	showOpenTodos(branch)
+}
+
+// todoTicket returns the ticket given with --ticket, or the one of the current branch.
+func todoTicket(cmd *cobra.Command) (string, error) {
+	if ticket, _ := cmd.Flags().GetString("ticket"); ticket != "" {
+		if err := convention.ValidateTicketID(ticket); err != nil {
+			return "", err
+		}
+		return strings.ToUpper(ticket), nil
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	ticket, err := extractTicketFromBranch(branch)
+	if err != nil {
+		return "", fmt.Errorf("no ticket found in branch '%s'; use --ticket", branch)
+	}
+	return strings.ToUpper(ticket), nil
+}
+
+// todoCmd represents the command to list the TODO items of a ticket.
+var todoCmd = &cobra.Command{
+	Use:   "todo",
+	Short: "Track TODO items for the ticket of the current branch",
+	Long: `Keep a TODO list per JIRA ticket. Items are stored locally and shown when you
+create or check out a branch for the ticket. With 'todo add --jira' an item is
+also created as a sub-task of the ticket.
+
+Without a subcommand, the TODO items of the current branch's ticket are listed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticket, err := todoTicket(cmd)
+		if err != nil {
+			return err
+		}
+		all, _ := cmd.Flags().GetBool("all")
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		items := md.Todos[ticket]
+		if len(items) == 0 {
+			fmt.Printf("No TODOs for %s. Add one with 'gh todo add <text>'.\n", linkTicket(ticket))
+			return nil
+		}
+		fmt.Printf("TODOs for %s:\n", linkTicket(ticket))
+		printTodos(items, all)
+		return nil
+	},
+}
+
+// todoAddCmd represents the command to add a TODO item.
+var todoAddCmd = &cobra.Command{
+	Use:   "add <text>",
+	Short: "Add a TODO item to the ticket",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticket, err := todoTicket(cmd)
+		if err != nil {
+			return err
+		}
+		item := TodoItem{Text: strings.Join(args, " "), CreatedAt: time.Now()}
+
+		if jira, _ := cmd.Flags().GetBool("jira"); jira {
+			cfg, err := loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			client, err := newJiraClient(cfg)
+			if err != nil {
+				return err
+			}
+			if item.JiraKey, err = client.createSubtask(cfg, ticket, item.Text); err != nil {
+				return fmt.Errorf("failed to create JIRA sub-task: %w", err)
+			}
+			fmt.Printf("Created sub-task %s.\n", item.JiraKey)
+		}
+
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		if md.Todos == nil {
+			md.Todos = map[string][]TodoItem{}
+		}
+		md.Todos[ticket] = append(md.Todos[ticket], item)
+		if err := saveMetadata(md); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+		fmt.Printf("Added TODO %d to %s.\n", len(md.Todos[ticket]), linkTicket(ticket))
+		return nil
+	},
+}
+
+// todoDoneCmd represents the command to mark TODO items as done.
+var todoDoneCmd = &cobra.Command{
+	Use:   "done <number>...",
+	Short: "Mark TODO items of the ticket as done",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ticket, err := todoTicket(cmd)
+		if err != nil {
+			return err
+		}
+		md, err := loadMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to load metadata: %w", err)
+		}
+		items := md.Todos[ticket]
+		for _, arg := range args {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 || n > len(items) {
+				return fmt.Errorf("'%s' is not a TODO number of %s (1-%d)", arg, ticket, len(items))
+			}
+			items[n-1].Done = true
+		}
+		if err := saveMetadata(md); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+		printTodos(items, true)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(todoCmd)
+	todoCmd.AddCommand(todoAddCmd)
+	todoCmd.AddCommand(todoDoneCmd)
+	todoCmd.PersistentFlags().String("ticket", "", "Ticket to use instead of the current branch's")
+	todoCmd.RegisterFlagCompletionFunc("ticket", completeTicketFlag)
+	todoCmd.Flags().Bool("all", false, "Also list finished items")
+	todoAddCmd.Flags().Bool("jira", false, "Also create the item as a JIRA sub-task of the ticket")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// commitTrailers is the metadata recorded in the trailers of one commit.
+type commitTrailers struct {
+	Hash   string `json:"hash"`
+	Header string `json:"header"`
+	convention.CommitMetadata
+	Trailers []convention.Trailer `json:"trailers,omitempty"`
+}
+
+// readCommitTrailers parses the trailers of the most recent commits in revRange.
+func readCommitTrailers(revRange string, maxCount int) ([]commitTrailers, error) {
+	// Records are separated by \x1e since messages span several lines.
+	out, err := gitOutput("log", "--no-merges", fmt.Sprintf("--max-count=%d", maxCount), "--format=%h%x1f%B%x1e", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits in '%s': %w", revRange, err)
+	}
+	var commits []commitTrailers
+	for _, record := range strings.Split(out, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if !ok {
+			continue
+		}
+		commits = append(commits, commitTrailers{
+			Hash:           hash,
+			Header:         firstLine(message),
+			CommitMetadata: convention.ParseCommitMetadata(message),
+			Trailers:       convention.ParseTrailers(message),
+		})
+	}
+	return commits, nil
+}
+
+// trailersCmd represents the command to query the metadata trailers of commits.
+var trailersCmd = &cobra.Command{
+	Use:   "trailers [range]",
+	Short: "Show the ticket, product and tool version recorded in commit trailers",
+	Long: `Show the metadata create-commit records in the trailers of each commit:
+
+  Ticket: CPRE-11347
+  Product: lego
+  Helper-Version: v1.2.3
+
+The commits in the range (default: HEAD) can be filtered by ticket or product,
+and --format json prints every trailer for downstream tooling. Commits made
+without the tool show no metadata.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		revRange := "HEAD"
+		if len(args) == 1 {
+			revRange = args[0]
+		}
+		maxCount, _ := cmd.Flags().GetInt("max-count")
+		ticket, _ := cmd.Flags().GetString("ticket")
+		product, _ := cmd.Flags().GetString("product")
+		format, _ := cmd.Flags().GetString("format")
+
+		commits, err := readCommitTrailers(revRange, maxCount)
+		if err != nil {
+			return err
+		}
+		var matched []commitTrailers
+		for _, c := range commits {
+			if ticket != "" && !strings.EqualFold(c.Ticket, ticket) {
+				continue
+			}
+			if product != "" && !strings.EqualFold(c.Product, product) {
+				continue
+			}
+			matched = append(matched, c)
+		}
+
+		switch format {
+		case "json":
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(matched)
+		case "text":
+			if len(matched) == 0 {
+				fmt.Println("No matching commits.")
+				return nil
+			}
+			for _, c := range matched {
+				fmt.Printf("%s  %s %-8s %-8s %s\n", c.Hash, padTicket(orDash(c.Ticket), 12), orDash(c.Product), orDash(c.HelperVersion), c.Header)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unknown format '%s'; use text or json", format)
+		}
+	},
+}
+
+// orDash returns s, or "-" when it is empty, for table columns.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(trailersCmd)
+	trailersCmd.Flags().IntP("max-count", "n", 50, "Number of commits to read")
+	trailersCmd.Flags().String("ticket", "", "Only show commits for this ticket")
+	trailersCmd.Flags().String("product", "", "Only show commits for this product")
+	trailersCmd.Flags().String("format", "text", "Output format: text or json")
+}
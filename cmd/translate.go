@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// TranslationConfig configures the translation service used to turn commit
+// descriptions into English. The service must speak the LibreTranslate API.
+type TranslationConfig struct {
+	// Endpoint is the base URL of the service, e.g. https://libretranslate.com.
+	Endpoint string `json:"endpoint,omitempty"`
+	APIKey   string `json:"api_key,omitempty"`
+}
+
+// translator is a client for a LibreTranslate-compatible service.
+type translator struct {
+	endpoint string
+	apiKey   string
+	http     *http.Client
+}
+
+// newTranslator returns a translator, or nil if no service is configured.
+func newTranslator(cfg Config) *translator {
+	if cfg.Translation.Endpoint == "" {
+		return nil
+	}
+	return &translator{
+		endpoint: strings.TrimRight(cfg.Translation.Endpoint, "/"),
+		apiKey:   cfg.Translation.APIKey,
+		http:     &http.Client{},
+	}
+}
+
+// post sends a JSON request to a service path and decodes the response into out.
+func (t *translator) post(path string, in map[string]string, out interface{}) error {
+	if t.apiKey != "" {
+		in["api_key"] = t.apiKey
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(apiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return explainCancel(err, "translation service", apiTimeout)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("translation service returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// detect returns the language code the service detects for text, e.g. "en".
+func (t *translator) detect(text string) (string, error) {
+	var out []struct {
+		Language string `json:"language"`
+	}
+	if err := t.post("/detect", map[string]string{"q": text}, &out); err != nil {
+		return "", err
+	}
+	if len(out) == 0 {
+		return "", fmt.Errorf("translation service could not detect the language")
+	}
+	return out[0].Language, nil
+}
+
+// toEnglish translates text to English.
+func (t *translator) toEnglish(text string) (string, error) {
+	var out struct {
+		TranslatedText string `json:"translatedText"`
+	}
+	if err := t.post("/translate", map[string]string{"q": text, "source": "auto", "target": "en", "format": "text"}, &out); err != nil {
+		return "", err
+	}
+	return out.TranslatedText, nil
+}
+
+// hasNonLatinLetters reports whether text contains letters outside ASCII, a
+// cheap hint that it is not written in English.
+func hasNonLatinLetters(text string) bool {
+	for _, r := range text {
+		if r > unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// offerTranslation checks that a commit description is in English and, when
+// a translation service is configured, offers to replace it with an English
+// translation. It reports whether the description was replaced.
+func offerTranslation(cfg Config, description *string) (bool, error) {
+	t := newTranslator(cfg)
+	if t == nil {
+		if hasNonLatinLetters(*description) {
+			fmt.Println("Warning: commit descriptions must be in English. Configure \"translation\" in the config file to get a translation offered.")
+		}
+		return false, nil
+	}
+
+	lang, err := t.detect(*description)
+	if err != nil {
+		// The check is a convenience, so an unreachable service does not block the commit.
+		fmt.Printf("Warning: failed to detect the description's language: %v\n", err)
+		return false, nil
+	}
+	if lang == "en" {
+		return false, nil
+	}
+	translated, err := t.toEnglish(*description)
+	if err != nil {
+		fmt.Printf("Warning: failed to translate the description: %v\n", err)
+		return false, nil
+	}
+	translated = strings.TrimRight(strings.TrimSpace(translated), ".")
+	if translated == "" || translated == *description {
+		return false, nil
+	}
+
+	use := true
+	if err := askOne(&survey.Confirm{
+		Message: fmt.Sprintf("The description looks like '%s'. Use the English translation '%s'?", lang, translated),
+		Default: true,
+	}, &use); err != nil {
+		return false, err
+	}
+	if use {
+		*description = translated
+	}
+	return use, nil
+}
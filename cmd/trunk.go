@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+)
+
+// Limits of trunk mode when none are configured.
+const (
+	defaultTrunkMaxCommits = 10
+	defaultTrunkMaxDays    = 3
+)
+
+// TrunkConfig configures trunk mode, which discourages long-lived branches
+// for teams moving toward trunk-based development.
+type TrunkConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxCommits is how many commits a branch may have before a split is suggested.
+	MaxCommits int `json:"max_commits,omitempty"`
+	// MaxDays is how old a branch's first commit may be before a split is suggested.
+	MaxDays int `json:"max_days,omitempty"`
+}
+
+// maxCommits returns the configured commit limit, defaulting to defaultTrunkMaxCommits.
+func (t TrunkConfig) maxCommits() int {
+	if t.MaxCommits > 0 {
+		return t.MaxCommits
+	}
+	return defaultTrunkMaxCommits
+}
+
+// maxDays returns the configured age limit, defaulting to defaultTrunkMaxDays.
+func (t TrunkConfig) maxDays() int {
+	if t.MaxDays > 0 {
+		return t.MaxDays
+	}
+	return defaultTrunkMaxDays
+}
+
+// branchDivergence is how far the current branch has moved away from its base.
+type branchDivergence struct {
+	Base   string
+	Ahead  int
+	Behind int
+	// Since is when the oldest commit not on the base was authored.
+	Since time.Time
+}
+
+// currentDivergence compares HEAD with the default branch of origin.
+func currentDivergence() (branchDivergence, error) {
+	base, err := defaultBaseBranch()
+	if err != nil {
+		return branchDivergence{}, err
+	}
+	d := branchDivergence{Base: base}
+	counts, err := gitOutput("rev-list", "--left-right", "--count", base+"...HEAD")
+	if err != nil {
+		return d, fmt.Errorf("failed to compare with %s: %w", base, err)
+	}
+	if behind, ahead, ok := strings.Cut(counts, "\t"); ok {
+		d.Behind, _ = strconv.Atoi(behind)
+		d.Ahead, _ = strconv.Atoi(ahead)
+	}
+	if d.Ahead > 0 {
+		if dates, err := gitOutput("log", "--format=%at", base+"..HEAD"); err == nil {
+			lines := strings.Split(dates, "\n")
+			d.Since = parseUnix(lines[len(lines)-1])
+		}
+	}
+	return d, nil
+}
+
+// showTrunkStatus prints how far the current branch has drifted from the
+// trunk and suggests splitting it once it is over the limits. It does nothing
+// unless trunk mode is on, and on the trunk itself.
+func showTrunkStatus(cfg Config) {
+	if !cfg.Trunk.Enabled {
+		return
+	}
+	branch, err := getCurrentBranch()
+	if err != nil || branch == "HEAD" || convention.LongLivedBranchPattern.MatchString(branch) {
+		return
+	}
+	// The status is advice, so a repository without origin/HEAD just goes without it.
+	d, err := currentDivergence()
+	if err != nil {
+		return
+	}
+	days := 0
+	if !d.Since.IsZero() {
+		days = int(time.Since(d.Since).Hours() / 24)
+	}
+	fmt.Printf("Trunk: %d ahead, %d behind %s; oldest commit %d days old.\n", d.Ahead, d.Behind, d.Base, days)
+
+	var over []string
+	if d.Ahead > cfg.Trunk.maxCommits() {
+		over = append(over, fmt.Sprintf("%d commits (limit %d)", d.Ahead, cfg.Trunk.maxCommits()))
+	}
+	if days > cfg.Trunk.maxDays() {
+		over = append(over, fmt.Sprintf("%d days of work (limit %d)", days, cfg.Trunk.maxDays()))
+	}
+	if len(over) > 0 {
+		fmt.Printf("Warning: this branch has %s. Consider splitting it: merge the finished part now, behind a feature flag if it is not ready for users ('gh create-pr'), and continue on a new branch.\n", strings.Join(over, " and "))
+	}
+	if d.Behind > 0 {
+		fmt.Printf("Tip: rebase onto %s often to keep merges small: git pull --rebase origin %s\n", d.Base, strings.TrimPrefix(d.Base, "origin/"))
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the command to check existing branches and commits against the convention.
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a branch name and commit messages against the convention, e.g. in CI",
+	Long: `Check an existing branch name (--branch) and the commit messages in a range
+(--commits) against the configured conventions, with the same rules as
+'gh serve-validation'. Violations are reported and the command exits with
+status 1, so CI can run it on every pull request:
+
+  gh validate --branch "$GITHUB_HEAD_REF" --commits origin/main..HEAD
+
+--branch HEAD checks the current branch; CI checkouts are often on a
+detached HEAD, so pass the name there. Long-lived branches such as main
+and release/* always pass, and merge commits are not checked. Use
+--format json for the same report as the validation endpoint.
+
+In GitHub Actions (GITHUB_ACTIONS=true), violations are also reported as
+error annotations, shown with the checks of the pull request, and as a table
+in the job summary, with no extra setup.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{hookAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		branch, _ := cmd.Flags().GetString("branch")
+		revRange, _ := cmd.Flags().GetString("commits")
+		format, _ := cmd.Flags().GetString("format")
+		if branch == "" && revRange == "" {
+			return fmt.Errorf("nothing to validate; use --branch, --commits <range> or both")
+		}
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format '%s'; use text or json", format)
+		}
+
+		var req validationRequest
+		if branch == "HEAD" {
+			if branch, err = getCurrentBranch(); err != nil || branch == "HEAD" {
+				return fmt.Errorf("not on a branch; pass the branch name with --branch <name>")
+			}
+		}
+		req.Branch = branch
+		var commits [][2]string
+		if revRange != "" {
+			if commits, err = commitHeaders(revRange); err != nil {
+				return err
+			}
+			for _, c := range commits {
+				req.Commits = append(req.Commits, c[1])
+			}
+		}
+		resp := validate(cfg.conventionRules(), req)
+
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		} else {
+			// Workflow commands go to stdout, so JSON reports get no annotations.
+			var report *actionsReport
+			if inGitHubActions() {
+				report = newActionsReport("Convention validation")
+			}
+			if resp.Branch != nil {
+				if resp.Branch.Valid {
+					fmt.Printf("Branch '%s' follows the convention.\n", resp.Branch.Subject)
+				}
+				for _, v := range resp.Branch.Violations {
+					fmt.Printf("branch '%s' %s\n", resp.Branch.Subject, v)
+					if report != nil {
+						report.add("branch", resp.Branch.Subject, v)
+					}
+				}
+			}
+			bad := 0
+			for i, result := range resp.Commits {
+				if !result.Valid {
+					bad++
+				}
+				for _, v := range result.Violations {
+					fmt.Printf("%s %s\n", commits[i][0], v)
+					if report != nil {
+						report.add(commits[i][0], commits[i][1], v)
+					}
+				}
+			}
+			if revRange != "" && bad == 0 {
+				fmt.Printf("All %d commit(s) in %s follow the convention.\n", len(commits), revRange)
+			}
+			if report != nil {
+				var summary []string
+				if resp.Branch != nil {
+					if resp.Branch.Valid {
+						summary = append(summary, fmt.Sprintf("Branch `%s` follows the convention.", resp.Branch.Subject))
+					} else {
+						summary = append(summary, fmt.Sprintf("Branch `%s` does not follow the convention.", resp.Branch.Subject))
+					}
+				}
+				if revRange != "" {
+					if bad == 0 {
+						summary = append(summary, fmt.Sprintf("All %d commit(s) in `%s` follow the convention.", len(commits), revRange))
+					} else {
+						summary = append(summary, fmt.Sprintf("%d of %d commit(s) in `%s` do not follow the convention.", bad, len(commits), revRange))
+					}
+				}
+				if err := report.writeSummary(strings.Join(summary, " ")); err != nil {
+					fmt.Printf("Warning: %v\n", err)
+				}
+			}
+		}
+
+		if !resp.Valid {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("validation failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().String("branch", "", "Branch name to check; HEAD for the current branch")
+	validateCmd.Flags().String("commits", "", "Range of commits to check, e.g. origin/main..HEAD")
+	validateCmd.Flags().String("format", "text", "Output format: text or json")
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// areaStats sums the changes to the files of one product area.
+type areaStats struct {
+	Files     int
+	Additions int
+	Deletions int
+}
+
+// productArea returns the product whose product_paths contain path or, when
+// none do, the top-level directory of path.
+func productArea(cfg Config, path string) string {
+	for _, product := range slices.Sorted(maps.Keys(cfg.ProductPaths)) {
+		for _, p := range cfg.ProductPaths[product] {
+			p = strings.TrimSuffix(p, "/")
+			if path == p || strings.HasPrefix(path, p+"/") {
+				return product
+			}
+		}
+	}
+	if dir, _, ok := strings.Cut(path, "/"); ok {
+		return dir + "/"
+	}
+	return "(root)"
+}
+
+// diffAreas returns the changes since the merge base with base, by product area.
+func diffAreas(cfg Config, base string) (map[string]*areaStats, error) {
+	out, err := gitOutput("diff", "--numstat", "--no-renames", base+"...HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	areas := map[string]*areaStats{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		area := productArea(cfg, fields[2])
+		if areas[area] == nil {
+			areas[area] = &areaStats{}
+		}
+		s := areas[area]
+		s.Files++
+		// Binary files show "-" instead of line counts.
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		s.Additions += added
+		s.Deletions += deleted
+	}
+	return areas, nil
+}
+
+// whatChangedCmd represents the command to summarise the current branch against its base.
+var whatChangedCmd = &cobra.Command{
+	Use:   "what-changed",
+	Short: "Summarise the commits, files and tickets on the current branch",
+	Long: `Summarise everything on the current branch that is not on its base: the
+commits by type, the changed files by product area, the total diff stats and
+the tickets the branch and its commits refer to. It is the summary a pull
+request would show, available locally before pushing.
+
+Files are grouped by the products of "product_paths" in the config, and by
+their top-level directory otherwise. The base is the default branch of origin
+unless --base names another branch.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		branch, err := getCurrentBranch()
+		if err != nil {
+			return err
+		}
+		base, _ := cmd.Flags().GetString("base")
+		if base == "" {
+			if base, err = defaultBaseBranch(); err != nil {
+				return err
+			}
+		} else {
+			base = "origin/" + base
+		}
+
+		commits, err := branchCommits(base)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			fmt.Printf("Nothing on '%s' that is not on %s.\n", branch, base)
+			return nil
+		}
+		types := map[string]int{}
+		var tickets []string
+		if ticketID, err := extractTicketFromBranch(branch); err == nil {
+			tickets = append(tickets, ticketID)
+		}
+		for _, c := range commits {
+			if h, ok := convention.ParseHeader(c.Subject); ok {
+				types[h.Type]++
+			} else {
+				types["other"]++
+			}
+			for _, t := range convention.TicketRefPattern.FindAllString(c.Subject+"\n"+c.Body, -1) {
+				if !slices.Contains(tickets, t) {
+					tickets = append(tickets, t)
+				}
+			}
+		}
+		areas, err := diffAreas(cfg, base)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("What changed on '%s' since %s:\n\n", branch, base)
+		fmt.Printf("Commits (%d):\n", len(commits))
+		printCounts("By type", types)
+		for _, c := range commits {
+			fmt.Printf("  - %s\n", c.Subject)
+		}
+
+		fmt.Println("\nFiles by area:")
+		var total areaStats
+		names := slices.Collect(maps.Keys(areas))
+		slices.SortFunc(names, func(a, b string) int {
+			if d := areas[b].Files - areas[a].Files; d != 0 {
+				return d
+			}
+			return strings.Compare(a, b)
+		})
+		for _, name := range names {
+			s := areas[name]
+			fmt.Printf("  %-16s %3d file(s)  +%d -%d\n", name, s.Files, s.Additions, s.Deletions)
+			total.Files += s.Files
+			total.Additions += s.Additions
+			total.Deletions += s.Deletions
+		}
+		fmt.Printf("\nTotal: %d file(s) changed, %d insertion(s), %d deletion(s)\n", total.Files, total.Additions, total.Deletions)
+
+		if len(tickets) > 0 {
+			fmt.Println("\nTickets:")
+			for _, t := range tickets {
+				if url := ticketURL(cfg, t); url != "" {
+					fmt.Printf("  %s  %s\n", linkTicket(t), url)
+				} else {
+					fmt.Printf("  %s\n", t)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whatChangedCmd)
+	whatChangedCmd.Flags().String("base", "", "Branch to compare with (default: the default branch of origin)")
+}
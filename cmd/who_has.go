@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abhinav-lv-amagi/amagi-git-helper/pkg/convention"
+	"github.com/spf13/cobra"
+)
+
+// whoHasColumns are the columns of the who-has report.
+var whoHasColumns = []tableColumn{
+	{name: "who"},
+	{name: "repo"},
+	{name: "branch", truncate: true},
+	{name: "state"},
+	{name: "pr"},
+	{name: "when", kind: columnDateTime},
+}
+
+// remoteTicketBranches returns the branches of origin for a ticket as shared
+// branches, owned by the author of their latest commit and dated by it.
+func remoteTicketBranches(repo, ticket string) ([]sharedBranch, error) {
+	out, err := gitOutput("for-each-ref", "--format=%(refname:lstrip=3)%09%(authoremail:trim)%09%(committerdate:unix)", "refs/remotes/origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	var branches []sharedBranch
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 || parts[0] == "HEAD" {
+			continue
+		}
+		if t, err := extractTicketFromBranch(parts[0]); err != nil || !strings.EqualFold(t, ticket) {
+			continue
+		}
+		branches = append(branches, sharedBranch{Owner: parts[1], Repo: repo, Branch: parts[0], Ticket: ticket, CreatedAt: parseUnix(parts[2]), State: branchOpen})
+	}
+	return branches, nil
+}
+
+// whoHasCmd represents the command to find everyone working on a ticket.
+var whoHasCmd = &cobra.Command{
+	Use:   "who-has <TICKET>",
+	Short: "Show who already has branches or open pull requests for a ticket",
+	Long: `Before starting on a ticket, check that nobody else is: list the branches made
+for it by anyone on the team, with their open pull requests.
+
+Branches come from two places. With a shared store (storage.backend sqlite,
+postgres or http), every branch a teammate created with this tool is listed,
+in any repository. The branches of origin for the ticket are listed too, owned
+by the author of their latest commit, after fetching from origin unless
+--no-fetch is given. Open pull requests of origin's branches are looked up
+with the provider's API when a token is configured.`,
+	Example:           `  gh who-has CPRE-11347`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		ticket := strings.ToUpper(args[0])
+		if err := convention.ValidateTicketID(ticket); err != nil {
+			return err
+		}
+
+		branches, err := store.ticketBranches(ticket)
+		if err != nil {
+			fmt.Printf("Warning: failed to look up the store: %v\n", err)
+		}
+
+		// Outside a repository, or without an origin, only the store is asked.
+		if remoteURL, err := gitOutput("remote", "get-url", "origin"); err == nil {
+			repo := remoteURL
+			if host, path, err := parseRemoteURL(remoteURL); err == nil {
+				repo = host + "/" + path
+			}
+			if noFetch, _ := cmd.Flags().GetBool("no-fetch"); !noFetch {
+				if _, err := gitOutput("fetch", "--prune", "--quiet", "origin"); err != nil {
+					fmt.Printf("Warning: failed to fetch from origin, using the branches fetched before: %v\n", err)
+				}
+			}
+			remote, err := remoteTicketBranches(repo, ticket)
+			if err != nil {
+				return err
+			}
+			var prs prProvider
+			if len(remote) > 0 {
+				provider, err := originProvider(cfg)
+				if err == nil {
+					prs, err = newPRProvider(cfg, provider)
+				}
+				if err != nil {
+					fmt.Printf("Warning: not looking up pull requests: %v\n", err)
+				}
+			}
+			for _, b := range remote {
+				if prs != nil {
+					link, err := prs.findOpen(b.Branch)
+					if err != nil {
+						fmt.Printf("Warning: failed to look up the pull request of %s: %v\n", b.Branch, err)
+					} else if link != nil {
+						b.PR = link.URL
+					}
+				}
+				branches = mergeSharedBranch(branches, b)
+			}
+		}
+
+		if len(branches) == 0 {
+			fmt.Printf("Nobody has a branch for %s yet.\n", linkTicket(ticket))
+			return nil
+		}
+		me := storeOwner(cfg.Storage.Owner)
+		t := newTable(whoHasColumns...)
+		for _, b := range branches {
+			who := b.Owner
+			if strings.EqualFold(who, me) || strings.EqualFold(who, committerEmail()) {
+				who += " (you)"
+			}
+			t.add(who, b.Repo, b.Branch, b.State, b.PR, b.CreatedAt)
+		}
+		return t.render(cmd, "")
+	},
+}
+
+// mergeSharedBranch adds a branch of origin to the branches from the store.
+// A branch the store already knows keeps its owner, who created it, and
+// takes the open pull request found on origin.
+func mergeSharedBranch(branches []sharedBranch, b sharedBranch) []sharedBranch {
+	for i, known := range branches {
+		if known.Repo == b.Repo && known.Branch == b.Branch {
+			if b.PR != "" {
+				branches[i].PR = b.PR
+			}
+			return branches
+		}
+	}
+	return append(branches, b)
+}
+
+func init() {
+	rootCmd.AddCommand(whoHasCmd)
+	whoHasCmd.Flags().Bool("no-fetch", false, "Use the remote branches fetched before instead of fetching from origin")
+	addTableFlags(whoHasCmd, whoHasColumns, "when:desc")
+}
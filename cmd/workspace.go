@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// isGitRepo reports whether dir is the top level of a git repository.
+func isGitRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// workspaceRepos returns the repositories of the workspace. Each configured
+// entry is either a repository or a directory whose subdirectories are
+// repositories.
+func workspaceRepos(cfg Config) []string {
+	var repos []string
+	for _, entry := range cfg.Workspace {
+		dir := expandHome(entry)
+		if isGitRepo(dir) {
+			repos = append(repos, dir)
+			continue
+		}
+		children, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if path := filepath.Join(dir, child.Name()); child.IsDir() && isGitRepo(path) {
+				repos = append(repos, path)
+			}
+		}
+	}
+	return repos
+}
@@ -0,0 +1,42 @@
+package convention
+
+import "testing"
+
+// The commit-msg hook and CI validate on every commit, so these must stay in
+// the microseconds; run them with go test -bench . -benchmem.
+
+func BenchmarkValidateCommitHeader(b *testing.B) {
+	rules := DefaultRules()
+	for b.Loop() {
+		rules.ValidateCommitHeader("fix(lego): handle empty playlists")
+	}
+}
+
+func BenchmarkValidateCommitHeaderInvalid(b *testing.B) {
+	rules := DefaultRules()
+	for b.Loop() {
+		rules.ValidateCommitHeader("Fixed the playlists.")
+	}
+}
+
+func BenchmarkValidateBranch(b *testing.B) {
+	rules := DefaultRules()
+	for b.Loop() {
+		rules.ValidateBranch("lv-fix-user-details-window-width/CPRE-11347")
+	}
+}
+
+func BenchmarkValidateBranchTemplate(b *testing.B) {
+	rules := DefaultRules()
+	rules.BranchTemplate = "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}-{{.Fields.team}}"
+	rules.CustomFields = []string{"team"}
+	for b.Loop() {
+		rules.ValidateBranch("lv/fix/CPRE-11347-window-width-core")
+	}
+}
+
+func BenchmarkParseBranch(b *testing.B) {
+	for b.Loop() {
+		ParseBranch("lv-fix-user-details-window-width/CPRE-11347")
+	}
+}
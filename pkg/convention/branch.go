@@ -0,0 +1,148 @@
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Branch holds the parts of a convention branch name.
+type Branch struct {
+	Abbreviation string
+	Type         string
+	Description  string
+	Ticket       string
+	// Env is the affected environment of templates that use {{.Env}}.
+	Env string
+	// Fields holds the custom fields of templates that use them.
+	Fields map[string]string
+}
+
+// String assembles the branch name in the default format, lowercasing the
+// abbreviation and description.
+func (b Branch) String() string {
+	return fmt.Sprintf("%s-%s-%s/%s", strings.ToLower(b.Abbreviation), b.Type, strings.ToLower(b.Description), b.Ticket)
+}
+
+// ParseBranch splits a branch name in the default format into its parts. It
+// reports false if the name does not have the shape of a convention branch;
+// the parts are not checked against any rules.
+func ParseBranch(name string) (Branch, bool) {
+	return defaultBranchTemplate.Parse(name)
+}
+
+// BranchPattern returns a pattern matching only branch names in the default
+// format that follow the convention with the given branch types.
+func BranchPattern(types []string) *regexp.Regexp {
+	return defaultBranchTemplate.Pattern(types)
+}
+
+// FormatDescription replaces spaces with hyphens and checks the length of a
+// branch description.
+func FormatDescription(description string, maxLength int) (string, error) {
+	formatted := strings.ReplaceAll(description, " ", "-")
+	if err := CheckLength("description", formatted, maxLength); err != nil {
+		return "", err
+	}
+	if len(formatted) == 0 {
+		return "", fmt.Errorf("description cannot be empty")
+	}
+	return formatted, nil
+}
+
+// nonSlugChars matches runs of characters that are not allowed in a branch description.
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns a ticket summary into a branch description, cutting it at a
+// word boundary so it fits within maxLength.
+func Slugify(summary string, maxLength int) string {
+	if maxLength <= 0 {
+		return ""
+	}
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(summary), "-"), "-")
+	for len(slug) > maxLength {
+		i := strings.LastIndex(slug, "-")
+		if i <= 0 {
+			return slug[:maxLength]
+		}
+		slug = slug[:i]
+	}
+	return slug
+}
+
+// compiledTemplates holds the branch templates compiled by Rules.Template,
+// keyed by their source and custom fields.
+var compiledTemplates sync.Map
+
+// Template compiles the branch template of the rules. Each template is only
+// compiled once, however often the rules are checked.
+func (r Rules) Template() (*BranchTemplate, error) {
+	if r.BranchTemplate == "" {
+		return defaultBranchTemplate, nil
+	}
+	key := r.BranchTemplate + "\x00" + strings.Join(r.CustomFields, "\x00")
+	if t, ok := compiledTemplates.Load(key); ok {
+		return t.(*BranchTemplate), nil
+	}
+	t, err := ParseBranchTemplate(r.BranchTemplate, r.CustomFields...)
+	if err != nil {
+		return nil, err
+	}
+	compiledTemplates.Store(key, t)
+	return t, nil
+}
+
+// BranchName validates the parts of a branch and assembles its name. The
+// description may contain spaces, which become hyphens.
+func (r Rules) BranchName(b Branch) (string, error) {
+	r = r.withDefaults()
+	tmpl, err := r.Template()
+	if err != nil {
+		return "", err
+	}
+	if len(b.Abbreviation) != 2 {
+		return "", fmt.Errorf("abbreviation must be two letters")
+	}
+	if err := ValidateChoice("branch type", b.Type, r.BranchTypes); err != nil {
+		return "", err
+	}
+	desc, err := FormatDescription(b.Description, r.MaxBranchDescription)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateTicketID(b.Ticket); err != nil {
+		return "", err
+	}
+	b.Description = desc
+	return tmpl.Render(b)
+}
+
+// ValidateBranch checks a branch name against the convention, explaining the
+// first problem found. Long-lived base branches such as main are accepted.
+func (r Rules) ValidateBranch(name string) error {
+	r = r.withDefaults()
+	if LongLivedBranchPattern.MatchString(name) {
+		return nil
+	}
+	tmpl, err := r.Template()
+	if err != nil {
+		return err
+	}
+	b, ok := tmpl.Parse(name)
+	if !ok {
+		return fmt.Errorf("branch name must look like '%s'", tmpl.Example())
+	}
+	if b.Type != "" {
+		if err := ValidateChoice("branch type", b.Type, r.BranchTypes); err != nil {
+			return err
+		}
+	}
+	if err := CheckLength("description", b.Description, r.MaxBranchDescription); err != nil {
+		return err
+	}
+	if !tmpl.Pattern(r.BranchTypes).MatchString(name) {
+		return fmt.Errorf("abbreviation and description must be lowercase letters, digits and hyphens")
+	}
+	return nil
+}
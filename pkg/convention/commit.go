@@ -0,0 +1,139 @@
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Header holds the parts of a convention commit header.
+type Header struct {
+	Type        string
+	Product     string
+	Description string
+}
+
+// String assembles the header, e.g. "fix(lego): handle empty playlists".
+func (h Header) String() string {
+	return fmt.Sprintf("%s(%s): %s", h.Type, h.Product, h.Description)
+}
+
+// HeaderPattern matches a conventional header and captures type, product and description.
+var HeaderPattern = regexp.MustCompile(`^(\w+)\(([\w-]+)\): (.+)$`)
+
+// ParseHeader splits a commit header into its parts. It reports false if the
+// header does not have the shape "type(product): description".
+func ParseHeader(header string) (Header, bool) {
+	m := HeaderPattern.FindStringSubmatch(header)
+	if m == nil {
+		return Header{}, false
+	}
+	return Header{Type: m[1], Product: m[2], Description: m[3]}, true
+}
+
+// TicketVerb returns the verb of the ticket trailer for a commit type:
+// "Fixes" for fix, "Closes" for feat and "Refs" for any other type.
+func TicketVerb(commitType string) string {
+	switch commitType {
+	case "fix":
+		return "Fixes"
+	case "feat":
+		return "Closes"
+	default:
+		return "Refs"
+	}
+}
+
+// CheckCommitDescription checks a commit description's length and style for
+// the given header, joining all problems into one error.
+func (r Rules) CheckCommitDescription(h Header) error {
+	r = r.withDefaults()
+	if len(h.Description) == 0 {
+		return fmt.Errorf("commit description cannot be empty")
+	}
+	if err := CheckLength("commit description", h.Description, r.MaxCommitDescription); err != nil {
+		return err
+	}
+	// Enforce the style rules, suggesting a fixed description where possible.
+	if violations := r.Style.CheckDescription(h.String(), h.Description); len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.String()
+		}
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// compiledSubjects holds the subject templates compiled by
+// Rules.SubjectTemplate, keyed by their source and custom fields.
+var compiledSubjects sync.Map
+
+// defaultMessageTemplate is the compiled default commit message template.
+var defaultMessageTemplate = MustParseMessageTemplate("", "")
+
+// SubjectTemplate compiles the commit subject template of the rules. Each
+// template is only compiled once, however often the rules are checked.
+func (r Rules) SubjectTemplate() (*MessageTemplate, error) {
+	if r.CommitSubjectTemplate == "" && len(r.CustomFields) == 0 {
+		return defaultMessageTemplate, nil
+	}
+	key := r.CommitSubjectTemplate + "\x00" + strings.Join(r.CustomFields, "\x00")
+	if t, ok := compiledSubjects.Load(key); ok {
+		return t.(*MessageTemplate), nil
+	}
+	t, err := ParseMessageTemplate(r.CommitSubjectTemplate, "", r.CustomFields...)
+	if err != nil {
+		return nil, err
+	}
+	compiledSubjects.Store(key, t)
+	return t, nil
+}
+
+// LintHeader checks a commit header for the format of the subject template
+// and the style rules, but not for the allowed types and products.
+func (r Rules) LintHeader(header string) []Violation {
+	tmpl, err := r.SubjectTemplate()
+	if err != nil {
+		return []Violation{{Rule: RuleHeaderFormat, Message: err.Error()}}
+	}
+	h, ok := tmpl.ParseHeader(header)
+	if !ok {
+		return []Violation{{
+			Rule:    RuleHeaderFormat,
+			Message: fmt.Sprintf("header must look like '%s'", tmpl.Example()),
+		}}
+	}
+	return r.Style.CheckDescription(header, h.Description)
+}
+
+// ValidateCommitHeader checks a commit header against the convention: its
+// format, type, product, description length and the style rules. The format
+// is that of the subject template; the parts it does not use are not checked.
+func (r Rules) ValidateCommitHeader(header string) []Violation {
+	r = r.withDefaults()
+	tmpl, err := r.SubjectTemplate()
+	if err != nil {
+		return []Violation{{Rule: RuleHeaderFormat, Message: err.Error()}}
+	}
+	h, ok := tmpl.ParseHeader(header)
+	if !ok {
+		return r.LintHeader(header)
+	}
+	var violations []Violation
+	if h.Type != "" {
+		if err := ValidateChoice("commit type", h.Type, r.CommitTypes); err != nil {
+			violations = append(violations, Violation{Rule: RuleHeaderType, Message: err.Error()})
+		}
+	}
+	if h.Product != "" {
+		if err := ValidateChoice("product", h.Product, r.Products); err != nil {
+			violations = append(violations, Violation{Rule: RuleHeaderProduct, Message: err.Error()})
+		}
+	}
+	if err := CheckLength("commit description", h.Description, r.MaxCommitDescription); err != nil {
+		violations = append(violations, Violation{Rule: RuleDescriptionLength, Message: err.Error()})
+	}
+	return append(violations, r.Style.CheckDescription(header, h.Description)...)
+}
@@ -0,0 +1,120 @@
+// Package convention implements the branch and commit naming convention
+// enforced by git-helper-cli, so other tools and bots can validate names and
+// messages with exactly the same rules as the CLI.
+//
+// Branch names look like <abbreviation>-<type>-<short_desc>/<JIRA_ticket_id>,
+// e.g. "lv-fix-user-details-window-width/CPRE-11347". Commit headers look like
+// <type>(<product>): <description>, e.g. "fix(lego): handle empty playlists".
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default branch and commit types, products and description lengths.
+var (
+	DefaultBranchTypes = []string{"fix", "feat"}
+	DefaultCommitTypes = []string{"fix", "feat"}
+	DefaultProducts    = []string{"lego", "plec"}
+)
+
+// SpikeBranchType is the type of experimental branches, which expire on a set
+// date. It is accepted whatever branch types are configured.
+const SpikeBranchType = "spike"
+
+// HotfixBranchType is the type of incident-response branches created by the
+// incident command. Like spike, it is accepted whatever branch types are configured.
+const HotfixBranchType = "hotfix"
+
+const (
+	DefaultMaxBranchDescription = 30
+	DefaultMaxCommitDescription = 50
+)
+
+// Rules is a complete set of convention rules. The zero value of a field
+// means its default.
+type Rules struct {
+	BranchTypes []string
+	CommitTypes []string
+	Products    []string
+	// MaxBranchDescription is measured after spaces are replaced with hyphens.
+	MaxBranchDescription int
+	MaxCommitDescription int
+	Style                Style
+	// BranchTemplate is the branch name format, see ParseBranchTemplate.
+	BranchTemplate string
+	// CommitSubjectTemplate is the commit header format, see ParseMessageTemplate.
+	CommitSubjectTemplate string
+	// CustomFields are the names of the custom fields templates may use.
+	CustomFields []string
+}
+
+// DefaultRules returns the rules used when nothing is configured.
+func DefaultRules() Rules {
+	return Rules{
+		BranchTypes:          DefaultBranchTypes,
+		CommitTypes:          DefaultCommitTypes,
+		Products:             DefaultProducts,
+		MaxBranchDescription: DefaultMaxBranchDescription,
+		MaxCommitDescription: DefaultMaxCommitDescription,
+	}
+}
+
+// withDefaults fills in the zero fields of r with their defaults.
+func (r Rules) withDefaults() Rules {
+	d := DefaultRules()
+	if len(r.BranchTypes) == 0 {
+		r.BranchTypes = d.BranchTypes
+	}
+	if len(r.CommitTypes) == 0 {
+		r.CommitTypes = d.CommitTypes
+	}
+	if len(r.Products) == 0 {
+		r.Products = d.Products
+	}
+	if r.MaxBranchDescription <= 0 {
+		r.MaxBranchDescription = d.MaxBranchDescription
+	}
+	if r.MaxCommitDescription <= 0 {
+		r.MaxCommitDescription = d.MaxCommitDescription
+	}
+	return r
+}
+
+// TicketIDPattern matches JIRA ticket IDs such as CPRE-11347.
+var TicketIDPattern = regexp.MustCompile(`^[A-Za-z]+-\d+$`)
+
+// TicketRefPattern finds JIRA ticket references such as CPRE-11347 in free text.
+var TicketRefPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-\d+\b`)
+
+// LongLivedBranchPattern matches base branches that are not expected to follow the convention.
+var LongLivedBranchPattern = regexp.MustCompile(`^(main|master|develop|release/.*|HEAD)$`)
+
+// ValidateTicketID checks that a JIRA ticket ID looks like ABC-123.
+func ValidateTicketID(ticketID string) error {
+	if !TicketIDPattern.MatchString(ticketID) {
+		return fmt.Errorf("ticket ID must be in format ABC-123")
+	}
+	return nil
+}
+
+// ValidateChoice checks that value is one of the allowed options; what names
+// the value in the error, e.g. "branch type".
+func ValidateChoice(what, value string, options []string) error {
+	if !slices.Contains(options, value) {
+		return fmt.Errorf("%s must be one of: %s", what, strings.Join(options, ", "))
+	}
+	return nil
+}
+
+// CheckLength returns an error saying by how much text exceeds maxLength, if it does.
+func CheckLength(what, text string, maxLength int) error {
+	if n := utf8.RuneCountInString(text); n > maxLength {
+		return fmt.Errorf("%s too long: %d characters, %d over the limit of %d", what, n, n-maxLength, maxLength)
+	}
+	return nil
+}
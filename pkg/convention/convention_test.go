@@ -0,0 +1,303 @@
+package convention
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidateBranch(t *testing.T) {
+	custom := Rules{BranchTypes: []string{"fix", "chore"}, BranchTemplate: "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}"}
+	tests := []struct {
+		name   string
+		rules  Rules
+		branch string
+		valid  bool
+	}{
+		{"default format", Rules{}, "lv-fix-user-details-window-width/CPRE-11347", true},
+		{"long-lived branch", Rules{}, "main", true},
+		{"release branch", Rules{}, "release/1.4", true},
+		{"configured type", Rules{BranchTypes: []string{"fix", SpikeBranchType}}, "lv-spike-try-redis-cache/CPRE-11347", true},
+		{"unknown type", Rules{}, "lv-chore-bump-deps/CPRE-11347", false},
+		{"missing ticket", Rules{}, "lv-fix-user-details-window-width", false},
+		{"invalid ticket", Rules{}, "lv-fix-window-width/CPRE", false},
+		{"uppercase description", Rules{}, "lv-fix-Window-Width/CPRE-11347", false},
+		{"uppercase abbreviation", Rules{}, "LV-fix-window-width/CPRE-11347", false},
+		{"description at the limit", Rules{}, "lv-fix-" + strings.Repeat("a", 30) + "/CPRE-1", true},
+		{"description over the limit", Rules{}, "lv-fix-" + strings.Repeat("a", 31) + "/CPRE-1", false},
+		{"configured limit", Rules{MaxBranchDescription: 10}, "lv-fix-window-width/CPRE-1", false},
+		{"custom template", custom, "lv/chore/CPRE-11347-bump-deps", true},
+		{"custom template, default format", custom, "lv-chore-bump-deps/CPRE-11347", false},
+		{"custom template, unknown type", custom, "lv/feat/CPRE-11347-bump-deps", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.ValidateBranch(tt.branch)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateBranch(%q) = %v, want valid %v", tt.branch, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  Rules
+		branch Branch
+		want   string
+	}{
+		{"default format", Rules{}, Branch{Abbreviation: "lv", Type: "fix", Description: "window width", Ticket: "CPRE-11347"}, "lv-fix-window-width/CPRE-11347"},
+		{"custom template", Rules{BranchTemplate: "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}"}, Branch{Abbreviation: "lv", Type: "feat", Description: "dark mode", Ticket: "CPRE-1"}, "lv/feat/CPRE-1-dark-mode"},
+		{"long abbreviation", Rules{}, Branch{Abbreviation: "lvx", Type: "fix", Description: "window", Ticket: "CPRE-1"}, ""},
+		{"unknown type", Rules{}, Branch{Abbreviation: "lv", Type: "chore", Description: "window", Ticket: "CPRE-1"}, ""},
+		{"empty description", Rules{}, Branch{Abbreviation: "lv", Type: "fix", Ticket: "CPRE-1"}, ""},
+		{"description too long", Rules{}, Branch{Abbreviation: "lv", Type: "fix", Description: strings.Repeat("a", 31), Ticket: "CPRE-1"}, ""},
+		{"invalid ticket", Rules{}, Branch{Abbreviation: "lv", Type: "fix", Description: "window", Ticket: "11347"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rules.BranchName(tt.branch)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("BranchName(%+v) = %q, want an error", tt.branch, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("BranchName(%+v) = %q, %v, want %q", tt.branch, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommitHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  Rules
+		header string
+		want   []string
+	}{
+		{"valid", Rules{}, "fix(lego): handle empty playlists", nil},
+		{"unknown type", Rules{}, "chore(lego): bump dependencies", []string{RuleHeaderType}},
+		{"unknown product", Rules{}, "fix(blip): handle empty playlists", []string{RuleHeaderProduct}},
+		{"configured products", Rules{Products: []string{"blip"}}, "fix(blip): handle empty playlists", nil},
+		{"no product", Rules{}, "fix: handle empty playlists", []string{RuleHeaderFormat}},
+		{"description at the limit", Rules{}, "fix(lego): " + strings.Repeat("a", 50), nil},
+		{"description over the limit", Rules{}, "fix(lego): " + strings.Repeat("a", 51), []string{RuleDescriptionLength}},
+		{"configured limit", Rules{MaxCommitDescription: 10}, "fix(lego): handle empty playlists", []string{RuleDescriptionLength}},
+		{"header over the limit", Rules{MaxCommitDescription: 100}, "fix(lego): " + strings.Repeat("a", 62), []string{RuleHeaderMaxLength}},
+		{"capitalized", Rules{}, "fix(lego): Handle empty playlists", []string{RuleSubjectCase}},
+		{"full stop", Rules{}, "fix(lego): handle empty playlists.", []string{RuleSubjectFullStop}},
+		{"past tense", Rules{}, "fix(lego): handled empty playlists", []string{RuleSubjectMood}},
+		{"disabled rule", Rules{Style: Style{Disabled: []string{RuleSubjectFullStop}}}, "fix(lego): handle empty playlists.", nil},
+		{"subject template", Rules{CommitSubjectTemplate: "[{{.Ticket}}] {{.Type}}: {{.Desc}}"}, "[CPRE-1] fix: handle empty playlists", nil},
+		{"subject template, default format", Rules{CommitSubjectTemplate: "[{{.Ticket}}] {{.Type}}: {{.Desc}}"}, "fix(lego): handle empty playlists", []string{RuleHeaderFormat}},
+		{"subject template, unknown type", Rules{CommitSubjectTemplate: "[{{.Ticket}}] {{.Type}}: {{.Desc}}"}, "[CPRE-1] chore: handle empty playlists", []string{RuleHeaderType}},
+		{"subject template, past tense", Rules{CommitSubjectTemplate: "{{.Type}}({{.Product}}): {{.Desc}} [{{.Ticket}}]"}, "fix(lego): handled empty playlists [CPRE-1]", []string{RuleSubjectMood}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.rules.ValidateCommitHeader(tt.header)
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ValidateCommitHeader(%q) = %v, want violations of %v", tt.header, violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageTemplateHeaderPattern(t *testing.T) {
+	tests := []struct {
+		source  string
+		example string
+		match   []string
+		reject  []string
+	}{
+		{
+			source:  DefaultCommitSubjectTemplate,
+			example: "type(product): description",
+			match:   []string{"fix(lego): handle empty playlists"},
+			reject:  []string{"chore(lego): bump", "fix(blip): handle", "fix: handle", "[CPRE-1] fix: handle"},
+		},
+		{
+			source:  "[{{.Ticket}}] {{.Type}}: {{.Desc}}",
+			example: "[TICKET-123] type: description",
+			match:   []string{"[CPRE-1] fix: handle empty playlists"},
+			reject:  []string{"fix(lego): handle empty playlists", "[CPRE] fix: handle", "[CPRE-1] chore: bump"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			tmpl, err := ParseMessageTemplate(tt.source, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tmpl.Example(); got != tt.example {
+				t.Errorf("Example() = %q, want %q", got, tt.example)
+			}
+			pattern := tmpl.HeaderPattern(DefaultCommitTypes, DefaultProducts)
+			for _, header := range tt.match {
+				m := pattern.FindStringSubmatch(header)
+				if m == nil {
+					t.Errorf("HeaderPattern %s does not match %q", pattern, header)
+					continue
+				}
+				h, _ := tmpl.ParseHeader(header)
+				if got := m[tmpl.DescriptionGroup()]; got != h.Description {
+					t.Errorf("DescriptionGroup of %q = %q, want %q", header, got, h.Description)
+				}
+			}
+			for _, header := range tt.reject {
+				if pattern.MatchString(header) {
+					t.Errorf("HeaderPattern %s matches %q", pattern, header)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMessageTemplateErrors(t *testing.T) {
+	for _, source := range []string{
+		"{{.Type}}{{.Desc}}",
+		"{{.Desc}}{{.Unknown}}",
+		"{{.Desc}",
+	} {
+		if _, err := ParseMessageTemplate(source, ""); err == nil {
+			t.Errorf("ParseMessageTemplate(%q) succeeded, want an error", source)
+		}
+	}
+}
+
+func TestValidateTicketID(t *testing.T) {
+	for ticket, valid := range map[string]bool{
+		"CPRE-11347": true,
+		"abc-1":      true,
+		"CPRE":       false,
+		"CPRE-":      false,
+		"-11347":     false,
+		"CPRE11347":  false,
+		"CP2-1":      false,
+		"":           false,
+	} {
+		if err := ValidateTicketID(ticket); (err == nil) != valid {
+			t.Errorf("ValidateTicketID(%q) = %v, want valid %v", ticket, err, valid)
+		}
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	tests := []struct {
+		text  string
+		max   int
+		valid bool
+	}{
+		{"window", 6, true},
+		{"window", 5, false},
+		{"", 0, true},
+		{"ünïcödé", 7, true},
+		{"ünïcödé", 6, false},
+	}
+	for _, tt := range tests {
+		if err := CheckLength("description", tt.text, tt.max); (err == nil) != tt.valid {
+			t.Errorf("CheckLength(%q, %d) = %v, want valid %v", tt.text, tt.max, err, tt.valid)
+		}
+	}
+}
+
+func TestTemplatePatternAndExample(t *testing.T) {
+	tests := []struct {
+		source  string
+		example string
+		match   []string
+		reject  []string
+	}{
+		{
+			source:  DefaultBranchTemplate,
+			example: "<abbreviation>-<type>-<short_desc>/<TICKET-123>",
+			match:   []string{"lv-fix-window-width/CPRE-11347", "lv-feat-dark-mode-2/AB-1"},
+			reject:  []string{"lv-chore-window-width/CPRE-11347", "lv-fix-Window/CPRE-1", "lv-fix-window", "xlv-fix-window/CPRE-1"},
+		},
+		{
+			source:  "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}",
+			example: "<abbreviation>/<type>/<TICKET-123>-<short_desc>",
+			match:   []string{"lv/fix/CPRE-11347-window-width"},
+			reject:  []string{"lv-fix-window-width/CPRE-11347", "lv/fix/CPRE-window"},
+		},
+		{
+			source:  "{{.Type}}/{{.Ticket}}/{{.Desc}}",
+			example: "<type>/<TICKET-123>/<short_desc>",
+			match:   []string{"feat/CPRE-1/dark-mode"},
+			reject:  []string{"lv/feat/CPRE-1/dark-mode", "chore/CPRE-1/dark-mode"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			tmpl, err := ParseBranchTemplate(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tmpl.Example(); got != tt.example {
+				t.Errorf("Example() = %q, want %q", got, tt.example)
+			}
+			pattern := tmpl.Pattern(DefaultBranchTypes)
+			for _, name := range tt.match {
+				if !pattern.MatchString(name) {
+					t.Errorf("Pattern %s does not match %q", pattern, name)
+				}
+			}
+			for _, name := range tt.reject {
+				if pattern.MatchString(name) {
+					t.Errorf("Pattern %s matches %q", pattern, name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBranchTemplateErrors(t *testing.T) {
+	for _, source := range []string{
+		"{{.Abbrev}}-{{.Type}}/{{.Ticket}}",
+		"{{.Abbrev}}-{{.Desc}}",
+		"{{.Desc}}/{{.Ticket}}/{{.Desc}}",
+		"{{.Desc}}/{{.Ticket}}/{{.Unknown}}",
+		"{{.Desc}}{{.Ticket}",
+	} {
+		if _, err := ParseBranchTemplate(source); err == nil {
+			t.Errorf("ParseBranchTemplate(%q) succeeded, want an error", source)
+		}
+	}
+}
+
+func TestAlternation(t *testing.T) {
+	tests := []struct {
+		options []string
+		want    string
+		match   []string
+		reject  []string
+	}{
+		{[]string{"fix", "feat"}, "fix|feat", []string{"fix", "feat"}, []string{"fi", "chore"}},
+		{[]string{"c++", "a.b"}, `c\+\+|a\.b`, []string{"c++", "a.b"}, []string{"cc", "axb"}},
+		{[]string{"\xff"}, "�", []string{"�"}, []string{"x"}},
+	}
+	for _, tt := range tests {
+		got := Alternation(tt.options)
+		if got != tt.want {
+			t.Errorf("Alternation(%q) = %q, want %q", tt.options, got, tt.want)
+		}
+		re := regexp.MustCompile("^(" + got + ")$")
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("Alternation(%q) does not match %q", tt.options, s)
+			}
+		}
+		for _, s := range tt.reject {
+			if re.MatchString(s) {
+				t.Errorf("Alternation(%q) matches %q", tt.options, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package convention
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Branch names, commit messages and templates reach the parsers from hooks
+// and config files, so the fuzz targets check that no input makes them panic
+// and that what they accept round-trips.
+
+func FuzzParseBranch(f *testing.F) {
+	for _, seed := range []string{
+		"lv-fix-user-details-window-width/CPRE-11347",
+		"",
+		"/",
+		"main",
+		"ab-fix-/A-1",
+		"ab-fix-ümlaut-ß/CPRE-1",
+		"ab-fix-" + strings.Repeat("x-", 4096) + "/CPRE-1",
+		"ab-fix-a/b/c/CPRE-1\x00",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		b, ok := ParseBranch(name)
+		if !ok {
+			return
+		}
+		if !TicketIDPattern.MatchString(b.Ticket) {
+			t.Errorf("ParseBranch(%q) ticket %q is not a ticket ID", name, b.Ticket)
+		}
+		if got := b.String(); got != name && strings.ToLower(name) == name {
+			t.Errorf("ParseBranch(%q) = %+v, which assembles to %q", name, b, got)
+		}
+		DefaultRules().ValidateBranch(name)
+	})
+}
+
+func FuzzBranchTemplate(f *testing.F) {
+	f.Add(DefaultBranchTemplate, "fix", "some desc", "ABC-1", "prod")
+	f.Add("{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}", "feat", "ünïcode", "X-9", "")
+	f.Add("{{.Desc}}\x00A\x00{{.Ticket}}", "fix", "a", "B-2", "")
+	f.Add("{{.Desc}}{{.Ticket}}", "", "", "", "")
+	f.Add("{{.Env}}/{{.Desc}}/{{.Ticket}}", "fix", "a", "B-2", "pro d")
+	f.Add("{{range .Fields}}{{end}}{{.Desc}}/{{.Ticket}}", "fix", "a", "B-2", "")
+	f.Add(`{{"\x00"}}{{.Desc}}/{{.Ticket}}`, "fix", "a", "B-2", "")
+	f.Fuzz(func(t *testing.T, source, branchType, desc, ticket, env string) {
+		tmpl, err := ParseBranchTemplate(source, "team")
+		if err != nil {
+			return
+		}
+		tmpl.Example()
+		tmpl.Pattern([]string{branchType})
+		b := Branch{Abbreviation: "ab", Type: branchType, Description: desc, Ticket: ticket, Env: env}
+		name, err := tmpl.Render(b)
+		if err != nil {
+			return
+		}
+		if ValidateRefName(name) != nil {
+			t.Errorf("Render(%+v) with %q = %q, which is not a valid ref", b, source, name)
+		}
+		tmpl.Parse(name)
+	})
+}
+
+func FuzzSlugify(f *testing.F) {
+	f.Add("Handle empty playlists", 30)
+	f.Add("", 0)
+	f.Add("---", 5)
+	f.Add("ÜNICODE summäry – with dashes", 10)
+	f.Add("averyveryverylongwordwithoutbreaks", 3)
+	f.Add("a b", -1)
+	f.Fuzz(func(t *testing.T, summary string, maxLength int) {
+		slug := Slugify(summary, maxLength)
+		if len(slug) > max(maxLength, 0) {
+			t.Errorf("Slugify(%q, %d) = %q, longer than the limit", summary, maxLength, slug)
+		}
+		if strings.Trim(slug, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			t.Errorf("Slugify(%q, %d) = %q, which has characters not allowed in a description", summary, maxLength, slug)
+		}
+	})
+}
+
+func FuzzValidateCommitHeader(f *testing.F) {
+	for _, seed := range []string{
+		"fix(lego): handle empty playlists",
+		"",
+		"fix(lego): ",
+		"Fix(lego): Added Support.",
+		"feat(plec): Ärger über Umlaute",
+		"fix(lego): \xff\xfe",
+		"fix(lego): " + strings.Repeat("é", 10000),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, header string) {
+		for _, v := range DefaultRules().ValidateCommitHeader(header) {
+			if v.Suggestion != "" && !utf8.ValidString(v.Suggestion) && utf8.ValidString(header) {
+				t.Errorf("ValidateCommitHeader(%q) suggests invalid UTF-8 %q", header, v.Suggestion)
+			}
+		}
+		if h, ok := ParseHeader(header); ok && h.String() != header {
+			t.Errorf("ParseHeader(%q) = %+v, which assembles to %q", header, h, h.String())
+		}
+	})
+}
+
+func FuzzParseTrailers(f *testing.F) {
+	for _, seed := range []string{
+		"fix(lego): x\n\nFixes CPRE-1\n\nTicket: CPRE-1\nProduct: lego",
+		"",
+		"\n\n",
+		"subject\r\n\r\nTicket:   CPRE-1  ",
+		"subject\n\nnot a trailer",
+		"subject\n\nKey:",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, message string) {
+		trailers := ParseTrailers(message)
+		ParseCommitMetadata(message)
+		if len(trailers) == 0 {
+			return
+		}
+		again := ParseTrailers("subject\n\n" + FormatTrailers(trailers))
+		if len(again) != len(trailers) {
+			t.Fatalf("trailers %q of %q do not parse back: %q", trailers, message, again)
+		}
+		for i := range again {
+			if again[i] != trailers[i] {
+				t.Errorf("trailer %q of %q parses back as %q", trailers[i], message, again[i])
+			}
+		}
+	})
+}
+
+func FuzzMessageTemplate(f *testing.F) {
+	f.Add(DefaultCommitSubjectTemplate, DefaultCommitBodyTemplate, "handle it")
+	f.Add("{{.Type}}: {{.Desc}} [{{.Ticket}}]", "", "ünïcode")
+	f.Add("{{.Desc}}", "{{.Fields.team}}", "multi\nline")
+	f.Add("{{.Nope}}", "", "")
+	f.Fuzz(func(t *testing.T, subject, body, desc string) {
+		tmpl, err := ParseMessageTemplate(subject, body, "team")
+		if err != nil {
+			return
+		}
+		m := sampleMessage
+		m.Desc = desc
+		m.Fields = map[string]string{"team": desc}
+		got, _, err := tmpl.Render(m)
+		if err == nil && (got == "" || strings.Contains(got, "\n")) {
+			t.Errorf("Render with subject template %q and description %q = %q, want a single line", subject, desc, got)
+		}
+	})
+}
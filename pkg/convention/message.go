@@ -0,0 +1,319 @@
+package convention
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Default commit message templates: the "type(product): desc" header and the
+// "Fixes TICKET" line below it.
+const (
+	DefaultCommitSubjectTemplate = "{{.Type}}({{.Product}}): {{.Desc}}"
+	DefaultCommitBodyTemplate    = "{{.Verb}} {{.Ticket}}"
+)
+
+// Message holds the values available to commit message templates.
+type Message struct {
+	Type    string
+	Product string
+	Desc    string
+	Ticket  string
+	// Verb is the ticket verb of the commit type, see TicketVerb.
+	Verb   string
+	Branch string
+	Abbrev string
+	// Date is the commit date as YYYY-MM-DD.
+	Date string
+	// Env is the affected environment, e.g. prod, or empty.
+	Env string
+	// Fields holds the custom fields, used as {{.Fields.name}}.
+	Fields map[string]string
+}
+
+// sampleMessage is used to check templates before they are used.
+var sampleMessage = Message{
+	Type:    "fix",
+	Product: "lego",
+	Desc:    "handle empty playlists",
+	Ticket:  "CPRE-123",
+	Verb:    "Fixes",
+	Branch:  "ab-fix-empty-playlists/CPRE-123",
+	Abbrev:  "ab",
+	Date:    "2006-01-02",
+	Env:     "prod",
+}
+
+// subjectField describes how one template field is matched in commit subjects.
+type subjectField struct {
+	name   string
+	expr   string
+	custom bool
+}
+
+// subjectFields are the template fields in the order of Message. The
+// expressions only use syntax shared with POSIX extended regular expressions,
+// apart from \d, so hooks can use them too.
+var subjectFields = []subjectField{
+	{name: "Type", expr: `[A-Za-z0-9_]+`},
+	{name: "Product", expr: `[A-Za-z0-9_-]+`},
+	{name: "Desc", expr: `.+`},
+	{name: "Ticket", expr: `[A-Za-z]+-\d+`},
+	{name: "Verb", expr: `[A-Za-z]+`},
+	{name: "Branch", expr: `[^ ]+`},
+	{name: "Abbrev", expr: `[A-Za-z]{2}`},
+	{name: "Date", expr: `\d{4}-\d{2}-\d{2}`},
+	{name: "Env", expr: `[A-Za-z0-9_-]*`},
+}
+
+// MessageTemplate renders the subject and body of commit messages, and parses
+// subjects back into their type, product and description.
+type MessageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+	// defs are subjectFields followed by the custom fields.
+	defs []subjectField
+	// literals holds the text around the subject's fields; fields holds the
+	// index in defs of each field, in subject order.
+	literals []string
+	fields   []int
+	parse    *regexp.Regexp
+	// patterns caches the compiled HeaderPattern of each set of types and
+	// products, since hooks validate with the same ones every time.
+	patterns sync.Map
+}
+
+// ParseMessageTemplate compiles commit message templates; an empty source
+// uses the default. The subject must render to a single non-empty line, and
+// the body may render to nothing to leave only the trailers. The templates may
+// use the named custom fields as {{.Fields.name}}. Subjects it renders must
+// parse back into their type, product and description, so hooks can check them.
+func ParseMessageTemplate(subject, body string, customFields ...string) (*MessageTemplate, error) {
+	if subject == "" {
+		subject = DefaultCommitSubjectTemplate
+	}
+	if body == "" {
+		body = DefaultCommitBodyTemplate
+	}
+	// NUL marks the fields in the subject, which becomes a regular expression.
+	if strings.ContainsRune(subject, 0) || !utf8.ValidString(subject) {
+		return nil, fmt.Errorf("invalid commit subject template: it must be UTF-8 text without NUL characters")
+	}
+	t := &MessageTemplate{defs: slices.Clone(subjectFields)}
+	for _, name := range customFields {
+		t.defs = append(t.defs, subjectField{name: name, expr: `.*`, custom: true})
+	}
+	var err error
+	if t.subject, err = template.New("subject").Option("missingkey=error").Parse(subject); err != nil {
+		return nil, fmt.Errorf("invalid commit subject template: %w", err)
+	}
+	if t.body, err = template.New("body").Option("missingkey=error").Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid commit body template: %w", err)
+	}
+	sample := sampleMessage
+	sample.Fields = map[string]string{}
+	for _, name := range customFields {
+		sample.Fields[name] = "sample"
+	}
+	rendered, _, err := t.Render(sample)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.layout(); err != nil {
+		return nil, err
+	}
+	if h, ok := t.ParseHeader(rendered); !ok || h.Description != t.sampleValue("Desc", sample.Desc) || h.Type != t.sampleValue("Type", sample.Type) || h.Product != t.sampleValue("Product", sample.Product) {
+		return nil, fmt.Errorf("invalid commit subject template: the type, product and description cannot be told apart in '%s'", rendered)
+	}
+	return t, nil
+}
+
+// layout renders the subject with markers in place of the fields to find
+// where each one goes, and compiles the expression that parses subjects.
+func (t *MessageTemplate) layout() error {
+	markers := Message{Fields: map[string]string{}}
+	for i, f := range t.defs {
+		marker := "\x00" + string(rune('A'+i)) + "\x00"
+		if f.custom {
+			markers.Fields[f.name] = marker
+			continue
+		}
+		*messageField(&markers, f.name) = marker
+	}
+	var b bytes.Buffer
+	if err := t.subject.Execute(&b, markers); err != nil {
+		return fmt.Errorf("invalid commit subject template: %w", err)
+	}
+	for i, piece := range strings.Split(strings.TrimSpace(b.String()), "\x00") {
+		if i%2 == 0 {
+			t.literals = append(t.literals, piece)
+			continue
+		}
+		index := int([]rune(piece + "\x00")[0] - 'A')
+		if index < 0 || index >= len(t.defs) || len(piece) != 1 {
+			return fmt.Errorf("invalid commit subject template: it must not produce NUL characters")
+		}
+		t.fields = append(t.fields, index)
+	}
+	t.parse = t.pattern(func(f subjectField) string { return f.expr })
+	return nil
+}
+
+// messageField returns the field of m with the given name.
+func messageField(m *Message, name string) *string {
+	switch name {
+	case "Type":
+		return &m.Type
+	case "Product":
+		return &m.Product
+	case "Desc":
+		return &m.Desc
+	case "Ticket":
+		return &m.Ticket
+	case "Verb":
+		return &m.Verb
+	case "Branch":
+		return &m.Branch
+	case "Abbrev":
+		return &m.Abbrev
+	case "Date":
+		return &m.Date
+	default:
+		return &m.Env
+	}
+}
+
+// uses reports whether the subject uses the named field.
+func (t *MessageTemplate) uses(name string) bool {
+	return slices.ContainsFunc(t.fields, func(i int) bool { return t.defs[i].name == name && !t.defs[i].custom })
+}
+
+// sampleValue returns value if the subject uses the named field, since
+// ParseHeader leaves the parts the subject does not have empty.
+func (t *MessageTemplate) sampleValue(name, value string) string {
+	if t.uses(name) {
+		return value
+	}
+	return ""
+}
+
+// pattern builds a regular expression from the subject, capturing each field
+// matched with the expression returned by match, in subject order.
+func (t *MessageTemplate) pattern(match func(f subjectField) string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i, literal := range t.literals {
+		b.WriteString(regexp.QuoteMeta(literal))
+		if i < len(t.fields) {
+			b.WriteString("(" + match(t.defs[t.fields[i]]) + ")")
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// ParseHeader splits a commit header into the type, product and description
+// of the subject template. It reports false if the header does not have the
+// shape of the template; parts the template does not use are left empty.
+func (t *MessageTemplate) ParseHeader(header string) (Header, bool) {
+	m := t.parse.FindStringSubmatch(header)
+	if m == nil {
+		return Header{}, false
+	}
+	var h Header
+	// A field used more than once is taken from its first place.
+	for i := len(t.fields) - 1; i >= 0; i-- {
+		if f := t.defs[t.fields[i]]; !f.custom {
+			switch f.name {
+			case "Type":
+				h.Type = m[i+1]
+			case "Product":
+				h.Product = m[i+1]
+			case "Desc":
+				h.Description = m[i+1]
+			}
+		}
+	}
+	return h, true
+}
+
+// HeaderPattern returns a pattern matching only headers that follow the
+// subject template with the given commit types and products. The pattern
+// only uses syntax shared with POSIX extended regular expressions, apart
+// from \d, and captures every field in subject order; see DescriptionGroup.
+func (t *MessageTemplate) HeaderPattern(types, products []string) *regexp.Regexp {
+	typeExpr, productExpr := Alternation(types), Alternation(products)
+	key := typeExpr + "\x00" + productExpr
+	if p, ok := t.patterns.Load(key); ok {
+		return p.(*regexp.Regexp)
+	}
+	p := t.pattern(func(f subjectField) string {
+		switch {
+		case f.custom:
+			return f.expr
+		case f.name == "Type":
+			return typeExpr
+		case f.name == "Product":
+			return productExpr
+		}
+		return f.expr
+	})
+	t.patterns.Store(key, p)
+	return p
+}
+
+// DescriptionGroup returns the group of HeaderPattern that captures the
+// description, or 0 if the subject has none.
+func (t *MessageTemplate) DescriptionGroup() int {
+	for i, index := range t.fields {
+		if f := t.defs[index]; !f.custom && f.name == "Desc" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Example describes the subject with placeholders, e.g.
+// "type(product): description".
+func (t *MessageTemplate) Example() string {
+	data := Message{Type: "type", Product: "product", Desc: "description", Ticket: "TICKET-123", Verb: "Fixes", Branch: "branch", Abbrev: "ab", Date: "YYYY-MM-DD", Env: "env", Fields: map[string]string{}}
+	for _, f := range t.defs {
+		if f.custom {
+			data.Fields[f.name] = "<" + f.name + ">"
+		}
+	}
+	var b bytes.Buffer
+	_ = t.subject.Execute(&b, data)
+	return strings.TrimSpace(b.String())
+}
+
+// MustParseMessageTemplate is like ParseMessageTemplate but panics on error.
+func MustParseMessageTemplate(subject, body string) *MessageTemplate {
+	t, err := ParseMessageTemplate(subject, body)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render returns the subject and body of the commit message for m.
+func (t *MessageTemplate) Render(m Message) (subject, body string, err error) {
+	var b bytes.Buffer
+	if err := t.subject.Execute(&b, m); err != nil {
+		return "", "", fmt.Errorf("invalid commit subject template: %w", err)
+	}
+	subject = strings.TrimSpace(b.String())
+	if subject == "" || strings.Contains(subject, "\n") {
+		return "", "", fmt.Errorf("commit subject template must render to a single line, got %q", subject)
+	}
+	b.Reset()
+	if err := t.body.Execute(&b, m); err != nil {
+		return "", "", fmt.Errorf("invalid commit body template: %w", err)
+	}
+	return subject, strings.TrimSpace(b.String()), nil
+}
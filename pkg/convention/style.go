@@ -0,0 +1,196 @@
+package convention
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule IDs, reported with violations and used to disable style rules.
+const (
+	RuleBranchName        = "branch-name"
+	RuleHeaderFormat      = "header-format"
+	RuleHeaderType        = "header-type"
+	RuleHeaderProduct     = "header-product"
+	RuleDescriptionLength = "description-max-length"
+	RuleHeaderMaxLength   = "header-max-length"
+	RuleSubjectCase       = "subject-case"
+	RuleSubjectFullStop   = "subject-full-stop"
+	RuleSubjectMood       = "subject-imperative"
+)
+
+// StyleRules lists the configurable style rules with a short explanation.
+var StyleRules = map[string]string{
+	RuleHeaderMaxLength: "the header must not exceed the maximum length",
+	RuleSubjectCase:     "the description must start with a lowercase letter",
+	RuleSubjectFullStop: "the description must not end with a period",
+	RuleSubjectMood:     "the description must use the imperative mood (\"add\", not \"added\")",
+}
+
+// DefaultMaxHeaderLength is the default maximum length of a commit header such
+// as "fix(lego): short description".
+const DefaultMaxHeaderLength = 72
+
+// Style configures the commit description style rules.
+type Style struct {
+	// Disabled lists rule IDs that should not be enforced.
+	Disabled        []string `json:"disabled,omitempty"`
+	MaxHeaderLength int      `json:"max_header_length,omitempty"`
+}
+
+// MaxHeader returns the configured maximum header length or the default.
+func (s Style) MaxHeader() int {
+	if s.MaxHeaderLength > 0 {
+		return s.MaxHeaderLength
+	}
+	return DefaultMaxHeaderLength
+}
+
+// Enabled reports whether the given rule is enforced.
+func (s Style) Enabled(rule string) bool {
+	return !slices.Contains(s.Disabled, rule)
+}
+
+// Violation describes a broken rule and, when possible, a fix.
+type Violation struct {
+	Rule       string `json:"rule"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func (v Violation) String() string {
+	if v.Suggestion == "" {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s: %s (try: %q)", v.Rule, v.Message, v.Suggestion)
+}
+
+// imperativeExceptions are words that look like non-imperative verbs but are fine as-is.
+var imperativeExceptions = map[string]bool{
+	"address": true, "bless": true, "bring": true, "bypass": true, "deps": true,
+	"docs": true, "embed": true, "feed": true, "focus": true, "need": true,
+	"pass": true, "ping": true, "process": true, "seed": true, "shed": true,
+	"speed": true, "spring": true, "status": true, "string": true, "this": true,
+	"thing": true,
+}
+
+// silentEEndings are stem endings whose imperative form ends with a silent "e",
+// e.g. "creat" -> "create" or "handl" -> "handle".
+var silentEEndings = []string{"am", "at", "bl", "ciz", "dl", "gl", "id", "iz", "od", "ov", "pl", "ur", "tl", "uc", "ir", "ng", "rg"}
+
+// needsSilentE reports whether the stem left after removing "ed" or "ing"
+// should get its trailing "e" back.
+func needsSilentE(stem string) bool {
+	for _, ending := range silentEEndings {
+		if strings.HasSuffix(stem, ending) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImperativeOf returns the imperative form of a non-imperative verb such as
+// "added", "fixes" or "fixing", or "" if the word already looks imperative.
+func ImperativeOf(word string) string {
+	w := strings.ToLower(word)
+	if imperativeExceptions[w] || len(w) < 4 {
+		return ""
+	}
+	switch {
+	case strings.HasSuffix(w, "ied"):
+		return strings.TrimSuffix(w, "ied") + "y"
+	case strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "ies") + "y"
+	case strings.HasSuffix(w, "ed"):
+		stem := strings.TrimSuffix(w, "ed")
+		// "removed" -> "remove", "added" -> "add".
+		if strings.HasSuffix(stem, "dd") || strings.HasSuffix(stem, "ll") || strings.HasSuffix(stem, "ss") {
+			return stem
+		}
+		if (strings.HasSuffix(stem, "s") && !strings.HasSuffix(stem, "us")) || needsSilentE(stem) {
+			return stem + "e"
+		}
+		return stem
+	case strings.HasSuffix(w, "ing"):
+		stem := strings.TrimSuffix(w, "ing")
+		// "adding" -> "add" keeps double letters only when the root has them.
+		if n := len(stem); n > 2 && stem[n-1] == stem[n-2] && !strings.HasSuffix(stem, "ll") && !strings.HasSuffix(stem, "ss") && !strings.HasSuffix(stem, "dd") {
+			return stem[:n-1]
+		}
+		if needsSilentE(stem) {
+			return stem + "e"
+		}
+		return stem
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "xes"):
+		return strings.TrimSuffix(w, "es")
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return ""
+}
+
+// CheckDescription checks a commit description against the style rules.
+// The header is the full first line, used for the length rule.
+func (s Style) CheckDescription(header, desc string) []Violation {
+	var violations []Violation
+	if desc == "" {
+		return violations
+	}
+
+	if s.Enabled(RuleHeaderMaxLength) && utf8.RuneCountInString(header) > s.MaxHeader() {
+		violations = append(violations, Violation{
+			Rule:    RuleHeaderMaxLength,
+			Message: fmt.Sprintf("header is %d characters long (max %d)", utf8.RuneCountInString(header), s.MaxHeader()),
+		})
+	}
+
+	if s.Enabled(RuleSubjectFullStop) && strings.HasSuffix(desc, ".") {
+		violations = append(violations, Violation{
+			Rule:       RuleSubjectFullStop,
+			Message:    "description should not end with a period",
+			Suggestion: strings.TrimRight(desc, "."),
+		})
+	}
+
+	first, rest, _ := strings.Cut(desc, " ")
+	if r, size := utf8.DecodeRuneInString(desc); s.Enabled(RuleSubjectCase) && unicode.IsUpper(r) {
+		// Leave acronyms such as "API" alone.
+		if !(len(first) > 1 && strings.ToUpper(first) == first) {
+			violations = append(violations, Violation{
+				Rule:       RuleSubjectCase,
+				Message:    "description should start with a lowercase letter",
+				Suggestion: string(unicode.ToLower(r)) + desc[size:],
+			})
+		}
+	}
+
+	if s.Enabled(RuleSubjectMood) {
+		if verb := ImperativeOf(first); verb != "" {
+			suggestion := verb
+			if rest != "" {
+				suggestion += " " + rest
+			}
+			violations = append(violations, Violation{
+				Rule:       RuleSubjectMood,
+				Message:    fmt.Sprintf("use the imperative mood (%q instead of %q)", verb, first),
+				Suggestion: suggestion,
+			})
+		}
+	}
+	return violations
+}
+
+// LintHeader checks a commit header for the conventional format and the style
+// rules, but not for the allowed types and products.
+func (s Style) LintHeader(header string) []Violation {
+	match := HeaderPattern.FindStringSubmatch(header)
+	if match == nil {
+		return []Violation{{
+			Rule:    RuleHeaderFormat,
+			Message: "header must look like 'type(product): description'",
+		}}
+	}
+	return s.CheckDescription(header, match[3])
+}
@@ -0,0 +1,329 @@
+package convention
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode/utf8"
+)
+
+// DefaultBranchTemplate is the branch name format used when none is configured.
+const DefaultBranchTemplate = "{{.Abbrev}}-{{.Type}}-{{.Desc}}/{{.Ticket}}"
+
+// branchTemplateData are the fields available to a branch template.
+type branchTemplateData struct {
+	Abbrev string
+	Type   string
+	Desc   string
+	Ticket string
+	Env    string
+	// Fields holds the custom fields, used as {{.Fields.name}}.
+	Fields map[string]string
+}
+
+// branchField describes how one template field is matched in branch names.
+type branchField struct {
+	name string
+	// loose matches the field when parsing, strict only when it follows the rules.
+	loose, strict string
+	required      bool
+	custom        bool
+}
+
+// customFieldValue is what the environment and custom fields may contain in
+// branch names, so the names can be parsed back into their parts.
+var customFieldValue = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// branchFields are the template fields in the order of branchTemplateData.
+var branchFields = []branchField{
+	{name: "Abbrev", loose: `[A-Za-z]{2}`, strict: `[a-z]{2}`},
+	{name: "Type", loose: `[a-z]+`},
+	{name: "Desc", loose: `.+`, strict: `[a-z0-9-]+`, required: true},
+	{name: "Ticket", loose: `[A-Za-z]+-\d+`, strict: `[A-Za-z]+-\d+`, required: true},
+	{name: "Env", loose: `[A-Za-z0-9_]*`, strict: `[A-Za-z0-9_]*`},
+}
+
+// BranchTemplate is a branch name format such as
+// "{{.Abbrev}}/{{.Type}}/{{.Ticket}}-{{.Desc}}". Names can be rendered from
+// their parts and parsed back into them.
+type BranchTemplate struct {
+	source string
+	tmpl   *template.Template
+	// defs are branchFields followed by the custom fields.
+	defs []branchField
+	// literals holds the text around the fields; fields holds the index in
+	// defs of each field, in template order.
+	literals []string
+	fields   []int
+	parse    *regexp.Regexp
+	// patterns caches the compiled Pattern of each set of branch types, since
+	// hooks validate with the same types every time.
+	patterns sync.Map
+}
+
+// ParseBranchTemplate compiles a branch template. It must use {{.Desc}} and
+// {{.Ticket}} and may use {{.Abbrev}}, {{.Type}}, {{.Env}} and the named custom
+// fields as {{.Fields.name}}, each at most once, and the names it produces must parse
+// back into their parts.
+func ParseBranchTemplate(source string, customFields ...string) (*BranchTemplate, error) {
+	// NUL marks the fields below, and the template becomes a regular expression.
+	if strings.ContainsRune(source, 0) || !utf8.ValidString(source) {
+		return nil, fmt.Errorf("invalid branch template: it must be UTF-8 text without NUL characters")
+	}
+	tmpl, err := template.New("branch").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template: %w", err)
+	}
+	t := &BranchTemplate{source: source, tmpl: tmpl, defs: slices.Clone(branchFields)}
+	for _, name := range customFields {
+		t.defs = append(t.defs, branchField{name: name, loose: `[A-Za-z0-9_]*`, strict: `[A-Za-z0-9_]*`, custom: true})
+	}
+
+	// Render with markers in place of the fields to find where each one goes.
+	markers := branchTemplateData{Fields: map[string]string{}}
+	for i, f := range t.defs {
+		marker := "\x00" + string(rune('A'+i)) + "\x00"
+		switch {
+		case f.custom:
+			markers.Fields[f.name] = marker
+		case f.name == "Abbrev":
+			markers.Abbrev = marker
+		case f.name == "Type":
+			markers.Type = marker
+		case f.name == "Desc":
+			markers.Desc = marker
+		case f.name == "Ticket":
+			markers.Ticket = marker
+		case f.name == "Env":
+			markers.Env = marker
+		}
+	}
+	rendered, err := t.execute(markers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template: %w", err)
+	}
+	pieces := strings.Split(rendered, "\x00")
+	seen := map[int]bool{}
+	for i, piece := range pieces {
+		if i%2 == 0 {
+			t.literals = append(t.literals, piece)
+			continue
+		}
+		index := int([]rune(piece + "\x00")[0] - 'A')
+		if index < 0 || index >= len(t.defs) || len(piece) != 1 {
+			return nil, fmt.Errorf("invalid branch template: it must not produce NUL characters")
+		}
+		if seen[index] {
+			return nil, fmt.Errorf("invalid branch template: {{.%s}} is used more than once", t.defs[index].name)
+		}
+		seen[index] = true
+		t.fields = append(t.fields, index)
+	}
+	for i, f := range branchFields {
+		if f.required && !seen[i] {
+			return nil, fmt.Errorf("invalid branch template: {{.%s}} is required", f.name)
+		}
+	}
+	t.parse = t.pattern(func(f branchField) string { return f.loose }, true)
+
+	// The names must be valid refs that parse back into the same parts.
+	sample := Branch{Abbreviation: "ab", Type: "fix", Description: "short-desc", Ticket: "ABC-123", Env: "prod", Fields: map[string]string{}}
+	for _, name := range customFields {
+		sample.Fields[name] = "x1"
+	}
+	name, err := t.Render(sample)
+	if err != nil {
+		return nil, err
+	}
+	if parsed, ok := t.Parse(name); !ok || parsed.Ticket != sample.Ticket || parsed.Description != sample.Description {
+		return nil, fmt.Errorf("invalid branch template: the ticket and description cannot be told apart in '%s'", name)
+	}
+	return t, nil
+}
+
+// MustParseBranchTemplate is ParseBranchTemplate for templates known to be valid.
+func MustParseBranchTemplate(source string) *BranchTemplate {
+	t, err := ParseBranchTemplate(source)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// defaultBranchTemplate is the compiled DefaultBranchTemplate.
+var defaultBranchTemplate = MustParseBranchTemplate(DefaultBranchTemplate)
+
+func (t *BranchTemplate) String() string {
+	return t.source
+}
+
+// execute renders the template with the given field values.
+func (t *BranchTemplate) execute(data branchTemplateData) (string, error) {
+	var b bytes.Buffer
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// pattern builds a regular expression from the template, matching each field
+// with the expression returned by match; capture reports whether fields are
+// captured in groups, in template order.
+func (t *BranchTemplate) pattern(match func(f branchField) string, capture bool) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i, literal := range t.literals {
+		b.WriteString(regexp.QuoteMeta(literal))
+		if i < len(t.fields) {
+			expr := match(t.defs[t.fields[i]])
+			if capture {
+				expr = "(" + expr + ")"
+			}
+			b.WriteString(expr)
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// Render assembles a branch name, lowercasing the abbreviation and
+// description, and checks that git accepts it. Custom fields missing from b
+// are empty.
+func (t *BranchTemplate) Render(b Branch) (string, error) {
+	data := branchTemplateData{
+		Abbrev: strings.ToLower(b.Abbreviation),
+		Type:   b.Type,
+		Desc:   strings.ToLower(b.Description),
+		Ticket: b.Ticket,
+		Env:    b.Env,
+		Fields: map[string]string{},
+	}
+	env := slices.IndexFunc(t.defs, func(f branchField) bool { return f.name == "Env" })
+	if slices.Contains(t.fields, env) && !customFieldValue.MatchString(b.Env) {
+		return "", fmt.Errorf("environment '%s' cannot be used in a branch name; use letters, digits and underscores", b.Env)
+	}
+	for _, f := range t.defs {
+		if f.custom {
+			value := b.Fields[f.name]
+			if !customFieldValue.MatchString(value) {
+				return "", fmt.Errorf("%s '%s' cannot be used in a branch name; use letters, digits and underscores", f.name, value)
+			}
+			data.Fields[f.name] = value
+		}
+	}
+	name, err := t.execute(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render branch name: %w", err)
+	}
+	if err := ValidateRefName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Parse splits a branch name into its parts. It reports false if the name
+// does not have the shape of the template; the parts are not checked against
+// any rules.
+func (t *BranchTemplate) Parse(name string) (Branch, bool) {
+	m := t.parse.FindStringSubmatch(name)
+	if m == nil {
+		return Branch{}, false
+	}
+	var b Branch
+	for i, index := range t.fields {
+		value := m[i+1]
+		if f := t.defs[index]; f.custom {
+			if b.Fields == nil {
+				b.Fields = map[string]string{}
+			}
+			b.Fields[f.name] = value
+			continue
+		}
+		switch t.defs[index].name {
+		case "Abbrev":
+			b.Abbreviation = value
+		case "Type":
+			b.Type = value
+		case "Desc":
+			b.Description = value
+		case "Ticket":
+			b.Ticket = value
+		case "Env":
+			b.Env = value
+		}
+	}
+	return b, true
+}
+
+// Pattern returns a pattern matching only branch names that follow the
+// template with the given branch types. The pattern only uses syntax shared
+// with POSIX extended regular expressions, apart from \d.
+func (t *BranchTemplate) Pattern(types []string) *regexp.Regexp {
+	typeExpr := Alternation(types)
+	if p, ok := t.patterns.Load(typeExpr); ok {
+		return p.(*regexp.Regexp)
+	}
+	p := t.pattern(func(f branchField) string {
+		if f.name == "Type" {
+			return typeExpr
+		}
+		return f.strict
+	}, true)
+	t.patterns.Store(typeExpr, p)
+	return p
+}
+
+// Example describes the format with placeholders, e.g.
+// "<abbreviation>-<type>-<short_desc>/<TICKET-123>".
+func (t *BranchTemplate) Example() string {
+	data := branchTemplateData{Abbrev: "<abbreviation>", Type: "<type>", Desc: "<short_desc>", Ticket: "<TICKET-123>", Env: "<env>", Fields: map[string]string{}}
+	for _, f := range t.defs {
+		if f.custom {
+			data.Fields[f.name] = "<" + f.name + ">"
+		}
+	}
+	example, _ := t.execute(data)
+	return example
+}
+
+// Alternation joins options into a regular expression alternation.
+// Invalid UTF-8, which regular expressions reject, is matched as U+FFFD,
+// the rune they read invalid bytes as.
+func Alternation(options []string) string {
+	quoted := make([]string, len(options))
+	for i, o := range options {
+		quoted[i] = regexp.QuoteMeta(strings.ToValidUTF8(o, "\uFFFD"))
+	}
+	return strings.Join(quoted, "|")
+}
+
+// ValidateRefName checks a branch name against the rules of git check-ref-format.
+func ValidateRefName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("branch name cannot be empty")
+	case strings.ContainsAny(name, " ~^:?*[\\\x7f"),
+		strings.Contains(name, ".."),
+		strings.Contains(name, "//"),
+		strings.Contains(name, "@{"),
+		strings.HasPrefix(name, "/"), strings.HasPrefix(name, "-"),
+		strings.HasSuffix(name, "/"), strings.HasSuffix(name, "."),
+		strings.HasSuffix(name, ".lock"):
+		return fmt.Errorf("'%s' is not a valid branch name", name)
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return fmt.Errorf("'%s' is not a valid branch name", name)
+		}
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return fmt.Errorf("'%s' is not a valid branch name", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package convention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Keys of the trailers create-commit adds to every commit, so tooling can read
+// commit metadata without parsing the header.
+const (
+	TrailerTicket        = "Ticket"
+	TrailerProduct       = "Product"
+	TrailerHelperVersion = "Helper-Version"
+	TrailerEnvironment   = "Environment"
+)
+
+// Trailer is one "Key: value" line at the end of a commit message.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// trailerPattern matches a trailer line; keys are letters, digits and hyphens as in git.
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*?)\s*$`)
+
+// ParseTrailers returns the trailers of a commit message: the lines of its last
+// paragraph, when every line of it is a trailer. Like git, a message with a
+// single paragraph has no trailers.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var trailers []Trailer
+	for _, line := range strings.Split(strings.TrimSpace(paragraphs[len(paragraphs)-1]), "\n") {
+		m := trailerPattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// FormatTrailers renders trailers as the last paragraph of a commit message.
+func FormatTrailers(trailers []Trailer) string {
+	lines := make([]string, len(trailers))
+	for i, t := range trailers {
+		lines[i] = t.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CommitMetadata is the machine-readable metadata of a convention commit.
+type CommitMetadata struct {
+	Ticket        string `json:"ticket,omitempty"`
+	Product       string `json:"product,omitempty"`
+	HelperVersion string `json:"helper_version,omitempty"`
+	// Environment is the environment the change affects, e.g. prod.
+	Environment string `json:"environment,omitempty"`
+}
+
+// Trailers returns the trailers recording the metadata, skipping empty fields.
+func (m CommitMetadata) Trailers() []Trailer {
+	var trailers []Trailer
+	for _, t := range []Trailer{
+		{Key: TrailerTicket, Value: m.Ticket},
+		{Key: TrailerProduct, Value: m.Product},
+		{Key: TrailerHelperVersion, Value: m.HelperVersion},
+		{Key: TrailerEnvironment, Value: m.Environment},
+	} {
+		if t.Value != "" {
+			trailers = append(trailers, t)
+		}
+	}
+	return trailers
+}
+
+// ParseCommitMetadata reads the metadata trailers of a commit message. Keys
+// are matched case-insensitively; the last occurrence of a key wins.
+func ParseCommitMetadata(message string) CommitMetadata {
+	var m CommitMetadata
+	for _, t := range ParseTrailers(message) {
+		switch {
+		case strings.EqualFold(t.Key, TrailerTicket):
+			m.Ticket = t.Value
+		case strings.EqualFold(t.Key, TrailerProduct):
+			m.Product = t.Value
+		case strings.EqualFold(t.Key, TrailerHelperVersion):
+			m.HelperVersion = t.Value
+		case strings.EqualFold(t.Key, TrailerEnvironment):
+			m.Environment = t.Value
+		}
+	}
+	return m
+}